@@ -1,6 +1,8 @@
 package eino
 
 import (
+	"context"
+
 	"github.com/alois132/skill/schema"
 	"github.com/cloudwego/eino/components/tool"
 )
@@ -44,3 +46,27 @@ func ToInvokableTools(skills ...*schema.Skill) []tool.InvokableTool {
 
 	return tools
 }
+
+// ToToolsFiltered 仅为 allow 允许的 Skill 构建 Eino Tools
+// 共享的 use_script 和 read_reference 工具同样只包含被允许的 Skill，被过滤的 Skill 无法通过它们调用；
+// nil Skill 和没有名称（含 Metadata 为 nil）的 Skill 无法被授权，总是被过滤。
+// 输出顺序与输入顺序一致，allow 为 nil 时等同于 ToTools；ctx 已取消时返回 nil
+func ToToolsFiltered(ctx context.Context, allow func(skillName string) bool, skills ...*schema.Skill) []tool.BaseTool {
+	if ctx.Err() != nil {
+		return nil
+	}
+	if allow == nil {
+		return ToTools(skills...)
+	}
+
+	permitted := make([]*schema.Skill, 0, len(skills))
+	for _, skill := range skills {
+		if skill == nil || skill.GetName() == "" {
+			continue
+		}
+		if allow(skill.GetName()) {
+			permitted = append(permitted, skill)
+		}
+	}
+	return ToTools(permitted...)
+}
@@ -0,0 +1,114 @@
+package eino
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+	"github.com/cloudwego/eino/components/tool"
+	einosch "github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+)
+
+// scriptToolSeparator 单脚本 Tool 名称中 Skill 名称与脚本名称之间的分隔符
+const scriptToolSeparator = "__"
+
+// ScriptTool 将某个 Skill 的单个脚本封装为 Eino Tool，名称为 <skill>__<script>
+// 参数 schema 取自脚本实现的 resources.SchemaProvider，没有 schema 时参数为任意对象
+type ScriptTool struct {
+	skill  *schema.Skill
+	script string
+	name   string
+}
+
+// NewScriptTool 创建一个新的 ScriptTool
+func NewScriptTool(skill *schema.Skill, scriptName string) *ScriptTool {
+	return &ScriptTool{
+		skill:  skill,
+		script: scriptName,
+		name:   skill.GetName() + scriptToolSeparator + scriptName,
+	}
+}
+
+// Info 返回 Tool 的元信息
+func (t *ScriptTool) Info(ctx context.Context) (*einosch.ToolInfo, error) {
+	script, err := t.skill.GetScript(ctx, t.script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve script %s.%s: %w", t.skill.GetName(), t.script, err)
+	}
+	params, err := scriptParams(ctx, script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build params for script %s.%s: %w", t.skill.GetName(), t.script, err)
+	}
+	return &einosch.ToolInfo{
+		Name:        t.name,
+		Desc:        script.GetUsage(),
+		ParamsOneOf: params,
+	}, nil
+}
+
+// InvokableRun 执行 Tool，通过 Skill.UseScript 调用对应的脚本
+func (t *ScriptTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if argumentsInJSON == "" {
+		argumentsInJSON = "{}"
+	}
+	return t.skill.UseScript(ctx, t.script, argumentsInJSON)
+}
+
+// scriptParams 将脚本的输入 schema 转换为 Tool 参数，没有 schema 时返回任意对象
+func scriptParams(ctx context.Context, script resources.Script) (*einosch.ParamsOneOf, error) {
+	input := json.RawMessage(`{"type":"object"}`)
+	if provider, ok := script.(resources.SchemaProvider); ok {
+		s, err := provider.Schema(ctx)
+		switch {
+		case err == nil && len(s.Input) > 0:
+			input = s.Input
+		case err != nil && !errors.Is(err, resources.ErrNoSchema):
+			return nil, err
+		}
+	}
+
+	js := &jsonschema.Schema{}
+	if err := json.Unmarshal(input, js); err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	return einosch.NewParamsOneOfByJSONSchema(js), nil
+}
+
+// ToPerScriptTools 为每个 Skill Body 中引用的每个脚本生成一个 ScriptTool
+// 输出顺序为 Skill 的输入顺序、再按脚本在 Body 中的出现顺序；相同的 skill.script 只生成一次。
+// 名称为 <skill>__<script>，不同的组合拼接出相同名称时，后出现的追加 _2、_3 等后缀以保证唯一
+func ToPerScriptTools(skills ...*schema.Skill) []tool.InvokableTool {
+	var tools []tool.InvokableTool
+	seenPairs := make(map[[2]string]bool)
+	usedNames := make(map[string]bool)
+
+	for _, skill := range skills {
+		if skill == nil {
+			continue
+		}
+		for _, scriptName := range skill.GetScriptNames() {
+			pair := [2]string{skill.GetName(), scriptName}
+			if seenPairs[pair] {
+				continue
+			}
+			seenPairs[pair] = true
+
+			t := NewScriptTool(skill, scriptName)
+			base := t.name
+			for i := 2; usedNames[t.name]; i++ {
+				t.name = base + "_" + strconv.Itoa(i)
+			}
+			usedNames[t.name] = true
+			tools = append(tools, t)
+		}
+	}
+	return tools
+}
+
+// Ensure ScriptTool implements InvokableTool
+var _ tool.InvokableTool = (*ScriptTool)(nil)
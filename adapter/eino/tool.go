@@ -2,10 +2,16 @@ package eino
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	skillschema "github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
 	"github.com/cloudwego/eino/components/tool"
 	einosch "github.com/cloudwego/eino/schema"
 )
@@ -13,7 +19,8 @@ import (
 // SkillTool 将 Skill 封装为 Eino Tool
 // 用于渐进式披露：input 为空，output 为 skill.Body
 type SkillTool struct {
-	skill *skillschema.Skill
+	skill        *skillschema.Skill
+	maxBodyChars int // body 最大字符数，<= 0 表示不截断
 }
 
 // NewSkillTool 创建一个新的 SkillTool
@@ -21,19 +28,26 @@ func NewSkillTool(skill *skillschema.Skill) *SkillTool {
 	return &SkillTool{skill: skill}
 }
 
-// Info 返回 Tool 的元信息
+// WithMaxBodyChars 限制返回 body 的最大字符数，超出部分会被截断
+func (t *SkillTool) WithMaxBodyChars(n int) *SkillTool {
+	t.maxBodyChars = n
+	return t
+}
+
+// Info 返回 Tool 的元信息，Skill 的 Metadata 为 nil 时名称和描述为空
 func (t *SkillTool) Info(ctx context.Context) (*einosch.ToolInfo, error) {
 	// 空参数表示不需要输入
 	return &einosch.ToolInfo{
-		Name: t.skill.Metadata.Name,
-		Desc: t.skill.Metadata.Description,
+		Name: t.skill.GetName(),
+		Desc: t.skill.GetDescription(),
 	}, nil
 }
 
 // InvokableRun 执行 Tool
-// input 为空，直接返回 skill.Body
+// input 为空，直接返回 skill.Body（设置了 WithMaxBodyChars 时会被截断）
 func (t *SkillTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
-	return t.skill.Body, nil
+	body, _ := t.skill.InspectTruncated(t.maxBodyChars)
+	return body, nil
 }
 
 // UseScriptRequest use_script 工具的请求参数
@@ -45,18 +59,40 @@ type UseScriptRequest struct {
 
 // UseScriptTool 执行 Skill 中的特定脚本
 type UseScriptTool struct {
-	skills map[string]*skillschema.Skill // skill name -> skill
+	skills       map[string]*skillschema.Skill // skill name -> skill
+	base64Binary bool                          // 是否以 base64 返回二进制结果
+	notFoundHint bool                          // 找不到时是否返回可用名称提示而非错误
+}
+
+// BinaryResult 二进制脚本结果，Data 为 base64 编码的内容
+type BinaryResult struct {
+	ContentType string `json:"content_type"`
+	Data        string `json:"data"`
 }
 
 // NewUseScriptTool 创建一个新的 UseScriptTool
 func NewUseScriptTool(skills ...*skillschema.Skill) *UseScriptTool {
 	skillMap := make(map[string]*skillschema.Skill, len(skills))
 	for _, skill := range skills {
-		skillMap[skill.Metadata.Name] = skill
+		skillMap[skill.GetName()] = skill
 	}
 	return &UseScriptTool{skills: skillMap}
 }
 
+// WithBase64Binary 开启二进制结果支持
+// 开启后，非 JSON 的脚本结果以 BinaryResult（base64 编码）的形式返回
+func (t *UseScriptTool) WithBase64Binary() *UseScriptTool {
+	t.base64Binary = true
+	return t
+}
+
+// WithNotFoundHint 设置找不到 Skill 或脚本时的行为
+// 开启后返回列出可用名称的提示文本（非错误），便于模型自行纠正；默认返回错误
+func (t *UseScriptTool) WithNotFoundHint(enabled bool) *UseScriptTool {
+	t.notFoundHint = enabled
+	return t
+}
+
 // Info 返回 Tool 的元信息
 func (t *UseScriptTool) Info(ctx context.Context) (*einosch.ToolInfo, error) {
 	params := map[string]*einosch.ParameterInfo{
@@ -94,10 +130,38 @@ func (t *UseScriptTool) InvokableRun(ctx context.Context, argumentsInJSON string
 
 	skill, ok := t.skills[req.SkillName]
 	if !ok {
+		if t.notFoundHint {
+			return notFoundHint("skill", req.SkillName, "", skillNames(t.skills)), nil
+		}
 		return "", fmt.Errorf("skill not found: %s", req.SkillName)
 	}
 
-	return skill.UseScript(ctx, req.ScriptName, req.Args)
+	// 先解析脚本，只有确实找不到时才返回提示，执行失败等其他错误照常返回
+	if t.notFoundHint {
+		if _, err := skill.GetScript(ctx, req.ScriptName); errors.Is(err, resources.ErrScriptNotFound) {
+			return notFoundHint("script", req.ScriptName, req.SkillName, availableScripts(ctx, skill)), nil
+		}
+	}
+
+	if !t.base64Binary {
+		return skill.UseScript(ctx, req.ScriptName, req.Args)
+	}
+
+	data, contentType, err := skill.UseScriptBytes(ctx, req.ScriptName, req.Args)
+	if err != nil {
+		return "", err
+	}
+	if contentType == "application/json" {
+		return string(data), nil
+	}
+	result, err := json.Marshal(BinaryResult{
+		ContentType: contentType,
+		Data:        base64.StdEncoding.EncodeToString(data),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal binary result: %w", err)
+	}
+	return string(result), nil
 }
 
 // ReadReferenceRequest read_reference 工具的请求参数
@@ -108,18 +172,26 @@ type ReadReferenceRequest struct {
 
 // ReadReferenceTool 读取 Skill 中的参考文献
 type ReadReferenceTool struct {
-	skills map[string]*skillschema.Skill
+	skills       map[string]*skillschema.Skill
+	notFoundHint bool // 找不到时是否返回可用名称提示而非错误
 }
 
 // NewReadReferenceTool 创建一个新的 ReadReferenceTool
 func NewReadReferenceTool(skills ...*skillschema.Skill) *ReadReferenceTool {
 	skillMap := make(map[string]*skillschema.Skill, len(skills))
 	for _, skill := range skills {
-		skillMap[skill.Metadata.Name] = skill
+		skillMap[skill.GetName()] = skill
 	}
 	return &ReadReferenceTool{skills: skillMap}
 }
 
+// WithNotFoundHint 设置找不到 Skill 或参考文档时的行为
+// 开启后返回列出可用名称的提示文本（非错误），便于模型自行纠正；默认返回错误
+func (t *ReadReferenceTool) WithNotFoundHint(enabled bool) *ReadReferenceTool {
+	t.notFoundHint = enabled
+	return t
+}
+
 // Info 返回 Tool 的元信息
 func (t *ReadReferenceTool) Info(ctx context.Context) (*einosch.ToolInfo, error) {
 	params := map[string]*einosch.ParameterInfo{
@@ -152,8 +224,56 @@ func (t *ReadReferenceTool) InvokableRun(ctx context.Context, argumentsInJSON st
 
 	skill, ok := t.skills[req.SkillName]
 	if !ok {
+		if t.notFoundHint {
+			return notFoundHint("skill", req.SkillName, "", skillNames(t.skills)), nil
+		}
 		return "", fmt.Errorf("skill not found: %s", req.SkillName)
 	}
 
-	return skill.ReadReference(req.ReferenceName)
+	body, err := skill.ReadReferenceContext(ctx, req.ReferenceName)
+	if t.notFoundHint && errors.Is(err, resources.ErrReferenceNotFound) {
+		return notFoundHint("reference", req.ReferenceName, req.SkillName, availableReferences(ctx, skill)), nil
+	}
+	return body, err
+}
+
+// notFoundHint 生成找不到资源时返回给模型的提示文本
+func notFoundHint(kind, name, skillName string, available []string) string {
+	var sb strings.Builder
+	if skillName == "" {
+		fmt.Fprintf(&sb, "%s %q not found.", kind, name)
+	} else {
+		fmt.Fprintf(&sb, "%s %q not found in skill %q.", kind, name, skillName)
+	}
+	if len(available) == 0 {
+		fmt.Fprintf(&sb, " No %ss are available.", kind)
+	} else {
+		fmt.Fprintf(&sb, " Available %ss: %s", kind, strings.Join(available, ", "))
+	}
+	return sb.String()
+}
+
+// skillNames 返回排序后的 Skill 名称
+func skillNames(skills map[string]*skillschema.Skill) []string {
+	names := make([]string, 0, len(skills))
+	for name := range skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// availableScripts 返回 Body 中引用的脚本以及内联和 Provider 提供的脚本名称（去重，Body 顺序优先）
+// Provider 列举失败时忽略其结果，提示中仍包含其余名称
+func availableScripts(ctx context.Context, skill *skillschema.Skill) []string {
+	names := skill.GetScriptNames()
+	available, _ := skill.AvailableScripts(ctx)
+	return util.UniqueStrings(append(names, available...))
+}
+
+// availableReferences 返回 Body 中引用的参考文档以及内联和 Provider 提供的参考文档名称（去重，Body 顺序优先）
+func availableReferences(ctx context.Context, skill *skillschema.Skill) []string {
+	names := skill.GetReferenceNames()
+	available, _ := skill.AvailableReferences(ctx)
+	return util.UniqueStrings(append(names, available...))
 }
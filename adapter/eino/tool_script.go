@@ -0,0 +1,105 @@
+package eino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/cloudwego/eino/components/tool"
+	einosch "github.com/cloudwego/eino/schema"
+)
+
+// ScriptFromTool 将 Eino InvokableTool 封装为脚本
+// Run 直接调用 InvokableRun 并传递 ctx；名称和使用说明取自 Tool.Info 的 Name 和 Desc，
+// Info 在首次成功获取后缓存，失败时下次访问重试（GetName/GetUsage 没有 ctx，使用 context.Background()）
+func ScriptFromTool(t tool.InvokableTool) resources.Script {
+	return &toolScript{tool: t}
+}
+
+// ProviderFromTools 将多个 Eino InvokableTool 作为脚本暴露的资源提供者
+func ProviderFromTools(tools ...tool.InvokableTool) resources.ResourceProvider {
+	provider := resources.NewInlineProvider()
+	for _, t := range tools {
+		provider.AddScript(ScriptFromTool(t))
+	}
+	return provider
+}
+
+// toolScript ScriptFromTool 返回的脚本
+type toolScript struct {
+	tool tool.InvokableTool
+
+	// infoMu 保护 info，只缓存成功获取的元信息
+	infoMu sync.Mutex
+	info   *einosch.ToolInfo
+}
+
+// toolInfo 返回缓存的 Tool 元信息，尚未成功获取时调用 Info
+func (s *toolScript) toolInfo() (*einosch.ToolInfo, error) {
+	s.infoMu.Lock()
+	defer s.infoMu.Unlock()
+
+	if s.info != nil {
+		return s.info, nil
+	}
+	info, err := s.tool.Info(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, fmt.Errorf("tool returned nil info")
+	}
+	s.info = info
+	return info, nil
+}
+
+// Run 调用 Tool 的 InvokableRun
+func (s *toolScript) Run(ctx context.Context, args string) (string, error) {
+	return s.tool.InvokableRun(ctx, args)
+}
+
+// GetName 返回 Tool 的名称，获取 Info 失败时返回空字符串
+func (s *toolScript) GetName() string {
+	info, err := s.toolInfo()
+	if err != nil {
+		return ""
+	}
+	return info.Name
+}
+
+// GetUsage 返回 Tool 的描述，获取 Info 失败时返回错误信息
+func (s *toolScript) GetUsage() string {
+	info, err := s.toolInfo()
+	if err != nil {
+		return "failed to get tool info: " + err.Error()
+	}
+	return info.Desc
+}
+
+// Schema 返回 Tool 参数的 JSON Schema，Tool 没有声明参数时 Input 为空
+func (s *toolScript) Schema(ctx context.Context) (*resources.ScriptSchema, error) {
+	info, err := s.toolInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tool info: %w", err)
+	}
+	if info.ParamsOneOf == nil {
+		return &resources.ScriptSchema{}, nil
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert tool params to json schema: %w", err)
+	}
+	input, err := json.Marshal(js)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool params schema: %w", err)
+	}
+	return &resources.ScriptSchema{Input: input}, nil
+}
+
+// Ensure toolScript implements Script and SchemaProvider
+var (
+	_ resources.Script         = (*toolScript)(nil)
+	_ resources.SchemaProvider = (*toolScript)(nil)
+)
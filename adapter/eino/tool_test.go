@@ -2,11 +2,19 @@ package eino
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/alois132/skill/core"
+	timeskill "github.com/alois132/skill/example/time"
 	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	einosch "github.com/cloudwego/eino/schema"
 )
 
 // 创建测试用的 time_skill
@@ -313,3 +321,508 @@ func containsAt(s, substr string, start int) bool {
 	}
 	return false
 }
+
+func TestUseScriptTool_Base64Binary(t *testing.T) {
+	ctx := context.Background()
+	png := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+
+	skill := core.CreateSkill("image_skill", "Render images",
+		core.WithScript(resources.NewBytesScript("render", "image/png", func(ctx context.Context, args string) ([]byte, error) {
+			return png, nil
+		})),
+	)
+	tool := NewUseScriptTool(skill).WithBase64Binary()
+
+	argsJSON, _ := json.Marshal(UseScriptRequest{SkillName: "image_skill", ScriptName: "render", Args: `{}`})
+	result, err := tool.InvokableRun(ctx, string(argsJSON))
+	if err != nil {
+		t.Fatalf("InvokableRun() error = %v", err)
+	}
+
+	var binary BinaryResult
+	if err := json.Unmarshal([]byte(result), &binary); err != nil {
+		t.Fatalf("Failed to unmarshal binary result: %v", err)
+	}
+	if binary.ContentType != "image/png" {
+		t.Errorf("Expected content type 'image/png', got '%s'", binary.ContentType)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(binary.Data)
+	if err != nil || string(decoded) != string(png) {
+		t.Errorf("Binary data corrupted: %v (%v)", decoded, err)
+	}
+}
+
+func TestTypedScriptTool(t *testing.T) {
+	ctx := context.Background()
+
+	script := resources.NewEasyScript("get_current_time", func(ctx context.Context, input timeskill.TimeInput) (timeskill.TimeOutput, error) {
+		return timeskill.TimeOutput{Time: input.Format + "@" + input.Timezone}, nil
+	}).WithUsage("Get the current time")
+	typedTool := NewTypedScriptTool(script)
+
+	info, err := typedTool.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Name != "get_current_time" {
+		t.Errorf("Expected name 'get_current_time', got '%s'", info.Name)
+	}
+	if info.Desc != "Get the current time" {
+		t.Errorf("Expected desc 'Get the current time', got '%s'", info.Desc)
+	}
+
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema failed: %v", err)
+	}
+	for _, name := range []string{"format", "timezone", "layout"} {
+		if _, ok := js.Properties.Get(name); !ok {
+			t.Errorf("Expected param '%s'", name)
+		}
+	}
+
+	result, err := typedTool.InvokableRun(ctx, `{"format":"iso","timezone":"UTC"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun failed: %v", err)
+	}
+	var output timeskill.TimeOutput
+	if err := json.Unmarshal([]byte(result), &output); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if output.Time != "iso@UTC" {
+		t.Errorf("Expected time 'iso@UTC', got '%s'", output.Time)
+	}
+}
+
+func TestTypedScriptTool_WithPanicRecovery(t *testing.T) {
+	script := resources.NewEasyScript("explode", func(ctx context.Context, input map[string]interface{}) (string, error) {
+		return input["missing"].(string), nil
+	})
+	result, err := NewTypedScriptTool(script).WithPanicRecovery(true).InvokableRun(context.Background(), `{}`)
+	var panicErr *schema.ScriptPanicError
+	if !errors.As(err, &panicErr) || panicErr.Script != "explode" || result != "" {
+		t.Errorf("Expected ScriptPanicError, got %q (%v)", result, err)
+	}
+}
+
+func TestTypedScriptTool_Required(t *testing.T) {
+	type Input struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit,omitempty"`
+	}
+	script := resources.NewEasyScript("search", func(ctx context.Context, input Input) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+
+	info, err := NewTypedScriptTool(script).Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema failed: %v", err)
+	}
+	if len(js.Required) != 1 || js.Required[0] != "query" {
+		t.Errorf("Expected required [query], got %v", js.Required)
+	}
+}
+
+func TestTypedScriptTool_ArrayInput(t *testing.T) {
+	ctx := context.Background()
+	skill := core.CreateSkill("calculator", "Calculator",
+		core.WithArrayInput("sum", func(ctx context.Context, input []float64) (map[string]float64, error) {
+			total := 0.0
+			for _, v := range input {
+				total += v
+			}
+			return map[string]float64{"sum": total}, nil
+		}),
+	)
+
+	// 脚本直接接收顶层数组参数
+	result, err := skill.UseScript(ctx, "sum", `[10, 5, 2.5]`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if result != `{"sum":17.5}` {
+		t.Errorf(`Expected {"sum":17.5}, got %s`, result)
+	}
+
+	script := skill.Scripts[0].(*resources.EasyScript[[]float64, map[string]float64])
+	typedTool := NewTypedScriptTool(script)
+	info, err := typedTool.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema failed: %v", err)
+	}
+	items, ok := js.Properties.Get("items")
+	if !ok || items.Type != "array" || items.Items == nil || items.Items.Type != "number" {
+		t.Errorf("Expected array of number param 'items', got %+v", items)
+	}
+
+	result, err = typedTool.InvokableRun(ctx, `{"items":[1,2,3]}`)
+	if err != nil {
+		t.Fatalf("InvokableRun failed: %v", err)
+	}
+	if result != `{"sum":6}` {
+		t.Errorf(`Expected {"sum":6}, got %s`, result)
+	}
+
+	// []int 输入
+	ints := NewTypedScriptTool(resources.NewEasyScript("echo", func(ctx context.Context, input []int) ([]int, error) {
+		return input, nil
+	}))
+	info, _ = ints.Info(ctx)
+	js, _ = info.ParamsOneOf.ToJSONSchema()
+	if items, _ := js.Properties.Get("items"); items == nil || items.Items == nil || items.Items.Type != "integer" {
+		t.Errorf("Expected array of integer param 'items', got %+v", items)
+	}
+	if result, err := ints.InvokableRun(ctx, `{"items":[10,5]}`); err != nil || result != `[10,5]` {
+		t.Errorf("Expected [10,5], got %s (%v)", result, err)
+	}
+}
+
+func TestSkillTool_WithMaxBodyChars(t *testing.T) {
+	skill := createTestTimeSkill()
+
+	result, err := NewSkillTool(skill).WithMaxBodyChars(10).InvokableRun(context.Background(), "")
+	if err != nil {
+		t.Fatalf("InvokableRun failed: %v", err)
+	}
+	expected := string([]rune(skill.Body)[:10]) + schema.TruncatedMarker
+	if result != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result)
+	}
+}
+
+func TestToToolsFiltered(t *testing.T) {
+	ctx := context.Background()
+	timeSkill := createTestTimeSkill()
+	secret := core.CreateSkill("secret_skill", "Secret skill",
+		core.WithScript(core.CreateScript("reveal", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"secret": "42"}, nil
+		})),
+		core.WithBody("Use <script>reveal</script>"),
+	)
+
+	tools := ToToolsFiltered(ctx, func(name string) bool { return name != "secret_skill" }, timeSkill, secret)
+	if len(tools) != 3 {
+		t.Fatalf("Expected 3 tools, got %d", len(tools))
+	}
+
+	names := make([]string, 0, len(tools))
+	for _, baseTool := range tools {
+		info, err := baseTool.Info(ctx)
+		if err != nil {
+			t.Fatalf("Info failed: %v", err)
+		}
+		names = append(names, info.Name)
+	}
+	expected := []string{"time_skill", "use_script", "read_reference"}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("Expected tools %v, got %v", expected, names)
+			break
+		}
+	}
+
+	// 共享的 use_script 不认识被过滤的 Skill
+	useScript := tools[1].(*UseScriptTool)
+	if _, err := useScript.InvokableRun(ctx, `{"skill_name":"secret_skill","script_name":"reveal","args":"{}"}`); err == nil {
+		t.Error("Expected filtered skill to be rejected by use_script")
+	}
+	if _, err := useScript.InvokableRun(ctx, `{"skill_name":"time_skill","script_name":"get_current_time","args":"{}"}`); err != nil {
+		t.Errorf("Expected permitted skill to run, got %v", err)
+	}
+}
+
+func TestToToolsFiltered_UnnamedSkills(t *testing.T) {
+	ctx := context.Background()
+	allowed := make([]string, 0)
+	allow := func(name string) bool {
+		allowed = append(allowed, name)
+		return true
+	}
+
+	// nil Skill 和无 Metadata 的 Skill 被过滤，不会传给 allow
+	tools := ToToolsFiltered(ctx, allow, nil, &schema.Skill{Body: "no metadata"}, createTestTimeSkill())
+	if len(tools) != 3 {
+		t.Fatalf("Expected 3 tools, got %d", len(tools))
+	}
+	if len(allowed) != 1 || allowed[0] != "time_skill" {
+		t.Errorf("Expected allow called only for time_skill, got %v", allowed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if tools := ToToolsFiltered(cancelled, allow, createTestTimeSkill()); tools != nil {
+		t.Errorf("Expected no tools for cancelled context, got %d", len(tools))
+	}
+}
+
+func TestReadReferenceTool_WithNotFoundHint(t *testing.T) {
+	ctx := context.Background()
+	skill := createTestTimeSkill()
+
+	// 默认返回错误
+	if _, err := NewReadReferenceTool(skill).InvokableRun(ctx, `{"skill_name":"time_skill","reference_name":"missing"}`); err == nil {
+		t.Error("Expected error by default")
+	}
+
+	refTool := NewReadReferenceTool(skill).WithNotFoundHint(true)
+	result, err := refTool.InvokableRun(ctx, `{"skill_name":"time_skill","reference_name":"missing"}`)
+	if err != nil {
+		t.Fatalf("Expected hint instead of error, got %v", err)
+	}
+	expected := `reference "missing" not found in skill "time_skill". Available references: time_format_guide`
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	// 存在的参考文档不受影响
+	result, err = refTool.InvokableRun(ctx, `{"skill_name":"time_skill","reference_name":"time_format_guide"}`)
+	if err != nil || !strings.HasPrefix(result, "# 时间格式指南") {
+		t.Errorf("Expected reference body, got %q, %v", result, err)
+	}
+
+	// 未知 Skill
+	result, err = refTool.InvokableRun(ctx, `{"skill_name":"unknown","reference_name":"x"}`)
+	if err != nil || result != `skill "unknown" not found. Available skills: time_skill` {
+		t.Errorf("Unexpected skill hint %q, %v", result, err)
+	}
+}
+
+func TestUseScriptTool_WithNotFoundHint(t *testing.T) {
+	ctx := context.Background()
+	useScript := NewUseScriptTool(createTestTimeSkill()).WithNotFoundHint(true)
+
+	result, err := useScript.InvokableRun(ctx, `{"skill_name":"time_skill","script_name":"missing","args":"{}"}`)
+	if err != nil {
+		t.Fatalf("Expected hint instead of error, got %v", err)
+	}
+	expected := `script "missing" not found in skill "time_skill". Available scripts: get_current_time, get_timezone`
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	// 脚本存在但执行失败时仍返回错误
+	if _, err := useScript.InvokableRun(ctx, `{"skill_name":"time_skill","script_name":"get_current_time","args":"not json"}`); err == nil {
+		t.Error("Expected execution error to be returned")
+	}
+}
+
+func TestNotFoundHint_ProviderResources(t *testing.T) {
+	ctx := context.Background()
+	provider := resources.NewInlineProvider()
+	provider.AddScript(resources.NewEasyScript("from_provider", func(ctx context.Context, input map[string]interface{}) (string, error) {
+		return "ok", nil
+	}))
+	skill := core.CreateSkill("p", "Provider skill",
+		core.WithBody("<script>inline</script>"),
+		core.WithResourceProvider(provider),
+		core.WithReferences([]*resources.Reference{
+			resources.NewLazyReference("broken", func(ctx context.Context) (string, error) {
+				return "", errors.New("disk read failed")
+			}),
+		}),
+	)
+
+	// Provider 提供但不在 Body 中的脚本可以执行，并出现在提示中
+	useScript := NewUseScriptTool(skill).WithNotFoundHint(true)
+	if result, err := useScript.InvokableRun(ctx, `{"skill_name":"p","script_name":"from_provider","args":"{}"}`); err != nil || result != `"ok"` {
+		t.Errorf("Expected provider script to run, got %q (%v)", result, err)
+	}
+	result, err := useScript.InvokableRun(ctx, `{"skill_name":"p","script_name":"missing","args":"{}"}`)
+	expected := `script "missing" not found in skill "p". Available scripts: inline, from_provider`
+	if err != nil || result != expected {
+		t.Errorf("Expected %q, got %q (%v)", expected, result, err)
+	}
+
+	// 加载失败不是"找不到"，返回真实错误
+	refTool := NewReadReferenceTool(skill).WithNotFoundHint(true)
+	if _, err := refTool.InvokableRun(ctx, `{"skill_name":"p","reference_name":"broken"}`); err == nil || !strings.Contains(err.Error(), "disk read failed") {
+		t.Errorf("Expected loader error, got %v", err)
+	}
+}
+
+func TestScriptFromTool(t *testing.T) {
+	type ctxKey struct{}
+	type GreetInput struct {
+		Name string `json:"name"`
+	}
+	type GreetOutput struct {
+		Greeting string `json:"greeting"`
+	}
+
+	// 从 ctx 读取前缀，验证 context 传递
+	greetTool, err := utils.InferTool("greet", "Greet someone by name", func(ctx context.Context, input GreetInput) (GreetOutput, error) {
+		prefix, _ := ctx.Value(ctxKey{}).(string)
+		return GreetOutput{Greeting: prefix + input.Name}, nil
+	})
+	if err != nil {
+		t.Fatalf("InferTool failed: %v", err)
+	}
+
+	script := ScriptFromTool(greetTool)
+	if script.GetName() != "greet" {
+		t.Errorf("Expected name 'greet', got '%s'", script.GetName())
+	}
+	if script.GetUsage() != "Greet someone by name" {
+		t.Errorf("Expected usage from tool desc, got '%s'", script.GetUsage())
+	}
+	schemaProvider, ok := script.(resources.SchemaProvider)
+	if !ok {
+		t.Fatal("Expected script to implement SchemaProvider")
+	}
+	if s, err := schemaProvider.Schema(context.Background()); err != nil || !strings.Contains(string(s.Input), `"name"`) {
+		t.Errorf("Expected input schema with name, got %v (%v)", s, err)
+	}
+
+	// 通过 Skill 执行
+	skill := core.CreateSkill("tools_skill", "Skill backed by eino tools",
+		core.WithResourceProvider(ProviderFromTools(greetTool)),
+		core.WithBody("Use <script>greet</script>"),
+	)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "Hello, ")
+	result, err := skill.UseScript(ctx, "greet", `{"name":"Ada"}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	var output GreetOutput
+	if err := json.Unmarshal([]byte(result), &output); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if output.Greeting != "Hello, Ada" {
+		t.Errorf("Expected 'Hello, Ada', got '%s'", output.Greeting)
+	}
+}
+
+// flakyInfoTool Info 第一次调用失败的 Tool
+type flakyInfoTool struct {
+	calls int
+}
+
+func (f *flakyInfoTool) Info(ctx context.Context) (*einosch.ToolInfo, error) {
+	f.calls++
+	if f.calls == 1 {
+		return nil, errors.New("registry unavailable")
+	}
+	return &einosch.ToolInfo{Name: "flaky", Desc: "Recovered tool"}, nil
+}
+
+func (f *flakyInfoTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	return args, nil
+}
+
+func TestScriptFromTool_RetriesFailedInfo(t *testing.T) {
+	flaky := &flakyInfoTool{}
+	script := ScriptFromTool(flaky)
+
+	if name := script.GetName(); name != "" {
+		t.Errorf("Expected empty name while Info fails, got %q", name)
+	}
+	if name := script.GetName(); name != "flaky" {
+		t.Errorf("Expected Info to be retried after failure, got %q", name)
+	}
+	if usage := script.GetUsage(); usage != "Recovered tool" || flaky.calls != 2 {
+		t.Errorf("Expected successful Info to be cached, got %q after %d calls", usage, flaky.calls)
+	}
+}
+
+func TestSkillTool_NilMetadata(t *testing.T) {
+	ctx := context.Background()
+	skill := &schema.Skill{Body: "body"}
+
+	info, err := NewSkillTool(skill).Info(ctx)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Name != "" || info.Desc != "" {
+		t.Errorf("Expected empty name and description, got %q %q", info.Name, info.Desc)
+	}
+
+	// 构建共享工具时同样不会 panic
+	if tools := ToTools(skill); len(tools) == 0 {
+		t.Error("Expected tools for skill without metadata")
+	}
+}
+
+func TestToPerScriptTools(t *testing.T) {
+	ctx := context.Background()
+	echo := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"skill": "weather"}, nil
+	}
+	weather := core.CreateSkill("weather", "Weather",
+		core.WithScript(core.CreateScript("get_timezone", echo)),
+		core.WithScript(core.CreateScript("forecast", echo)),
+		core.WithAutoParsedBody("<script>forecast</script> <script>get_timezone</script> <script>forecast</script>"),
+	)
+
+	tools := ToPerScriptTools(createTestTimeSkill(), weather)
+	if len(tools) != 4 {
+		t.Fatalf("Expected 4 tools, got %d", len(tools))
+	}
+
+	names := make([]string, len(tools))
+	for i, tl := range tools {
+		info, err := tl.Info(ctx)
+		if err != nil {
+			t.Fatalf("Info failed: %v", err)
+		}
+		names[i] = info.Name
+	}
+	expected := []string{"time_skill__get_current_time", "time_skill__get_timezone", "weather__forecast", "weather__get_timezone"}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected tool %d to be %s, got %s", i, expected[i], names[i])
+		}
+	}
+
+	// 参数取自脚本的输入 schema
+	info, _ := tools[0].Info(ctx)
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema failed: %v", err)
+	}
+	if _, ok := js.Properties.Get("timezone"); !ok {
+		t.Error("Expected timezone parameter from script schema")
+	}
+
+	// 同名脚本调用各自 Skill 的实现
+	result, err := tools[1].InvokableRun(ctx, `{}`)
+	if err != nil || result != `{"timezone":"UTC"}` {
+		t.Errorf("Expected time_skill timezone result, got %s (%v)", result, err)
+	}
+	result, err = tools[3].InvokableRun(ctx, "")
+	if err != nil || result != `{"skill":"weather"}` {
+		t.Errorf("Expected weather result, got %s (%v)", result, err)
+	}
+}
+
+func TestToPerScriptTools_NameCollision(t *testing.T) {
+	ctx := context.Background()
+	noop := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	}
+	a := core.CreateSkill("a__b", "A",
+		core.WithScript(core.CreateScript("c", noop)),
+		core.WithAutoParsedBody("<script>c</script>"),
+	)
+	b := core.CreateSkill("a", "B",
+		core.WithScript(core.CreateScript("b__c", noop)),
+		core.WithAutoParsedBody("<script>b__c</script>"),
+	)
+
+	tools := ToPerScriptTools(a, b)
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(tools))
+	}
+	first, _ := tools[0].Info(ctx)
+	second, _ := tools[1].Info(ctx)
+	if first.Name != "a__b__c" || second.Name != "a__b__c_2" {
+		t.Errorf("Expected unique names, got %s and %s", first.Name, second.Name)
+	}
+}
@@ -0,0 +1,129 @@
+package eino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	skillschema "github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	einosch "github.com/cloudwego/eino/schema"
+)
+
+// TypedScriptTool 将单个 EasyScript 直接封装为 Eino Tool
+// 参数 schema 由输入类型 I 反射生成，未标记 omitempty 的字段视为必填。
+// I 为切片或数组时，Tool 参数为 {"items": [...]}（函数调用的参数必须是对象），执行时解包为数组传给脚本
+type TypedScriptTool[I, O any] struct {
+	script        *resources.EasyScript[I, O]
+	recoverPanics bool // 将脚本 panic 恢复为 *schema.ScriptPanicError
+}
+
+// NewTypedScriptTool 创建一个新的 TypedScriptTool
+func NewTypedScriptTool[I, O any](script *resources.EasyScript[I, O]) *TypedScriptTool[I, O] {
+	return &TypedScriptTool[I, O]{script: script}
+}
+
+// WithPanicRecovery 开启后脚本中的 panic 会被恢复并作为 *schema.ScriptPanicError 返回
+// 与 Skill.RecoverPanics 使用同一恢复逻辑；由 Skill 创建的工具应与 Skill 的设置保持一致
+func (t *TypedScriptTool[I, O]) WithPanicRecovery(enabled bool) *TypedScriptTool[I, O] {
+	t.recoverPanics = enabled
+	return t
+}
+
+// Info 返回 Tool 的元信息
+func (t *TypedScriptTool[I, O]) Info(ctx context.Context) (*einosch.ToolInfo, error) {
+	if inType := util.TypeOf[I](); isArrayType(inType) {
+		return &einosch.ToolInfo{
+			Name: t.script.GetName(),
+			Desc: t.script.GetUsage(),
+			ParamsOneOf: einosch.NewParamsOneOfByParams(map[string]*einosch.ParameterInfo{
+				arrayParamName: {
+					Type:     einosch.Array,
+					ElemInfo: &einosch.ParameterInfo{Type: dataType(inType.Elem())},
+					Desc:     "the script's array input",
+					Required: true,
+				},
+			}),
+		}, nil
+	}
+
+	params, err := utils.GoStruct2ParamsOneOf[I]()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build params for script %s: %w", t.script.GetName(), err)
+	}
+	return &einosch.ToolInfo{
+		Name:        t.script.GetName(),
+		Desc:        t.script.GetUsage(),
+		ParamsOneOf: params,
+	}, nil
+}
+
+// InvokableRun 执行 Tool
+// 参数直接反序列化为 I，返回序列化后的 O
+func (t *TypedScriptTool[I, O]) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (result string, err error) {
+	if t.recoverPanics {
+		defer skillschema.RecoverScriptPanic(t.script.GetName(), &err)
+	}
+	if argumentsInJSON == "" {
+		argumentsInJSON = "{}"
+	}
+	if isArrayType(util.TypeOf[I]()) {
+		args, err := unwrapArrayArgs(argumentsInJSON)
+		if err != nil {
+			return "", err
+		}
+		argumentsInJSON = args
+	}
+	return t.script.Run(ctx, argumentsInJSON)
+}
+
+// arrayParamName 数组输入在 Tool 参数对象中的字段名
+const arrayParamName = "items"
+
+// isArrayType 判断类型是否为切片或数组（[]byte 按 base64 字符串处理，不视为数组）
+func isArrayType(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+// unwrapArrayArgs 从 {"items": [...]} 中取出数组；参数本身已是数组时原样返回
+func unwrapArrayArgs(args string) (string, error) {
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(args), &wrapper); err != nil {
+		var items []json.RawMessage
+		if json.Unmarshal([]byte(args), &items) == nil {
+			return args, nil
+		}
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	items, ok := wrapper[arrayParamName]
+	if !ok {
+		return "[]", nil
+	}
+	return string(items), nil
+}
+
+// dataType 将 Go 类型映射为 Tool 参数类型
+func dataType(t reflect.Type) einosch.DataType {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return einosch.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return einosch.Integer
+	case reflect.Float32, reflect.Float64:
+		return einosch.Number
+	case reflect.String:
+		return einosch.String
+	case reflect.Slice, reflect.Array:
+		return einosch.Array
+	default:
+		return einosch.Object
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultAuditBufferSize 审计缓冲区的默认大小
+const defaultAuditBufferSize = 1024
+
+// AuditEntry 一次脚本执行的审计记录
+// 默认只记录参数和结果的 SHA-256 摘要，开启 WithAuditRawValues 后才记录原始值
+type AuditEntry struct {
+	Timestamp  time.Time     `json:"timestamp"`
+	Actor      string        `json:"actor,omitempty"`
+	SkillName  string        `json:"skill_name"`
+	ScriptName string        `json:"script_name"`
+	ArgsHash   string        `json:"args_hash"`
+	ResultHash string        `json:"result_hash,omitempty"`
+	Args       string        `json:"args,omitempty"`
+	Result     string        `json:"result,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// AuditSink 审计记录的接收者
+// Record 在后台协程中串行调用，不会阻塞脚本执行
+type AuditSink interface {
+	Record(entry AuditEntry)
+}
+
+// AuditOption 审计配置选项
+type AuditOption func(*auditConfig)
+
+type auditConfig struct {
+	raw        bool
+	bufferSize int
+}
+
+// WithAuditRawValues 在审计记录中保存原始参数和结果（默认只保存摘要）
+func WithAuditRawValues() AuditOption {
+	return func(c *auditConfig) {
+		c.raw = true
+	}
+}
+
+// WithAuditBufferSize 设置审计缓冲区大小，缓冲区满时丢弃新的记录
+func WithAuditBufferSize(n int) AuditOption {
+	return func(c *auditConfig) {
+		c.bufferSize = n
+	}
+}
+
+// WithAuditSink 为每次 UseScript 调用记录审计日志
+// 记录通过缓冲队列异步写入 sink，缓冲区满时丢弃并输出日志，不会阻塞请求；Close 会写入所有排队的记录
+func WithAuditSink(sink AuditSink, opts ...AuditOption) ManagerOption {
+	return func(m *SkillManager) {
+		config := auditConfig{bufferSize: defaultAuditBufferSize}
+		for _, opt := range opts {
+			opt(&config)
+		}
+		if config.bufferSize <= 0 {
+			config.bufferSize = 1
+		}
+		m.auditor = newAuditor(sink, config)
+	}
+}
+
+// actorKey 用于在 context 中传递调用者身份
+type actorKey struct{}
+
+// WithActor 在 context 中记录调用者身份，用于审计
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 获取 context 中的调用者身份
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+// auditor 异步写入审计记录
+type auditor struct {
+	sink   AuditSink
+	config auditConfig
+
+	mu      sync.RWMutex // 保护 closed，并保证 close 之后没有新的记录
+	closed  bool
+	entries chan AuditEntry
+	done    chan struct{}
+}
+
+func newAuditor(sink AuditSink, config auditConfig) *auditor {
+	a := &auditor{
+		sink:    sink,
+		config:  config,
+		entries: make(chan AuditEntry, config.bufferSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *auditor) run() {
+	defer close(a.done)
+	for entry := range a.entries {
+		a.sink.Record(entry)
+	}
+}
+
+// record 构造审计记录并入队，队列已满或已关闭时丢弃
+func (a *auditor) record(ctx context.Context, skillName, scriptName, args, result string, start time.Time, err error) {
+	entry := AuditEntry{
+		Timestamp:  start,
+		SkillName:  skillName,
+		ScriptName: scriptName,
+		ArgsHash:   hashString(args),
+		Duration:   time.Since(start),
+	}
+	entry.Actor, _ = ActorFromContext(ctx)
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.ResultHash = hashString(result)
+	}
+	if a.config.raw {
+		entry.Args = args
+		entry.Result = result
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.entries <- entry:
+	default:
+		log.Printf("skill manager: audit buffer full, dropping entry for %s.%s", skillName, scriptName)
+	}
+}
+
+// close 停止接收记录，并等待排队的记录写入完成
+func (a *auditor) close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.entries)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
+
+// hashString 返回 s 的 SHA-256 摘要（十六进制）
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// JSONLinesAuditSink 以 JSON Lines 格式写入审计记录
+type JSONLinesAuditSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLinesAuditSink 创建写入 w 的 JSON Lines 审计 sink
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{w: w}
+}
+
+// NewFileAuditSink 创建追加写入文件的 JSON Lines 审计 sink
+func NewFileAuditSink(path string) (*JSONLinesAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesAuditSink{w: f, closer: f}, nil
+}
+
+// Record 写入一行 JSON 记录，写入失败时输出日志
+func (s *JSONLinesAuditSink) Record(entry AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := json.NewEncoder(s.w).Encode(entry); err != nil {
+		log.Printf("skill manager: failed to write audit entry: %v", err)
+	}
+}
+
+// Close 关闭底层文件（如果有）
+func (s *JSONLinesAuditSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// Ensure JSONLinesAuditSink implements AuditSink
+var _ AuditSink = (*JSONLinesAuditSink)(nil)
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
+)
+
+// ScriptCatalogEntry 脚本目录中的一项
+type ScriptCatalogEntry struct {
+	Skill       string          `json:"skill"`
+	Script      string          `json:"script"`
+	Usage       string          `json:"usage,omitempty"`        // 无法解析的脚本为空
+	InputSchema json.RawMessage `json:"input_schema,omitempty"` // 脚本未实现 SchemaProvider 时为空
+}
+
+// CatalogScripts 列举 Store 中的所有 Skill，为每个 Body 中引用的 <script> 生成一项目录，便于统一注册工具
+// Skill 经 GetSkill 加载（使用缓存），使用说明和输入 schema 经 Provider 解析。
+// 结果按 Skill 名称排序，同一 Skill 内按脚本在 Body 中首次出现的顺序排列。
+// 单个 Skill 加载失败不会中止其余 Skill：其余目录项照常返回，失败汇总在返回的 error 中
+func (m *SkillManager) CatalogScripts(ctx context.Context) ([]ScriptCatalogEntry, error) {
+	if m.store == nil {
+		return nil, errors.New("skill store not configured")
+	}
+	metadatas, err := m.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+	names := make([]string, 0, len(metadatas))
+	for _, metadata := range metadatas {
+		names = append(names, metadata.Name)
+	}
+	sort.Strings(names)
+
+	perSkill := make([][]ScriptCatalogEntry, len(names))
+	skillErrs := make([]error, len(names))
+	err = forEachBounded(ctx, names, skillLoadConcurrency, func(i int, name string) {
+		perSkill[i], skillErrs[i] = m.catalogSkill(ctx, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ScriptCatalogEntry
+	for _, skillEntries := range perSkill {
+		entries = append(entries, skillEntries...)
+	}
+	return entries, errors.Join(skillErrs...)
+}
+
+// catalogSkill 加载单个 Skill 并生成其脚本目录项
+func (m *SkillManager) catalogSkill(ctx context.Context, name string) ([]ScriptCatalogEntry, error) {
+	skill, err := m.GetSkill(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load skill %s: %w", name, err)
+	}
+
+	var entries []ScriptCatalogEntry
+	for _, scriptName := range util.UniqueStrings(skill.GetScriptNames()) {
+		entry := ScriptCatalogEntry{Skill: name, Script: scriptName}
+		if script, err := skill.GetScript(ctx, scriptName); err == nil {
+			entry.Usage = script.GetUsage()
+			if provider, ok := script.(resources.SchemaProvider); ok {
+				if s, err := provider.Schema(ctx); err == nil && s != nil {
+					entry.InputSchema = s.Input
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
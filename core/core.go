@@ -2,7 +2,14 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"github.com/alois132/skill/schema"
 	"github.com/alois132/skill/schema/resources"
 )
@@ -10,10 +17,23 @@ import (
 // 不考虑封锁边界，只是为了便捷和美观
 type Option func(skill *schema.Skill)
 
+// SkillOption is accepted by CreateSkill, CreateSkillValidated and CloneWith:
+// either an Option, applied in order, or a late option (see WithGeneratedBody), applied after all Options
+type SkillOption interface {
+	isSkillOption()
+}
+
+func (Option) isSkillOption() {}
+
+// lateOption is applied after all Options of the same CreateSkill or CloneWith call
+type lateOption func(skill *schema.Skill)
+
+func (lateOption) isSkillOption() {}
+
 // create skill
 
 // CreateSkill creates a new skill with the given metadata and options
-func CreateSkill(name string, description string, opts ...Option) *schema.Skill {
+func CreateSkill(name string, description string, opts ...SkillOption) *schema.Skill {
 	skill := &schema.Skill{
 		Metadata: &schema.SkillMetadata{
 			Name:        name,
@@ -25,12 +45,128 @@ func CreateSkill(name string, description string, opts ...Option) *schema.Skill
 		Assets:     []*resources.Asset{},
 	}
 
-	// Apply all options
+	applyOptions(skill, opts)
+
+	return skill
+}
+
+// CreateSkillValidated creates a skill like CreateSkill and validates it with Skill.Validate
+// All issues are returned joined; combine with WithUniqueNames to reject duplicate script, reference or asset names
+func CreateSkillValidated(name string, description string, opts ...SkillOption) (*schema.Skill, error) {
+	skill := CreateSkill(name, description, opts...)
+	if issues := skill.Validate(context.Background()); len(issues) > 0 {
+		return nil, fmt.Errorf("invalid skill %s: %w", name, errors.Join(issues...))
+	}
+	return skill, nil
+}
+
+// CloneWith returns a deep copy of skill with opts applied to the copy
+// Options behave as in CreateSkill, appending to or overriding the cloned fields; the original is untouched
+func CloneWith(skill *schema.Skill, opts ...SkillOption) *schema.Skill {
+	clone := skill.Clone()
+	if clone.Metadata == nil {
+		clone.Metadata = &schema.SkillMetadata{}
+	}
+
+	applyOptions(clone, opts)
+
+	return clone
+}
+
+// applyOptions applies the Options in opts to skill in order, then the late options in order
+func applyOptions(skill *schema.Skill, opts []SkillOption) {
+	var late []lateOption
 	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case Option:
+			opt(skill)
+		case lateOption:
+			late = append(late, opt)
+		}
+	}
+	for _, opt := range late {
 		opt(skill)
 	}
+}
 
-	return skill
+// WithName sets the name of a skill
+func WithName(name string) Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.Name = name
+	}
+}
+
+// WithDescription sets the description of a skill
+func WithDescription(description string) Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.Description = description
+	}
+}
+
+// WithRequiredInputs declares the top-level or dot-separated nested input fields the skill's scripts expect
+// Use Skill.CheckInputs to find missing fields before executing
+func WithRequiredInputs(fields ...string) Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.RequiredInputs = append(skill.Metadata.RequiredInputs, fields...)
+	}
+}
+
+// WithBodyFormat sets the tag grammar version the skill's body is written in
+// Use schema.BodyFormatV2 for bodies with tag attributes; empty means schema.BodyFormatV1
+func WithBodyFormat(format string) Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.BodyFormat = format
+	}
+}
+
+// WithAutoExecuteDefaults sets per-script default args used by AutoExecute
+// Scripts without a default receive the args passed to AutoExecute
+func WithAutoExecuteDefaults(defaults map[string]string) Option {
+	return func(skill *schema.Skill) {
+		if skill.Metadata.AutoExecuteDefaults == nil {
+			skill.Metadata.AutoExecuteDefaults = make(map[string]string, len(defaults))
+		}
+		for name, args := range defaults {
+			skill.Metadata.AutoExecuteDefaults[name] = args
+		}
+	}
+}
+
+// WithPanicRecovery makes the skill recover panics in its scripts and return them as *schema.ScriptPanicError
+// Without it a panicking script crashes the process (fail-fast). The setting is not persisted with the skill;
+// use WithScriptPanicRecovery for skills a SkillManager loads from its store
+func WithPanicRecovery() Option {
+	return func(skill *schema.Skill) {
+		skill.RecoverPanics = true
+	}
+}
+
+// WithExample adds an example invocation of a script, stored in the skill metadata
+// Run the examples with Skill.RunExamples; an expected JSON object matches any result containing its fields
+func WithExample(scriptName string, args string, expectedResult string) Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.Examples = append(skill.Metadata.Examples, schema.Example{
+			Script:   scriptName,
+			Args:     args,
+			Expected: expectedResult,
+		})
+	}
+}
+
+// WithUniqueNames makes Skill.Validate (and so CreateSkillValidated) reject duplicate names
+// within the skill's scripts, references or assets; names are compared case-sensitively
+func WithUniqueNames() Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.UniqueNames = true
+	}
+}
+
+// WithCaseInsensitiveNames is WithUniqueNames comparing names case-insensitively
+func WithCaseInsensitiveNames() Option {
+	return func(skill *schema.Skill) {
+		skill.Metadata.UniqueNames = true
+		skill.Metadata.CaseInsensitiveNames = true
+	}
 }
 
 // create reference
@@ -52,6 +188,18 @@ func WithReference(name string, body string) Option {
 	}
 }
 
+// WithReferenceFormat adds a single reference with an explicit content format
+// (e.g. resources.ReferenceFormatHTML)
+func WithReferenceFormat(name string, body string, format string) Option {
+	return func(skill *schema.Skill) {
+		skill.References = append(skill.References, &resources.Reference{
+			Name:   name,
+			Body:   body,
+			Format: format,
+		})
+	}
+}
+
 // CreateReference creates a new reference with the given name and body
 func CreateReference(name string, body string) *resources.Reference {
 	return &resources.Reference{
@@ -76,6 +224,30 @@ func WithScript(script resources.Script) Option {
 	}
 }
 
+// WithTypedScripts adds one script per map entry, all sharing the input type I
+// Scripts are added in name order so the skill's script list is deterministic,
+// and each is an EasyScript[I, map[string]interface{}] so tools generated from them share I's schema
+func WithTypedScripts[I any](scripts map[string]func(ctx context.Context, input I) (map[string]interface{}, error)) Option {
+	return func(skill *schema.Skill) {
+		names := make([]string, 0, len(scripts))
+		for name := range scripts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			skill.Scripts = append(skill.Scripts, resources.NewEasyScript(name, resources.ScriptFunc[I, map[string]interface{}](scripts[name])))
+		}
+	}
+}
+
+// WithArrayInput adds a script whose input is a top-level JSON array (e.g. args `[10, 5]`) instead of an object
+// EasyScript decodes array args directly into []E; eino.NewTypedScriptTool advertises such scripts
+// with a single required "items" array parameter and unwraps it before running the script
+func WithArrayInput[E, O any](name string, fn func(ctx context.Context, input []E) (O, error)) Option {
+	return WithScript(resources.NewEasyScript(name, resources.ScriptFunc[[]E, O](fn)))
+}
+
 // CreateScript creates a new EasyScript with the given name and function
 func CreateScript[I, O any](name string, fn resources.ScriptFunc[I, O]) resources.Script {
 	return &resources.EasyScript[I, O]{
@@ -109,6 +281,30 @@ func CreateAsset(name string, data []byte, ext resources.AssetExt) *resources.As
 	}
 }
 
+// CreateAssetStrict creates a new asset like CreateAsset, but returns an error if ext is not a registered extension
+func CreateAssetStrict(name string, data []byte, ext resources.AssetExt) (*resources.Asset, error) {
+	if !ext.Valid() {
+		return nil, fmt.Errorf("unsupported asset extension %q for asset %s", ext, name)
+	}
+	return CreateAsset(name, data, ext), nil
+}
+
+// CreateAssetInfer creates a new asset, inferring its extension from the name
+// If the name has no extension, the type is sniffed from data with http.DetectContentType
+// An unregistered extension or an unrecognized content type is an error
+func CreateAssetInfer(name string, data []byte) (*resources.Asset, error) {
+	if ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), ".")); ext != "" {
+		return CreateAssetStrict(name, data, resources.AssetExt(ext))
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := resources.AssetExtForContentType(contentType)
+	if !ok {
+		return nil, fmt.Errorf("cannot determine asset type for %s: detected unsupported content type %q", name, contentType)
+	}
+	return CreateAsset(name, data, ext), nil
+}
+
 // WithBody sets the body of a skill
 func WithBody(body string) Option {
 	return func(skill *schema.Skill) {
@@ -130,6 +326,112 @@ func WithParsedBody(body string) Option {
 	return WithAutoParsedBody(body)
 }
 
+// WithBodyBuilder builds the body with a fluent BodyBuilder
+// The builder starts from the current body, so it can be combined with WithBody
+// 使用 BodyBuilder 构建 Body，构建器以当前 Body 为起点
+func WithBodyBuilder(build func(b *BodyBuilder)) Option {
+	return func(skill *schema.Skill) {
+		b := NewBodyBuilder()
+		b.Text(skill.Body)
+		build(b)
+		skill.Body = b.String()
+		skill.ParseXMLTags()
+	}
+}
+
+// WithGeneratedBody builds the body from the skill's inline scripts and references
+// After all other options are applied, the body becomes prefix, then the existing body, then a
+// <script>/<reference> tag (one per line) for every inline script and reference the body does not already mention
+func WithGeneratedBody(prefix string) SkillOption {
+	return lateOption(func(skill *schema.Skill) {
+		b := NewBodyBuilder()
+		b.Text(prefix)
+		if skill.Body != "" {
+			if prefix != "" && !strings.HasSuffix(prefix, "\n") {
+				b.Text("\n\n")
+			}
+			b.Text(skill.Body)
+		}
+		skill.Body = b.String()
+		skill.ParseXMLTags()
+
+		// Tags already in the prefix or body are not repeated
+		var tags []string
+		seenScripts := nameSet(skill.GetScriptNames())
+		for _, script := range skill.Scripts {
+			if name := script.GetName(); !seenScripts[name] {
+				seenScripts[name] = true
+				tags = append(tags, EmbedScript(name))
+			}
+		}
+		seenRefs := nameSet(skill.GetReferenceNames())
+		for _, ref := range skill.References {
+			if !seenRefs[ref.Name] {
+				seenRefs[ref.Name] = true
+				tags = append(tags, EmbedReference(ref.Name))
+			}
+		}
+		if len(tags) == 0 {
+			return
+		}
+		if skill.Body != "" && !strings.HasSuffix(skill.Body, "\n") {
+			b.Text("\n\n")
+		}
+		b.Text(strings.Join(tags, "\n"))
+		skill.Body = b.String()
+		skill.ParseXMLTags()
+	})
+}
+
+// nameSet returns the set of names
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// BodyBuilder builds a skill body from text and embedded XML tags
+// 用于以链式调用构建 Body 内容
+type BodyBuilder struct {
+	sb strings.Builder
+}
+
+// NewBodyBuilder creates an empty BodyBuilder
+func NewBodyBuilder() *BodyBuilder {
+	return &BodyBuilder{}
+}
+
+// Text appends plain text
+func (b *BodyBuilder) Text(text string) *BodyBuilder {
+	b.sb.WriteString(text)
+	return b
+}
+
+// Script appends a <script>name</script> tag
+func (b *BodyBuilder) Script(name string) *BodyBuilder {
+	b.sb.WriteString(EmbedScript(name))
+	return b
+}
+
+// Reference appends a <reference>name</reference> tag
+func (b *BodyBuilder) Reference(name string) *BodyBuilder {
+	b.sb.WriteString(EmbedReference(name))
+	return b
+}
+
+// Asset appends a <asset>name</asset> tag
+func (b *BodyBuilder) Asset(name string) *BodyBuilder {
+	b.sb.WriteString(EmbedAsset(name))
+	return b
+}
+
+// String returns the built body
+func (b *BodyBuilder) String() string {
+	return b.sb.String()
+}
+
 // Embeddable XML tag functions for constructing body content
 // 用于构建 Body 内容的可嵌入 XML 标记函数
 
@@ -193,6 +495,107 @@ func UseScript(ctx context.Context, skill *schema.Skill, name string, args strin
 	return skill.UseScript(ctx, name, args)
 }
 
+// UseScriptTyped executes a script with a typed input and decodes its result into O
+// The input is marshalled to JSON args; marshal, script and unmarshal errors are wrapped with the script name
+func UseScriptTyped[I, O any](ctx context.Context, skill *schema.Skill, name string, input I) (O, error) {
+	var output O
+	args, err := json.Marshal(input)
+	if err != nil {
+		return output, fmt.Errorf("failed to marshal args for script %s: %w", name, err)
+	}
+	result, err := skill.UseScript(ctx, name, string(args))
+	if err != nil {
+		return output, fmt.Errorf("failed to run script %s: %w", name, err)
+	}
+	if err := json.Unmarshal([]byte(result), &output); err != nil {
+		return output, fmt.Errorf("failed to unmarshal result of script %s: %w", name, err)
+	}
+	return output, nil
+}
+
+// MergeResults is the default reducer for Skill.AutoExecuteReduce
+// It stores each script's parsed JSON result under the script name; results that are not valid JSON
+// are stored as raw strings. A script error aborts the reduction.
+func MergeResults(acc map[string]interface{}, r schema.ScriptResult) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(r.Result), &value); err != nil {
+		acc[r.Name] = r.Result
+		return nil
+	}
+	acc[r.Name] = value
+	return nil
+}
+
+// capability manifest
+
+// CapabilityManifest returns a compact markdown manifest of the given skills for agent prompting
+// Skills are sorted by name; each lists its description and resolvable scripts (body order first,
+// then inline and provider-listed scripts not referenced in the body, sorted) with one-line usages
+// resolved through providers. When a provider fails to list its scripts, the skill still lists the
+// scripts that resolved and the listing errors are returned, joined, alongside the manifest
+func CapabilityManifest(ctx context.Context, skills ...*schema.Skill) (string, error) {
+	sorted := make([]*schema.Skill, 0, len(skills))
+	for _, skill := range skills {
+		if skill != nil {
+			sorted = append(sorted, skill)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+
+	var sb strings.Builder
+	var errs []error
+	for i, skill := range sorted {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("## " + skill.GetName() + "\n")
+		if description := skill.GetDescription(); description != "" {
+			sb.WriteString(description + "\n")
+		}
+
+		available, err := skill.AvailableScripts(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list scripts of skill %s: %w", skill.GetName(), err))
+		}
+		names := append(skill.GetScriptNames(), available...)
+
+		seen := make(map[string]struct{})
+		for _, name := range names {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			script, err := skill.GetScript(ctx, name)
+			if err != nil {
+				continue // 无法解析的脚本不列出
+			}
+			sb.WriteString(fmt.Sprintf("- `%s`: %s\n", name, firstLine(script.GetUsage())))
+		}
+	}
+
+	return sb.String(), errors.Join(errs...)
+}
+
+// firstLine returns the first non-empty line of s, trimmed
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
 // read skill's reference
 
 // ReadReference reads a reference by name from the given skill
@@ -204,7 +607,7 @@ func ReadReference(skill *schema.Skill, name string) (string, error) {
 // This allows the skill to dynamically load resources from external sources
 func WithResourceProvider(provider resources.ResourceProvider) Option {
 	return func(skill *schema.Skill) {
-		skill.Provider = provider
+		skill.SetProvider(provider)
 	}
 }
 
@@ -0,0 +1,584 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/schema/store"
+	"github.com/alois132/skill/util"
+)
+
+func TestWithBodyBuilder(t *testing.T) {
+	skill := CreateSkill("builder_skill", "Skill built with BodyBuilder",
+		WithBody("说明："),
+		WithBodyBuilder(func(b *BodyBuilder) {
+			b.Text("第一步：").Script("init").
+				Text("\n参考：").Reference("guide").
+				Text("\n模板：").Asset("template.png")
+		}),
+	)
+
+	expected := "说明：" + "第一步：" + EmbedScript("init") +
+		"\n参考：" + EmbedReference("guide") +
+		"\n模板：" + EmbedAsset("template.png")
+	if skill.Body != expected {
+		t.Errorf("Expected body %q, got %q", expected, skill.Body)
+	}
+
+	tags := skill.GetParsedTags()
+	if len(tags) != 3 {
+		t.Fatalf("Expected 3 parsed tags, got %d", len(tags))
+	}
+	if tags[0].TagName != "script" || tags[0].Content != "init" {
+		t.Errorf("Unexpected first tag: %+v", tags[0])
+	}
+	if tags[1].TagName != "reference" || tags[1].Content != "guide" {
+		t.Errorf("Unexpected second tag: %+v", tags[1])
+	}
+	if tags[2].TagName != "asset" || tags[2].Content != "template.png" {
+		t.Errorf("Unexpected third tag: %+v", tags[2])
+	}
+}
+
+func TestWithGeneratedBody(t *testing.T) {
+	add := CreateScript("add", func(ctx context.Context, input map[string]float64) (float64, error) {
+		return input["a"] + input["b"], nil
+	})
+	sub := CreateScript("sub", func(ctx context.Context, input map[string]float64) (float64, error) {
+		return input["a"] - input["b"], nil
+	})
+
+	// WithGeneratedBody 在所有其他选项之后生效，与顺序无关
+	skill := CreateSkill("calc", "Calculator",
+		WithGeneratedBody("Simple arithmetic."),
+		WithBody("Use <script>add</script> for sums."),
+		WithScript(add),
+		WithScript(sub),
+		WithReference("guide", "how to calculate"),
+	)
+
+	if !strings.HasPrefix(skill.Body, "Simple arithmetic.\n\nUse <script>add</script> for sums.") {
+		t.Errorf("Expected prefix followed by existing body, got %q", skill.Body)
+	}
+	for _, tag := range []string{EmbedScript("add"), EmbedScript("sub"), EmbedReference("guide")} {
+		if n := strings.Count(skill.Body, tag); n != 1 {
+			t.Errorf("Expected %s exactly once, got %d in %q", tag, n, skill.Body)
+		}
+	}
+	if err := skill.ParseXMLTags(); err != nil {
+		t.Fatalf("Expected generated body to parse, got %v", err)
+	}
+	if names := skill.GetScriptNames(); len(names) != 2 || names[0] != "add" || names[1] != "sub" {
+		t.Errorf("Expected scripts [add sub], got %v", names)
+	}
+	if names := skill.GetReferenceNames(); len(names) != 1 || names[0] != "guide" {
+		t.Errorf("Expected references [guide], got %v", names)
+	}
+
+	// CloneWith 中同样在其他选项之后生效
+	clone := CloneWith(CreateSkill("calc", "Calculator"), WithGeneratedBody(""), WithScript(add))
+	if clone.Body != EmbedScript("add") {
+		t.Errorf("Expected generated body on clone, got %q", clone.Body)
+	}
+}
+
+func TestCapabilityManifest_Provider(t *testing.T) {
+	ctx := context.Background()
+
+	provider := CreateInlineProvider()
+	provider.AddScript(CreateRemoteScript("remote_calc", resources.NewMockRemoteScriptClient()))
+	skill := CreateSkill("calc", "Calculator",
+		WithBody("Use <script>remote_calc</script> or <script>dangling</script>"),
+		WithResourceProvider(provider),
+	)
+
+	manifest, err := CapabilityManifest(ctx, skill)
+	if err != nil {
+		t.Fatalf("CapabilityManifest failed: %v", err)
+	}
+	expected := "## calc\nCalculator\n- `remote_calc`: Remote script: remote_calc\n"
+	if manifest != expected {
+		t.Errorf("Expected manifest %q, got %q", expected, manifest)
+	}
+}
+
+func TestCapabilityManifest_ProviderOnlyScripts(t *testing.T) {
+	ctx := context.Background()
+
+	// Provider 列出但 Body 未引用的脚本同样列出
+	provider := CreateInlineProvider()
+	provider.AddScript(CreateRemoteScript("remote_calc", resources.NewMockRemoteScriptClient()))
+	skill := CreateSkill("calc", "Calculator", WithResourceProvider(provider))
+
+	manifest, err := CapabilityManifest(ctx, skill)
+	if err != nil {
+		t.Fatalf("CapabilityManifest failed: %v", err)
+	}
+	expected := "## calc\nCalculator\n- `remote_calc`: Remote script: remote_calc\n"
+	if manifest != expected {
+		t.Errorf("Expected manifest %q, got %q", expected, manifest)
+	}
+
+	// Provider 列举失败时仍列出可解析的脚本，同时返回错误
+	listErr := errors.New("provider unavailable")
+	broken := CreateSkill("broken", "Broken",
+		WithBody("<script>inline</script>"),
+		WithScript(CreateRemoteScript("inline", resources.NewMockRemoteScriptClient())),
+		WithResourceProvider(resources.NewLazyLoadingProvider(func(ctx context.Context) (resources.ResourceProvider, error) {
+			return nil, listErr
+		})),
+	)
+	manifest, err = CapabilityManifest(ctx, skill, broken)
+	if !errors.Is(err, listErr) {
+		t.Errorf("Expected provider error, got %v", err)
+	}
+	expected = "## broken\nBroken\n- `inline`: Remote script: inline\n\n## calc\nCalculator\n- `remote_calc`: Remote script: remote_calc\n"
+	if manifest != expected {
+		t.Errorf("Expected partial manifest %q, got %q", expected, manifest)
+	}
+}
+
+type calculator struct {
+	precision int
+}
+
+type calcInput struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+func (c *calculator) Add(ctx context.Context, input calcInput) (map[string]float64, error) {
+	return map[string]float64{"result": input.A + input.B}, nil
+}
+
+func (c *calculator) GetURL(ctx context.Context, input map[string]interface{}) (string, error) {
+	return "http://example.com", nil
+}
+
+func (c *calculator) Precision() int {
+	return c.precision
+}
+
+func TestScriptsFromStruct(t *testing.T) {
+	ctx := context.Background()
+
+	scripts, err := ScriptsFromStruct(&calculator{})
+	if err != nil {
+		t.Fatalf("ScriptsFromStruct failed: %v", err)
+	}
+	if len(scripts) != 2 {
+		t.Fatalf("Expected 2 scripts, got %d", len(scripts))
+	}
+
+	skill := CreateSkill("calc", "Calculator", WithScripts(scripts))
+
+	result, err := skill.UseScript(ctx, "add", `{"a":10,"b":5}`)
+	if err != nil {
+		t.Fatalf("Failed to run add: %v", err)
+	}
+	if result != `{"result":15}` {
+		t.Errorf("Expected '{\"result\":15}', got '%s'", result)
+	}
+
+	result, err = skill.UseScript(ctx, "get_url", `{}`)
+	if err != nil {
+		t.Fatalf("Failed to run get_url: %v", err)
+	}
+	if result != `"http://example.com"` {
+		t.Errorf("Unexpected get_url result: %s", result)
+	}
+
+	// 严格模式下签名不匹配的方法返回错误
+	if _, err := ScriptsFromStruct(&calculator{}, WithStrictSignatures()); err == nil {
+		t.Error("Expected error for Precision method in strict mode")
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	skill := CreateSkill("calc", "Calculator",
+		WithScript(CreateScript("add", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"ok": true}, nil
+		})),
+		WithReference("add", "# add"),
+		WithAutoParsedBody("Use <script>add</script>, see <reference>add</reference>"),
+	)
+
+	if err := RenameTag(skill, "script", "add", "sum"); err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+
+	names := GetScriptNames(skill)
+	if len(names) != 1 || names[0] != "sum" {
+		t.Errorf("Expected script names [sum], got %v", names)
+	}
+	// 不会误改其他类型的标记
+	refs := GetReferenceNames(skill)
+	if len(refs) != 1 || refs[0] != "add" {
+		t.Errorf("Expected reference names [add], got %v", refs)
+	}
+	if skill.References[0].Name != "add" {
+		t.Errorf("Expected inline reference to keep its name, got %s", skill.References[0].Name)
+	}
+
+	// 内联脚本同步重命名
+	if _, err := UseScript(context.Background(), skill, "sum", `{}`); err != nil {
+		t.Errorf("Expected renamed script to be runnable: %v", err)
+	}
+	if _, err := UseScript(context.Background(), skill, "add", `{}`); err == nil {
+		t.Error("Expected old script name to be gone")
+	}
+
+	if err := RenameTag(skill, "widget", "a", "b"); err == nil {
+		t.Error("Expected error for unknown tag kind")
+	}
+}
+
+func TestRenameTag_AttributeTags(t *testing.T) {
+	skill := CreateSkill("calc", "Calculator",
+		WithBody(`Use <script timeout="5s">add</script> and <reference lang="en">add</reference>`),
+	)
+
+	if err := RenameTag(skill, "script", "add", "sum"); err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+	expected := `Use <script timeout="5s">sum</script> and <reference lang="en">add</reference>`
+	if skill.Body != expected {
+		t.Errorf("Expected %s, got %s", expected, skill.Body)
+	}
+}
+
+func TestRenameTag_ArgsAttribute(t *testing.T) {
+	skill := CreateSkill("time", "Time",
+		WithBody(`Run <script args='{"format":"iso"}'>now</script> then <script args="{}">now</script>`),
+	)
+
+	if err := RenameTag(skill, "script", "now", "current"); err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+	expected := `Run <script args='{"format":"iso"}'>current</script> then <script args="{}">current</script>`
+	if skill.Body != expected {
+		t.Errorf("Expected %s, got %s", expected, skill.Body)
+	}
+
+	// 改名后的标记仍带有内联参数
+	tags := util.ParseXMLTags(skill.Body)
+	if len(tags) != 2 || tags[0].Content != "current" || tags[0].Attrs[util.ArgsAttr] != `{"format":"iso"}` {
+		t.Errorf("Expected args attribute to survive rename, got %+v", tags)
+	}
+}
+
+func TestRenameScriptEverywhere(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	for _, skill := range []*schema.Skill{
+		CreateSkill("b", "B", WithBody("<script>add</script>")),
+		CreateSkill("a", "A", WithBody("<script>add</script> and <script>mul</script>")),
+		CreateSkill("c", "C", WithBody("<reference>add</reference>")),
+	} {
+		if err := st.Put(ctx, skill); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	updated, err := RenameScriptEverywhere(ctx, st, "add", "sum")
+	if err != nil {
+		t.Fatalf("RenameScriptEverywhere failed: %v", err)
+	}
+	if len(updated) != 2 || updated[0] != "a" || updated[1] != "b" {
+		t.Errorf("Expected [a b], got %v", updated)
+	}
+
+	skill, _ := st.Get(ctx, "a")
+	if skill.Body != "<script>sum</script> and <script>mul</script>" {
+		t.Errorf("Unexpected body: %s", skill.Body)
+	}
+	skill, _ = st.Get(ctx, "c")
+	if skill.Body != "<reference>add</reference>" {
+		t.Errorf("Expected reference tag untouched, got %s", skill.Body)
+	}
+}
+
+func TestWithRequiredInputs(t *testing.T) {
+	skill := CreateSkill("time_skill", "Time", WithRequiredInputs("format", "options.timezone"))
+
+	missing, err := skill.CheckInputs(`{"options":{"timezone":"UTC"}}`)
+	if err != nil {
+		t.Fatalf("CheckInputs failed: %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "format" {
+		t.Errorf("Expected [format], got %v", missing)
+	}
+
+	// null 视为缺失，嵌套路径缺失
+	missing, err = skill.CheckInputs(`{"format":null,"options":{}}`)
+	if err != nil {
+		t.Fatalf("CheckInputs failed: %v", err)
+	}
+	if len(missing) != 2 || missing[0] != "format" || missing[1] != "options.timezone" {
+		t.Errorf("Expected [format options.timezone], got %v", missing)
+	}
+
+	missing, err = skill.CheckInputs(`{"format":"iso","options":{"timezone":"UTC"}}`)
+	if err != nil || len(missing) != 0 {
+		t.Errorf("Expected no missing fields, got %v, %v", missing, err)
+	}
+
+	if _, err := skill.CheckInputs(`not json`); err == nil {
+		t.Error("Expected error for unparseable args")
+	}
+}
+
+func TestWithTypedScripts(t *testing.T) {
+	type pair struct {
+		A float64 `json:"a"`
+		B float64 `json:"b"`
+	}
+	skill := CreateSkill("calculator", "Basic arithmetic",
+		WithTypedScripts(map[string]func(ctx context.Context, input pair) (map[string]interface{}, error){
+			"subtract": func(ctx context.Context, input pair) (map[string]interface{}, error) {
+				return map[string]interface{}{"result": input.A - input.B}, nil
+			},
+			"add": func(ctx context.Context, input pair) (map[string]interface{}, error) {
+				return map[string]interface{}{"result": input.A + input.B}, nil
+			},
+		}),
+	)
+
+	if len(skill.Scripts) != 2 || skill.Scripts[0].GetName() != "add" || skill.Scripts[1].GetName() != "subtract" {
+		t.Fatalf("Expected scripts [add subtract], got %d scripts", len(skill.Scripts))
+	}
+	// 所有脚本共享同一输入类型
+	for _, script := range skill.Scripts {
+		if _, ok := script.(*resources.EasyScript[pair, map[string]interface{}]); !ok {
+			t.Errorf("Expected %s to be EasyScript over the shared input type, got %T", script.GetName(), script)
+		}
+	}
+	if skill.Scripts[0].GetUsage() != skill.Scripts[1].GetUsage() {
+		t.Errorf("Expected identical usage, got %q and %q", skill.Scripts[0].GetUsage(), skill.Scripts[1].GetUsage())
+	}
+
+	ctx := context.Background()
+	for name, expected := range map[string]string{"add": `{"result":5}`, "subtract": `{"result":1}`} {
+		result, err := skill.UseScript(ctx, name, `{"a":3,"b":2}`)
+		if err != nil {
+			t.Fatalf("UseScript %s failed: %v", name, err)
+		}
+		if result != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, name, result)
+		}
+	}
+}
+
+func TestCreateAssetInfer(t *testing.T) {
+	pngHeader := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	// 从文件扩展名推断
+	asset, err := CreateAssetInfer("logo.PNG", []byte("not really a png"))
+	if err != nil {
+		t.Fatalf("CreateAssetInfer failed: %v", err)
+	}
+	if asset.Ext != resources.PNG || asset.ContentType() != "image/png" {
+		t.Errorf("Expected png from extension, got %s (%s)", asset.Ext, asset.ContentType())
+	}
+
+	// 无扩展名时从内容嗅探
+	asset, err = CreateAssetInfer("logo", pngHeader)
+	if err != nil {
+		t.Fatalf("CreateAssetInfer failed: %v", err)
+	}
+	if asset.Ext != resources.PNG || asset.Name != "logo" {
+		t.Errorf("Expected png from magic bytes, got %s", asset)
+	}
+
+	if _, err := CreateAssetInfer("notes.xyz", pngHeader); err == nil || !strings.Contains(err.Error(), `"xyz"`) {
+		t.Errorf("Expected unsupported extension error, got %v", err)
+	}
+	if _, err := CreateAssetInfer("notes", []byte("plain text")); err == nil || !strings.Contains(err.Error(), "text/plain") {
+		t.Errorf("Expected unknown content type error, got %v", err)
+	}
+
+	if _, err := CreateAssetStrict("doc", nil, resources.AssetExt("docx")); err == nil {
+		t.Error("Expected CreateAssetStrict to reject unregistered extension")
+	}
+	if _, err := CreateAssetStrict("doc.pdf", nil, resources.PDF); err != nil {
+		t.Errorf("Expected CreateAssetStrict to accept pdf, got %v", err)
+	}
+}
+
+func TestWithPanicRecovery(t *testing.T) {
+	ctx := context.Background()
+	panicky := resources.NewEasyScript("config", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		name := input["skill_name"].(string) // 缺少 skill_name 时 panic
+		return map[string]interface{}{"name": name}, nil
+	})
+	skill := CreateSkill("config_skill", "Config", WithScript(panicky), WithPanicRecovery())
+
+	result, err := skill.UseScript(ctx, "config", `{}`)
+	var panicErr *schema.ScriptPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected ScriptPanicError, got %v", err)
+	}
+	if result != "" || panicErr.Script != "config" || panicErr.Value == nil {
+		t.Errorf("Unexpected panic error: %+v", panicErr)
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestWithPanicRecovery") {
+		t.Error("Expected stack trace to be captured")
+	}
+
+	// AutoExecute 同样恢复 panic
+	skill.Body = "<script>config</script>"
+	results, _ := skill.AutoExecute(ctx, `{}`)
+	if len(results) != 1 || !errors.As(results[0].Err, &panicErr) {
+		t.Errorf("Expected AutoExecute to report ScriptPanicError, got %+v", results)
+	}
+
+	// 二进制脚本同样恢复 panic
+	skill.Scripts = append(skill.Scripts, resources.NewBytesScript("image", "image/png", func(ctx context.Context, args string) ([]byte, error) {
+		panic("render failed")
+	}))
+	if _, _, err := skill.UseScriptBytes(ctx, "image", `{}`); !errors.As(err, &panicErr) || panicErr.Script != "image" {
+		t.Errorf("Expected UseScriptBytes to report ScriptPanicError, got %v", err)
+	}
+
+	// 运行时配置不进入元数据，但随 Clone 保留
+	if strings.Contains(skill.Glance(), "recover") {
+		t.Errorf("Expected panic recovery not to be serialized, got %s", skill.Glance())
+	}
+	if !skill.Clone().RecoverPanics {
+		t.Error("Expected clone to keep panic recovery")
+	}
+
+	// 未开启时保持 fail-fast
+	failFast := CreateSkill("config_skill", "Config", WithScript(panicky))
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic without WithPanicRecovery")
+		}
+	}()
+	failFast.UseScript(ctx, "config", `{}`)
+}
+
+func TestWithUniqueNames(t *testing.T) {
+	add := func(name string) resources.Script {
+		return resources.NewEasyScript(name, func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return input, nil
+		})
+	}
+
+	// 两个同名的内联脚本
+	_, err := CreateSkillValidated("dup_skill", "Duplicate scripts",
+		WithUniqueNames(),
+		WithScript(add("add")),
+		WithScript(add("add")),
+	)
+	if !errors.Is(err, schema.ErrDuplicateName) {
+		t.Errorf("Expected ErrDuplicateName, got %v", err)
+	}
+
+	// 校验选项不出现在元数据 JSON 中
+	if glance := CreateSkill("opt_skill", "Options", WithCaseInsensitiveNames()).Glance(); strings.Contains(glance, "names") {
+		t.Errorf("Expected name check options to stay out of Glance, got %s", glance)
+	}
+
+	// 不同类资源同名不算重复
+	skill, err := CreateSkillValidated("ns_skill", "Same name in different namespaces",
+		WithUniqueNames(),
+		WithScript(add("guide")),
+		WithReference("guide", "# Guide"),
+	)
+	if err != nil {
+		t.Errorf("Expected no error across namespaces, got %v", err)
+	}
+	if dups := skill.DuplicateNames(); len(dups) != 0 {
+		t.Errorf("Expected no duplicates, got %v", dups)
+	}
+
+	// 默认区分大小写，可选忽略大小写
+	mixed := []SkillOption{WithScript(add("Add")), WithScript(add("add"))}
+	if _, err := CreateSkillValidated("case_skill", "Mixed case", append(mixed, WithUniqueNames())...); err != nil {
+		t.Errorf("Expected case-sensitive check to pass, got %v", err)
+	}
+	skill = CreateSkill("fold_skill", "Mixed case", append(mixed, WithCaseInsensitiveNames())...)
+	if dups := skill.DuplicateNames(); len(dups["scripts"]) != 1 || dups["scripts"][0] != "add" {
+		t.Errorf("Expected scripts duplicate [add], got %v", dups)
+	}
+	if _, err := CreateSkillValidated("fold_skill", "Mixed case", append(mixed, WithCaseInsensitiveNames())...); !errors.Is(err, schema.ErrDuplicateName) {
+		t.Errorf("Expected ErrDuplicateName with case-insensitive check, got %v", err)
+	}
+
+	// 未开启时 Validate 不报告重复，DuplicateNames 仍可用于诊断
+	skill = CreateSkill("plain_skill", "No check", WithScript(add("add")), WithScript(add("add")))
+	if issues := skill.Validate(context.Background()); len(issues) != 0 {
+		t.Errorf("Expected no issues without WithUniqueNames, got %v", issues)
+	}
+	if dups := skill.DuplicateNames(); len(dups["scripts"]) != 1 {
+		t.Errorf("Expected one duplicate script, got %v", dups)
+	}
+}
+
+func TestBindFileSkill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.yaml")
+	write := func(body string) {
+		content := "metadata:\n  name: dev_skill\n  description: Dev skill\nbody: " + body + "\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	write("first")
+
+	bodies := make(chan string, 10)
+	errs := make(chan error, 10)
+	stop, err := BindFileSkill(context.Background(), path, func(skill *schema.Skill) {
+		bodies <- skill.Body
+	}, WithBindPollInterval(10*time.Millisecond), WithBindDebounce(30*time.Millisecond), WithBindErrorHandler(func(err error) {
+		errs <- err
+	}))
+	if err != nil {
+		t.Fatalf("BindFileSkill failed: %v", err)
+	}
+	defer stop()
+
+	waitBody := func(expected string) {
+		t.Helper()
+		select {
+		case body := <-bodies:
+			if body != expected {
+				t.Errorf("Expected body %q, got %q", expected, body)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for body %q", expected)
+		}
+	}
+	waitBody("first")
+
+	// 修改文件后重新绑定
+	write("second")
+	waitBody("second")
+
+	// 解析错误只报告，不终止监听
+	os.WriteFile(path, []byte("metadata: [unclosed"), 0644)
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for parse error")
+	}
+	write("third")
+	waitBody("third")
+
+	// stop 之后不再绑定
+	stop()
+	write("fourth")
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case body := <-bodies:
+		t.Errorf("Expected no bind after stop, got %q", body)
+	default:
+	}
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+)
+
+// EnvExpansionOption configures WithEnvExpansion
+type EnvExpansionOption func(*envExpansion)
+
+// envExpansion expands ${VAR} / $VAR placeholders in skills loaded from the store
+type envExpansion struct {
+	lookup       func(key string) (string, bool)
+	errorOnUnset bool
+
+	mu      sync.Mutex
+	sources map[string]*expandedSource // keyed by skill name, the source text of the last expansion
+}
+
+// expandedSource remembers the unexpanded text behind an expanded skill so that saving it does not persist expanded values
+type expandedSource struct {
+	body expandedText
+	refs map[string]expandedText
+}
+
+// expandedText is one expanded field: the text read from the store and the text after expansion
+type expandedText struct {
+	source   string
+	expanded string
+}
+
+// WithEnvExpansion expands environment variables in the body and reference bodies of skills loaded from the store.
+// Both ${VAR} and $VAR are supported and $$ produces a literal $; other $ sequences such as $5 or $@ are left untouched.
+// Unset variables expand to an empty string unless WithEnvErrorOnUnset is given.
+func WithEnvExpansion(opts ...EnvExpansionOption) ManagerOption {
+	return func(m *SkillManager) {
+		e := &envExpansion{lookup: os.LookupEnv}
+		for _, opt := range opts {
+			opt(e)
+		}
+		m.envExpansion = e
+	}
+}
+
+// WithEnvLookup replaces os.LookupEnv as the variable source
+func WithEnvLookup(fn func(key string) (string, bool)) EnvExpansionOption {
+	return func(e *envExpansion) {
+		e.lookup = fn
+	}
+}
+
+// WithEnvErrorOnUnset makes loading fail when a referenced variable is not set
+func WithEnvErrorOnUnset() EnvExpansionOption {
+	return func(e *envExpansion) {
+		e.errorOnUnset = true
+	}
+}
+
+// apply expands the skill in place; references are replaced with expanded copies
+// The unexpanded text is remembered so that restore can put the placeholders back before the skill is saved
+func (e *envExpansion) apply(skill *schema.Skill) error {
+	body, err := e.expand(skill.Body)
+	if err != nil {
+		return err
+	}
+
+	source := &expandedSource{
+		body: expandedText{source: skill.Body, expanded: body},
+		refs: make(map[string]expandedText),
+	}
+	refs := make([]*resources.Reference, len(skill.References))
+	for i, ref := range skill.References {
+		if ref == nil {
+			continue
+		}
+		expanded, err := e.expand(ref.Body)
+		if err != nil {
+			return fmt.Errorf("reference %s: %w", ref.Name, err)
+		}
+		copied := *ref
+		copied.Body = expanded
+		refs[i] = &copied
+		if expanded != ref.Body {
+			source.refs[ref.Name] = expandedText{source: ref.Body, expanded: expanded}
+		}
+	}
+
+	if body != skill.Body {
+		skill.Body = body
+		skill.ParseXMLTags()
+	}
+	if skill.References != nil {
+		skill.References = refs
+	}
+
+	e.mu.Lock()
+	if e.sources == nil {
+		e.sources = make(map[string]*expandedSource)
+	}
+	if body == source.body.source && len(source.refs) == 0 {
+		delete(e.sources, skill.GetName())
+	} else {
+		e.sources[skill.GetName()] = source
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// restore returns the skill to persist in place of skill
+// The body and references that still hold their expanded text get their placeholders back, on a clone so the cached
+// skill stays expanded; fields that were edited after loading are saved as given. Without anything to restore, skill
+// itself is returned
+func (e *envExpansion) restore(skill *schema.Skill) *schema.Skill {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	source, ok := e.sources[skill.GetName()]
+	if !ok {
+		return skill
+	}
+
+	var saved *schema.Skill
+	clone := func() *schema.Skill {
+		if saved == nil {
+			saved = skill.Clone()
+		}
+		return saved
+	}
+	if skill.Body == source.body.expanded && skill.Body != source.body.source {
+		clone().Body = source.body.source
+	}
+	for i, ref := range skill.References {
+		if ref == nil {
+			continue
+		}
+		text, ok := source.refs[ref.Name]
+		if ok && ref.Body == text.expanded {
+			clone().References[i].Body = text.source
+		}
+	}
+	if saved == nil {
+		return skill
+	}
+	return saved
+}
+
+// forget drops the remembered source of a skill that was removed
+func (e *envExpansion) forget(name string) {
+	e.mu.Lock()
+	delete(e.sources, name)
+	e.mu.Unlock()
+}
+
+// expand replaces ${NAME} and $NAME in s, where NAME matches [A-Za-z_][A-Za-z0-9_]*, and treats $$ as an escaped $
+// Any other $ sequence (e.g. $5, $@, ${1}, a trailing $) is left as is
+func (e *envExpansion) expand(s string) (string, error) {
+	var sb strings.Builder
+	var missing string
+	resolve := func(key string) {
+		value, ok := e.lookup(key)
+		if !ok && missing == "" {
+			missing = key
+		}
+		sb.WriteString(value)
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			sb.WriteByte('$')
+			i += 2
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 || !isEnvName(s[i+2:i+2+end]) {
+				sb.WriteByte('$')
+				i++
+				continue
+			}
+			resolve(s[i+2 : i+2+end])
+			i += end + 3
+		case isEnvNameStart(next):
+			j := i + 2
+			for j < len(s) && isEnvNameChar(s[j]) {
+				j++
+			}
+			resolve(s[i+1 : j])
+			i = j
+		default:
+			sb.WriteByte('$')
+			i++
+		}
+	}
+
+	if missing != "" && e.errorOnUnset {
+		return "", fmt.Errorf("environment variable not set: %s", missing)
+	}
+	return sb.String(), nil
+}
+
+// isEnvName reports whether name matches [A-Za-z_][A-Za-z0-9_]*
+func isEnvName(name string) bool {
+	if name == "" || !isEnvNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isEnvNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || ('0' <= c && c <= '9')
+}
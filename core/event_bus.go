@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/alois132/skill/schema/store"
+)
+
+// WithEventBus 订阅 Store 的事件总线，收到其他实例引起的 Skill 变更事件时使缓存中的对应 Skill（及脚本结果缓存）失效，
+// 下次 GetSkill 从 Store 重新加载。事件在后台协程中处理，不阻塞 Store 的写入；Close 时停止处理。
+// 事件只按名称匹配（不区分命名空间）；本实例通过 SaveSkill、DeleteSkill 等写入时以 store.WithEventOrigin 标记来源，
+// 自己引起的事件会被忽略，缓存中已更新的条目（包括内联脚本）得以保留
+func WithEventBus(bus store.EventBus) ManagerOption {
+	return func(m *SkillManager) {
+		m.eventBus = bus
+	}
+}
+
+// eventSubscriber 处理事件总线的后台协程
+type eventSubscriber struct {
+	cancel   func()
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// subscribeEvents 订阅事件总线并启动后台处理
+func (m *SkillManager) subscribeEvents(bus store.EventBus) *eventSubscriber {
+	events, cancel := bus.Subscribe()
+	s := &eventSubscriber{
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for {
+			select {
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+				m.handleEvent(evt)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// newInstanceID 生成随机的实例标识，用作事件来源
+func newInstanceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// storeContext 返回写入 Store 时使用的 context，订阅了事件总线时附带本实例的事件来源
+func (m *SkillManager) storeContext(ctx context.Context) context.Context {
+	if m.instanceID == "" {
+		return ctx
+	}
+	return store.WithEventOrigin(ctx, m.instanceID)
+}
+
+// handleEvent 使事件涉及的 Skill 缓存失效，本实例自己引起的事件被忽略
+func (m *SkillManager) handleEvent(evt store.SkillEvent) {
+	if evt.Origin != "" && evt.Origin == m.instanceID {
+		return
+	}
+	m.mu.Lock()
+	m.cache.delete(evt.Name)
+	m.mu.Unlock()
+	m.invalidateSkillResults(evt.Name)
+}
+
+// close 取消订阅、停止处理事件并等待后台协程退出，可重复调用
+func (s *eventSubscriber) close() {
+	s.stopOnce.Do(func() {
+		s.cancel()
+		close(s.stop)
+	})
+	<-s.done
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alois132/skill/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// bindConfig BindFileSkill 的配置
+type bindConfig struct {
+	pollInterval time.Duration
+	debounce     time.Duration
+	onError      func(err error)
+}
+
+// BindOption BindFileSkill 的配置选项
+type BindOption func(*bindConfig)
+
+// WithBindPollInterval 设置检查文件变化的间隔，默认 500ms
+func WithBindPollInterval(d time.Duration) BindOption {
+	return func(c *bindConfig) {
+		c.pollInterval = d
+	}
+}
+
+// WithBindDebounce 设置去抖时长：文件内容在该时长内保持不变后才重新加载，默认 200ms
+// 避免编辑器分多次写入时加载到不完整的文件
+func WithBindDebounce(d time.Duration) BindOption {
+	return func(c *bindConfig) {
+		c.debounce = d
+	}
+}
+
+// WithBindErrorHandler 设置重新加载失败（读取或解析错误）时的回调，默认通过 log 输出
+func WithBindErrorHandler(fn func(err error)) BindOption {
+	return func(c *bindConfig) {
+		c.onError = fn
+	}
+}
+
+// BindFileSkill 从 JSON 或 YAML 文件（按扩展名 .yaml/.yml 判断）加载 Skill 并调用 bind，
+// 之后在文件内容变化时重新加载并再次调用 bind，适用于本地开发时的单文件热加载。
+//
+// 首次加载失败时直接返回错误；之后的读取或解析错误交给错误回调，监听继续，修复文件后会再次加载。
+// 变化通过轮询文件内容检测，内容在去抖时长内保持不变后才重新加载；内容未变（如仅修改时间变化）时不调用 bind。
+// bind 在后台 goroutine 中调用，同一时间只有一次调用。stop 停止监听并等待后台 goroutine 退出，可重复调用；
+// ctx 取消同样会停止监听
+func BindFileSkill(ctx context.Context, path string, bind func(*schema.Skill), opts ...BindOption) (stop func(), err error) {
+	config := &bindConfig{
+		pollInterval: 500 * time.Millisecond,
+		debounce:     200 * time.Millisecond,
+		onError: func(err error) {
+			log.Printf("skill binder: %v", err)
+		},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skill file: %w", err)
+	}
+	skill, err := decodeSkillFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	bind(skill)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchSkillFile(watchCtx, path, data, bind, config)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}, nil
+}
+
+// watchSkillFile 轮询文件内容，内容变化并稳定 debounce 时长后重新加载
+func watchSkillFile(ctx context.Context, path string, applied []byte, bind func(*schema.Skill), config *bindConfig) {
+	ticker := time.NewTicker(config.pollInterval)
+	defer ticker.Stop()
+
+	var pending []byte
+	var pendingSince time.Time
+	var lastReadErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// 编辑器保存时文件可能短暂不存在，同一错误只报告一次
+			if err.Error() != lastReadErr {
+				lastReadErr = err.Error()
+				config.onError(fmt.Errorf("failed to read skill file: %w", err))
+			}
+			continue
+		}
+		lastReadErr = ""
+
+		if bytes.Equal(data, applied) {
+			pending = nil
+			continue
+		}
+		if pending == nil || !bytes.Equal(data, pending) {
+			pending, pendingSince = data, time.Now()
+			continue
+		}
+		if time.Since(pendingSince) < config.debounce {
+			continue
+		}
+
+		applied, pending = data, nil
+		skill, err := decodeSkillFile(path, data)
+		if err != nil {
+			config.onError(err)
+			continue
+		}
+		bind(skill)
+	}
+}
+
+// decodeSkillFile 按扩展名将 JSON 或 YAML 内容解析为 Skill
+// YAML 先转换为 JSON，以复用 Skill 的 json 标签
+func decodeSkillFile(path string, data []byte) (*schema.Skill, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse skill yaml: %w", err)
+		}
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert skill yaml: %w", err)
+		}
+		data = converted
+	}
+
+	var skill schema.Skill
+	if err := json.Unmarshal(data, &skill); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal skill: %w", err)
+	}
+	if skill.Metadata == nil {
+		return nil, errors.New("skill file has no metadata: " + path)
+	}
+	return &skill, nil
+}
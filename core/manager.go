@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/alois132/skill/schema"
 	"github.com/alois132/skill/schema/resources"
@@ -14,9 +17,40 @@ import (
 // SkillManager 统一管理 Skill 的加载、缓存和生命周期
 type SkillManager struct {
 	store     store.SkillStore
-	cache     map[string]*schema.Skill
+	cache     skillCache
 	mu        sync.RWMutex
 	providers map[string]resources.ResourceProvider // skill name -> provider
+
+	defaultProvider resources.ResourceProvider // 所有加载的 Skill 共享的后备 Provider，nil 表示不设置
+
+	clearProviderCaches bool // 重新加载或替换 Provider 时清空 Skill 专属 Provider 的缓存
+
+	writeBehindConfig *writeBehindConfig
+	writeBehind       *writeBehind
+
+	scriptSems map[string]chan struct{} // script name 或 skill.script -> 并发信号量
+
+	failureHandler FailureHandler
+
+	loadSem chan struct{} // 限制并发的 Store 加载数，nil 表示不限制
+
+	envExpansion *envExpansion // 加载时展开环境变量，nil 表示不展开
+
+	auditor *auditor // 脚本执行审计，nil 表示不记录
+
+	cacheTTL     time.Duration // 缓存条目存活时间，0 表示不过期
+	cacheMaxSize int           // 缓存最大条目数，0 表示不限制
+
+	resultCache      *lruCache[string] // 脚本结果缓存，nil 表示不缓存
+	cacheableScripts map[string]bool   // 允许缓存结果的 script name 或 skill.script
+
+	eventBus   store.EventBus   // 订阅的事件总线，nil 表示不订阅
+	subscriber *eventSubscriber // 事件总线的后台处理
+	instanceID string           // 本实例写入 Store 时的事件来源，未订阅事件总线时为空
+
+	readinessHealthChecks bool // Ready 是否检查脚本后端的健康状态
+
+	recoverPanics bool // 为从 Store 加载的 Skill 开启 panic 恢复
 }
 
 // ManagerOption SkillManager 的配置选项
@@ -25,18 +59,47 @@ type ManagerOption func(*SkillManager)
 // NewSkillManager 创建一个新的 Skill 管理器
 func NewSkillManager(store store.SkillStore, opts ...ManagerOption) *SkillManager {
 	m := &SkillManager{
-		store:     store,
-		cache:     make(map[string]*schema.Skill),
-		providers: make(map[string]resources.ResourceProvider),
+		store:      store,
+		providers:  make(map[string]resources.ResourceProvider),
+		scriptSems: make(map[string]chan struct{}),
 	}
 
 	for _, opt := range opts {
 		opt(m)
 	}
 
+	m.cache = newLRUCache[*schema.Skill](m.cacheTTL, m.cacheMaxSize)
+
+	if m.eventBus != nil {
+		m.instanceID = newInstanceID()
+	}
+
+	if m.writeBehindConfig != nil && store != nil {
+		m.writeBehind = newWriteBehind(m.storeContext(context.Background()), store, *m.writeBehindConfig)
+	}
+
+	if m.eventBus != nil {
+		m.subscriber = m.subscribeEvents(m.eventBus)
+	}
+
 	return m
 }
 
+// Close 关闭写后队列和审计队列，关闭前会写入所有排队的 Skill 和审计记录；设置了 WithEventBus 时取消订阅并停止处理事件
+// 都未开启时为空操作
+func (m *SkillManager) Close() error {
+	if m.writeBehind != nil {
+		m.writeBehind.close()
+	}
+	if m.auditor != nil {
+		m.auditor.close()
+	}
+	if m.subscriber != nil {
+		m.subscriber.close()
+	}
+	return nil
+}
+
 // WithManagerResourceProvider 为指定的 Skill 设置资源提供者
 func WithManagerResourceProvider(skillName string, provider resources.ResourceProvider) ManagerOption {
 	return func(m *SkillManager) {
@@ -44,41 +107,149 @@ func WithManagerResourceProvider(skillName string, provider resources.ResourcePr
 	}
 }
 
+// WithDefaultProvider 设置所有从 Store 加载的 Skill 共享的默认资源提供者
+// GetSkill、ReloadSkill 和 ReloadChanged 加载 Skill 时，Skill 专属的 Provider（WithManagerResourceProvider 设置的，
+// 否则为 Skill 自带的）在前、默认 Provider 作为后备组合为 CompositeProvider；没有专属 Provider 时直接使用默认 Provider。
+// 通过 RegisterSkill 注册的 Skill 不受影响
+func WithDefaultProvider(provider resources.ResourceProvider) ManagerOption {
+	return func(m *SkillManager) {
+		m.defaultProvider = provider
+	}
+}
+
+// WithClearProviderCacheOnReload 在 ReloadSkill、ReloadChanged 重新加载 Skill 以及 SetResourceProvider 设置 Provider 时，
+// 清空该 Skill 专属 Provider（WithManagerResourceProvider 或 SetResourceProvider 设置的）的缓存，使重新加载能读到新的资源。
+// 仅对实现了 resources.Clearable 的 Provider（如 CachingProvider）生效；默认 Provider 不会被清空。
+// 同一个缓存 Provider 被多个 Skill 共享时，任意一个 Skill 重新加载都会清空它
+func WithClearProviderCacheOnReload() ManagerOption {
+	return func(m *SkillManager) {
+		m.clearProviderCaches = true
+	}
+}
+
+// clearProviderCache 按配置清空 Skill 专属 Provider 的缓存，调用方需持有 m.mu
+func (m *SkillManager) clearProviderCache(name string) {
+	if !m.clearProviderCaches {
+		return
+	}
+	if clearable, ok := m.providers[name].(resources.Clearable); ok {
+		clearable.ClearCache()
+	}
+}
+
+// attachProvider 为加载的 Skill 设置 Provider，没有任何 Provider 时保持不变
+func (m *SkillManager) attachProvider(name string, skill *schema.Skill) {
+	provider := skill.GetProvider()
+	if p, ok := m.providers[name]; ok {
+		provider = p
+	}
+	if provider = m.withDefaultProvider(provider); provider != nil {
+		skill.SetProvider(provider)
+	}
+}
+
+// withDefaultProvider 将默认 Provider 作为后备组合到 provider 之后
+func (m *SkillManager) withDefaultProvider(provider resources.ResourceProvider) resources.ResourceProvider {
+	switch {
+	case m.defaultProvider == nil:
+		return provider
+	case provider == nil:
+		return m.defaultProvider
+	default:
+		return resources.NewCompositeProvider(provider, m.defaultProvider)
+	}
+}
+
+// WithScriptPanicRecovery 为从 Store 加载的 Skill 开启脚本 panic 恢复（见 core.WithPanicRecovery）
+// panic 恢复是运行时配置，不随 Skill 存储，因此需要在加载时设置
+func WithScriptPanicRecovery() ManagerOption {
+	return func(m *SkillManager) {
+		m.recoverPanics = true
+	}
+}
+
+// WithMaxConcurrentLoads 限制 GetSkill 同时向 Store 发起的加载数，n <= 0 表示不限制
+// 缓存命中不占用名额，等待名额时响应 context 取消
+func WithMaxConcurrentLoads(n int) ManagerOption {
+	return func(m *SkillManager) {
+		if n <= 0 {
+			m.loadSem = nil
+			return
+		}
+		m.loadSem = make(chan struct{}, n)
+	}
+}
+
+// FailureHandler 脚本执行失败时的回调
+type FailureHandler func(ctx context.Context, skillName, scriptName, args string, err error)
+
+// WithFailureHandler 设置脚本执行失败时的回调（如记录日志、指标或放入重试队列）
+// 回调不会修改返回给调用方的错误，执行成功时不会被调用
+func WithFailureHandler(fn FailureHandler) ManagerOption {
+	return func(m *SkillManager) {
+		m.failureHandler = fn
+	}
+}
+
 // GetSkill 获取指定名称的 Skill
 // 优先从缓存获取，如果缓存未命中则从 Store 加载
 func (m *SkillManager) GetSkill(ctx context.Context, name string) (*schema.Skill, error) {
 	// 1. 尝试从缓存获取
-	m.mu.RLock()
-	if skill, ok := m.cache[name]; ok {
-		m.mu.RUnlock()
+	if skill, ok := m.cache.get(name); ok {
 		return skill, nil
 	}
-	m.mu.RUnlock()
 
 	// 2. 从 Store 加载
 	if m.store == nil {
 		return nil, errors.New("skill store not configured")
 	}
 
-	skill, err := m.store.Get(ctx, name)
+	if m.loadSem != nil {
+		select {
+		case m.loadSem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	skill, err := m.load(ctx, name)
+	if m.loadSem != nil {
+		<-m.loadSem
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load skill from store: %w", err)
 	}
 
-	// 3. 如果该 Skill 有配置 ResourceProvider，则设置
-	if provider, ok := m.providers[name]; ok {
-		skill.Provider = provider
-	}
+	// 3. 设置 ResourceProvider（Skill 专属的优先，默认 Provider 作为后备）
+	m.attachProvider(name, skill)
 
 	// 4. 存入缓存
 	m.mu.Lock()
-	m.cache[name] = skill
+	m.cache.set(name, skill)
 	m.mu.Unlock()
+	m.invalidateSkillResults(name)
+
+	return skill, nil
+}
 
+// load 从 Store 读取 Skill，并按配置展开环境变量
+func (m *SkillManager) load(ctx context.Context, name string) (*schema.Skill, error) {
+	skill, err := m.store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if m.recoverPanics {
+		skill.RecoverPanics = true
+	}
+	if m.envExpansion != nil {
+		if err := m.envExpansion.apply(skill); err != nil {
+			return nil, fmt.Errorf("failed to expand skill %s: %w", name, err)
+		}
+	}
 	return skill, nil
 }
 
 // RegisterSkill 直接注册一个 Skill 到管理器（不经过 Store）
+// 注册的 Skill 可能只存在于缓存中，因此不会因 TTL 过期或容量限制被淘汰，直到 ClearCache
 func (m *SkillManager) RegisterSkill(skill *schema.Skill) error {
 	if skill == nil {
 		return errors.New("skill cannot be nil")
@@ -90,28 +261,50 @@ func (m *SkillManager) RegisterSkill(skill *schema.Skill) error {
 	name := skill.Metadata.Name
 
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	m.cache[name] = skill
+	m.cache.pin(name, skill)
+	m.mu.Unlock()
+	m.invalidateSkillResults(name)
 	return nil
 }
 
 // SaveSkill 保存 Skill 到 Store 并更新缓存
+// 开启写后模式时，缓存立即更新，Store 写入由后台队列完成
+// 开启环境变量展开时，仍为展开结果的 Body 和参考文档以加载时的原文（含 ${VAR} 占位符）写入 Store，
+// 缓存中保留展开后的 Skill；加载后被修改过的内容按原样写入
 func (m *SkillManager) SaveSkill(ctx context.Context, skill *schema.Skill) error {
 	if m.store == nil {
 		return errors.New("skill store not configured")
 	}
+	persisted := skill
+	if m.envExpansion != nil && skill != nil {
+		persisted = m.envExpansion.restore(skill)
+	}
+
+	if m.writeBehind != nil {
+		if skill == nil || skill.Metadata == nil || skill.Metadata.Name == "" {
+			return errors.New("skill metadata name cannot be empty")
+		}
+		if err := m.writeBehind.enqueue(ctx, persisted); err != nil {
+			return fmt.Errorf("failed to enqueue skill for saving: %w", err)
+		}
+		m.mu.Lock()
+		m.cache.set(skill.Metadata.Name, skill)
+		m.mu.Unlock()
+		m.invalidateSkillResults(skill.Metadata.Name)
+		return nil
+	}
 
-	if err := m.store.Put(ctx, skill); err != nil {
+	if err := m.store.Put(m.storeContext(ctx), persisted); err != nil {
 		return fmt.Errorf("failed to save skill to store: %w", err)
 	}
 
 	// 更新缓存
 	m.mu.Lock()
 	if skill.Metadata != nil {
-		m.cache[skill.Metadata.Name] = skill
+		m.cache.set(skill.Metadata.Name, skill)
 	}
 	m.mu.Unlock()
+	m.invalidateSkillResults(skill.GetName())
 
 	return nil
 }
@@ -121,40 +314,131 @@ func (m *SkillManager) ReloadSkill(ctx context.Context, name string) (*schema.Sk
 	if m.store == nil {
 		return nil, errors.New("skill store not configured")
 	}
+	// 先写入排队中的 Skill，避免用 Store 中较旧的版本覆盖缓存
+	if err := m.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("failed to flush pending writes: %w", err)
+	}
 
 	// 从 Store 重新加载
-	skill, err := m.store.Get(ctx, name)
+	skill, err := m.load(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reload skill: %w", err)
 	}
-
-	// 如果该 Skill 有配置 ResourceProvider，则设置
-	if provider, ok := m.providers[name]; ok {
-		skill.Provider = provider
+	if old, ok := m.cache.peek(name); ok {
+		keepInlineScripts(skill, old)
 	}
 
+	// 设置 ResourceProvider（Skill 专属的优先，默认 Provider 作为后备）
+	m.attachProvider(name, skill)
+
 	// 更新缓存
 	m.mu.Lock()
-	m.cache[name] = skill
+	m.clearProviderCache(name)
+	m.cache.set(name, skill)
 	m.mu.Unlock()
+	m.invalidateSkillResults(name)
 
 	return skill, nil
 }
 
+// keepInlineScripts 将 old 中 fresh 没有的内联脚本带到 fresh 上
+// 内联脚本（Go 函数）不会被持久化，重新从 Store 加载时保留注册时提供的脚本
+func keepInlineScripts(fresh, old *schema.Skill) {
+	present := make(map[string]bool, len(fresh.Scripts))
+	for _, script := range fresh.Scripts {
+		present[script.GetName()] = true
+	}
+	for _, script := range old.Scripts {
+		if !present[script.GetName()] {
+			fresh.Scripts = append(fresh.Scripts, script)
+		}
+	}
+}
+
+// ReloadChanged 重新加载内容已在 Store 中变化的缓存 Skill
+// 通过 ContentHash 比较缓存副本与 Store 中的最新副本，仅替换发生变化的 Skill，返回被重新加载的名称（已排序）
+// Store 中不存在的缓存 Skill（例如通过 RegisterSkill 注册的）会被跳过；开启写后模式时先写入排队中的 Skill
+func (m *SkillManager) ReloadChanged(ctx context.Context) ([]string, error) {
+	if m.store == nil {
+		return nil, errors.New("skill store not configured")
+	}
+	if err := m.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("failed to flush pending writes: %w", err)
+	}
+
+	cached := m.cache.snapshot()
+	names := make([]string, 0, len(cached))
+	for name := range cached {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		resultMu sync.Mutex
+		reloaded []string
+		errs     []error
+	)
+
+	err := forEachBounded(ctx, names, skillLoadConcurrency, func(_ int, name string) {
+		old := cached[name]
+		fresh, err := m.load(ctx, name)
+		if err != nil {
+			if exists, existsErr := m.store.Exists(ctx, name); existsErr == nil && !exists {
+				return
+			}
+			resultMu.Lock()
+			errs = append(errs, fmt.Errorf("failed to reload skill %s: %w", name, err))
+			resultMu.Unlock()
+			return
+		}
+
+		if fresh.ContentHash() == old.ContentHash() {
+			return
+		}
+		keepInlineScripts(fresh, old)
+
+		m.mu.Lock()
+		m.clearProviderCache(name)
+		m.attachProvider(name, fresh)
+		m.cache.set(name, fresh)
+		m.mu.Unlock()
+		m.invalidateSkillResults(name)
+
+		resultMu.Lock()
+		reloaded = append(reloaded, name)
+		resultMu.Unlock()
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	sort.Strings(reloaded)
+	return reloaded, errors.Join(errs...)
+}
+
 // DeleteSkill 从 Store 和缓存中删除指定的 Skill
+// 开启写后模式时丢弃该 Skill 尚未写入的排队写入；只存在于写入队列中的 Skill 同样可以删除
 func (m *SkillManager) DeleteSkill(ctx context.Context, name string) error {
 	if m.store == nil {
 		return errors.New("skill store not configured")
 	}
 
-	if err := m.store.Delete(ctx, name); err != nil {
+	discarded, err := m.discardPendingWrite(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := m.store.Delete(m.storeContext(ctx), name); err != nil && !(discarded && errors.Is(err, store.ErrNotFound)) {
 		return fmt.Errorf("failed to delete skill: %w", err)
 	}
+	if m.envExpansion != nil {
+		m.envExpansion.forget(name)
+	}
 
 	// 从缓存中移除
 	m.mu.Lock()
-	delete(m.cache, name)
+	m.cache.delete(name)
 	m.mu.Unlock()
+	m.invalidateSkillResults(name)
 
 	return nil
 }
@@ -163,11 +447,9 @@ func (m *SkillManager) DeleteSkill(ctx context.Context, name string) error {
 func (m *SkillManager) ListSkills(ctx context.Context) ([]*schema.SkillMetadata, error) {
 	if m.store == nil {
 		// 如果没有 Store，返回缓存中的 Skill 元数据
-		m.mu.RLock()
-		defer m.mu.RUnlock()
-
-		metadatas := make([]*schema.SkillMetadata, 0, len(m.cache))
-		for _, skill := range m.cache {
+		cached := m.cache.snapshot()
+		metadatas := make([]*schema.SkillMetadata, 0, len(cached))
+		for _, skill := range cached {
 			if skill.Metadata != nil {
 				metadatas = append(metadatas, skill.Metadata)
 			}
@@ -178,14 +460,157 @@ func (m *SkillManager) ListSkills(ctx context.Context) ([]*schema.SkillMetadata,
 	return m.store.List(ctx)
 }
 
+// SearchSkills 返回满足查询条件的 Skill 元数据，空查询与 ListSkills 相同
+// Store 实现了 store.SearchableStore 时由 Store 执行查询，否则对 ListSkills 的结果过滤
+func (m *SkillManager) SearchSkills(ctx context.Context, query store.SkillQuery) ([]*schema.SkillMetadata, error) {
+	if searchable, ok := m.store.(store.SearchableStore); ok {
+		return searchable.Search(ctx, query)
+	}
+
+	metadatas, err := m.ListSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Filter(metadatas), nil
+}
+
 // UseScript 执行指定 Skill 的脚本
+// 设置了 WithAuditSink 时每次调用都会记录审计日志；执行失败时（包括 Skill 不存在）会先调用 WithFailureHandler 设置的回调，再返回原始错误
 func (m *SkillManager) UseScript(ctx context.Context, skillName string, scriptName string, args string) (string, error) {
+	start := time.Now()
+	result, err := m.useScript(ctx, skillName, scriptName, args)
+	if m.auditor != nil {
+		m.auditor.record(ctx, skillName, scriptName, args, result, start, err)
+	}
+	if err != nil && m.failureHandler != nil {
+		m.failureHandler(ctx, skillName, scriptName, args, err)
+	}
+	return result, err
+}
+
+// useScript 解析 Skill 并执行脚本
+func (m *SkillManager) useScript(ctx context.Context, skillName string, scriptName string, args string) (string, error) {
 	skill, err := m.GetSkill(ctx, skillName)
 	if err != nil {
 		return "", err
 	}
 
-	return skill.UseScript(ctx, scriptName, args)
+	return m.cachedUseScript(ctx, skillName, scriptName, args, func() (string, error) {
+		release, err := m.acquireScript(ctx, skillName, scriptName)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+
+		return skill.UseScript(ctx, scriptName, args)
+	})
+}
+
+// ReplaceScript 在运行时替换指定 Skill 的脚本实现，之后的 UseScript 调用使用新实现
+// Skill 未缓存时先加载；替换时会生成新的 Skill 对象放入缓存（写时复制），执行中的调用继续使用旧实现。
+// 脚本由 Provider 提供时通过覆盖 Provider 中的同名脚本实现替换；重新加载 Skill 后恢复为原始实现
+func (m *SkillManager) ReplaceScript(ctx context.Context, skillName, scriptName string, script resources.Script) error {
+	if script == nil {
+		return errors.New("script cannot be nil")
+	}
+	if script.GetName() != scriptName {
+		script = resources.RenameScript(script, scriptName)
+	}
+
+	old, err := m.GetSkill(ctx, skillName)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// 在锁外解析 Provider 中的脚本，避免慢速或远程 Provider 阻塞 GetSkill
+		provider := old.GetProvider()
+		inProvider := false
+		if provider != nil {
+			_, err := provider.GetScript(ctx, scriptName)
+			inProvider = err == nil
+		}
+
+		m.mu.Lock()
+		if current, ok := m.cache.peek(skillName); ok && current != old {
+			// 解析期间缓存中的 Skill 被替换，基于新的 Skill 重新解析
+			m.mu.Unlock()
+			old = current
+			continue
+		}
+
+		fresh := old.Clone()
+		replaced := inProvider
+		if inProvider {
+			fresh.SetProvider(&scriptOverrideProvider{ResourceProvider: provider, name: scriptName, script: script})
+		}
+		for i, s := range fresh.Scripts {
+			if s.GetName() == scriptName {
+				fresh.Scripts[i] = script
+				replaced = true
+			}
+		}
+		if !replaced {
+			m.mu.Unlock()
+			return fmt.Errorf("script not found: %s.%s", skillName, scriptName)
+		}
+
+		m.cache.set(skillName, fresh)
+		m.mu.Unlock()
+		m.invalidateSkillResults(skillName)
+		return nil
+	}
+}
+
+// scriptOverrideProvider 覆盖 Provider 中的单个脚本，其余资源委托给原 Provider
+type scriptOverrideProvider struct {
+	resources.ResourceProvider
+	name   string
+	script resources.Script
+}
+
+// GetScript 返回覆盖的脚本或委托给原 Provider
+func (p *scriptOverrideProvider) GetScript(ctx context.Context, name string) (resources.Script, error) {
+	if name == p.name {
+		return p.script, nil
+	}
+	return p.ResourceProvider.GetScript(ctx, name)
+}
+
+// SetScriptConcurrency 限制脚本的最大并发执行数
+// key 可以是脚本名称（对所有 Skill 中的同名脚本生效），也可以是 "skill.script"（只对指定 Skill 生效，优先级更高）
+// max <= 0 表示不限制
+func (m *SkillManager) SetScriptConcurrency(key string, max int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if max <= 0 {
+		delete(m.scriptSems, key)
+		return
+	}
+	m.scriptSems[key] = make(chan struct{}, max)
+}
+
+// acquireScript 获取脚本的并发信号量，等待时响应 context 取消
+// 没有设置限制时直接返回
+func (m *SkillManager) acquireScript(ctx context.Context, skillName, scriptName string) (release func(), err error) {
+	m.mu.RLock()
+	sem, ok := m.scriptSems[skillName+"."+scriptName]
+	if !ok {
+		sem, ok = m.scriptSems[scriptName]
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		return func() {}, nil
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // ReadReference 读取指定 Skill 的参考文档
@@ -195,24 +620,183 @@ func (m *SkillManager) ReadReference(ctx context.Context, skillName string, refN
 		return "", err
 	}
 
-	return skill.ReadReference(refName)
+	return skill.ReadReferenceContext(ctx, refName)
 }
 
-// ClearCache 清空 Skill 缓存
-func (m *SkillManager) ClearCache() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// SkillsWithReference 返回提供指定参考文档的所有 Skill 名称（按名称排序）
+// 扫描缓存和 Store 中的 Skill（不改变缓存内容），匹配 Body 中的 <reference> 标记、内联参考文档和 Provider 声明的参考文档。
+// 加载失败的 Skill 不会中止查询：其余匹配照常返回，失败汇总在返回的 error 中
+func (m *SkillManager) SkillsWithReference(ctx context.Context, refName string) ([]string, error) {
+	return m.skillsMatching(ctx, func(skill *schema.Skill) bool {
+		if slices.Contains(skill.GetReferenceNames(), refName) {
+			return true
+		}
+		for _, ref := range skill.References {
+			if ref.Name == refName {
+				return true
+			}
+		}
+		if provider := skill.GetProvider(); provider != nil {
+			names, err := provider.ListReferences(ctx)
+			return err == nil && slices.Contains(names, refName)
+		}
+		return false
+	})
+}
+
+// SkillsWithAsset 返回提供指定资源文件的所有 Skill 名称（按名称排序）
+// 扫描范围与匹配规则同 SkillsWithReference
+func (m *SkillManager) SkillsWithAsset(ctx context.Context, assetName string) ([]string, error) {
+	return m.skillsMatching(ctx, func(skill *schema.Skill) bool {
+		if slices.Contains(skill.GetAssetNames(), assetName) {
+			return true
+		}
+		for _, asset := range skill.Assets {
+			if asset.Name == assetName {
+				return true
+			}
+		}
+		if provider := skill.GetProvider(); provider != nil {
+			names, err := provider.ListAssets(ctx)
+			return err == nil && slices.Contains(names, assetName)
+		}
+		return false
+	})
+}
+
+// skillsMatching 遍历缓存和 Store 中的所有 Skill，返回满足 match 的 Skill 名称（按名称排序）
+// 未缓存的 Skill 直接从 Store 读取而不放入缓存；加载失败的 Skill 被跳过，失败汇总在返回的 error 中
+func (m *SkillManager) skillsMatching(ctx context.Context, match func(skill *schema.Skill) bool) ([]string, error) {
+	candidates, err := m.allSkillNames(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	m.cache = make(map[string]*schema.Skill)
+	names := make([]string, 0, len(candidates))
+	var errs []error
+	for _, name := range candidates {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		skill, err := m.peekOrLoad(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if match(skill) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, errors.Join(errs...)
+}
+
+// peekOrLoad 返回缓存中的 Skill（不影响命中统计和淘汰顺序），未缓存时从 Store 加载但不放入缓存
+// 用于扫描全部 Skill 的查询，避免把整个 Store 拉进缓存、挤掉正在使用的条目
+func (m *SkillManager) peekOrLoad(ctx context.Context, name string) (*schema.Skill, error) {
+	if skill, ok := m.cache.peek(name); ok {
+		return skill, nil
+	}
+	if m.store == nil {
+		return nil, errors.New("skill store not configured")
+	}
+	skill, err := m.load(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load skill %s: %w", name, err)
+	}
+	m.attachProvider(name, skill)
+	return skill, nil
+}
+
+// allSkillNames 返回缓存和 Store 中所有 Skill 的名称（去重，按名称排序）
+func (m *SkillManager) allSkillNames(ctx context.Context) ([]string, error) {
+	candidates := make(map[string]struct{})
+	for _, name := range m.GetCachedSkillNames() {
+		candidates[name] = struct{}{}
+	}
+	if m.store != nil {
+		metadatas, err := m.store.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list skills: %w", err)
+		}
+		for _, metadata := range metadatas {
+			candidates[metadata.Name] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ValidateAll 加载并校验缓存和 Store 中的所有 Skill，返回 Skill 名称到问题列表的映射（空切片表示有效）
+// 每个 Skill 都会出现在结果中；加载失败记录为该 Skill 的问题，不会中止其余 Skill 的校验。
+// 未缓存的 Skill 直接从 Store 读取，校验不会改变缓存内容。
+// 只有列举 Store 失败或 ctx 被取消时返回 error
+func (m *SkillManager) ValidateAll(ctx context.Context) (map[string][]error, error) {
+	names, err := m.allSkillNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make(map[string][]error, len(names))
+	var mu sync.Mutex
+	err = forEachBounded(ctx, names, skillLoadConcurrency, func(_ int, name string) {
+		var issues []error
+		if skill, err := m.peekOrLoad(ctx, name); err != nil {
+			issues = []error{err}
+		} else {
+			issues = skill.Validate(ctx)
+		}
+
+		mu.Lock()
+		report[name] = issues
+		mu.Unlock()
+	})
+	return report, err
+}
+
+// skillLoadConcurrency 批量加载 Skill 时同时处理的 Skill 数
+const skillLoadConcurrency = 8
+
+// forEachBounded 为 names 中的每一项调用 fn，最多 n 个同时执行；fn 收到名称及其下标
+// 先取得名额再启动协程；ctx 取消后不再启动新的调用，等待已启动的调用结束后返回 ctx.Err()
+func forEachBounded(ctx context.Context, names []string, n int, fn func(i int, name string)) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	sem := make(chan struct{}, n)
+	for i, name := range names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i, name)
+		}(i, name)
+	}
+	return nil
+}
+
+// ClearCache 清空 Skill 缓存和脚本结果缓存
+func (m *SkillManager) ClearCache() {
+	m.cache.clear()
+	m.invalidateResults()
 }
 
 // GetCachedSkillNames 获取当前缓存中的所有 Skill 名称
 func (m *SkillManager) GetCachedSkillNames() []string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	names := make([]string, 0, len(m.cache))
-	for name := range m.cache {
+	cached := m.cache.snapshot()
+	names := make([]string, 0, len(cached))
+	for name := range cached {
 		names = append(names, name)
 	}
 	return names
@@ -220,14 +804,16 @@ func (m *SkillManager) GetCachedSkillNames() []string {
 
 // SetResourceProvider 为指定的 Skill 设置资源提供者
 func (m *SkillManager) SetResourceProvider(skillName string, provider resources.ResourceProvider) {
+	defer m.invalidateSkillResults(skillName)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.providers[skillName] = provider
+	m.clearProviderCache(skillName)
 
 	// 如果 Skill 已在缓存中，更新其 Provider
-	if skill, ok := m.cache[skillName]; ok {
-		skill.Provider = provider
+	if skill, ok := m.cache.peek(skillName); ok {
+		skill.SetProvider(m.withDefaultProvider(provider))
 	}
 }
 
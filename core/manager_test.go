@@ -2,7 +2,19 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alois132/skill/schema"
 	"github.com/alois132/skill/schema/resources"
@@ -398,7 +410,7 @@ func TestSkillManager_SetResourceProvider(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to get skill: %v", err)
 	}
-	if loaded.Provider == nil {
+	if loaded.GetProvider() == nil {
 		t.Error("Expected provider to be set")
 	}
 
@@ -439,7 +451,7 @@ func TestSkillManager_WithManagerResourceProvider(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to get skill: %v", err)
 	}
-	if loaded.Provider == nil {
+	if loaded.GetProvider() == nil {
 		t.Error("Expected provider to be set via option")
 	}
 
@@ -453,6 +465,53 @@ func TestSkillManager_WithManagerResourceProvider(t *testing.T) {
 	}
 }
 
+func TestSkillManager_WithDefaultProvider(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	for _, name := range []string{"plain", "custom"} {
+		if err := memStore.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: name}}); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+	}
+
+	shared := resources.NewInlineProvider()
+	shared.AddReference(&resources.Reference{Name: "style_guide", Body: "# Shared"})
+	shared.AddReference(&resources.Reference{Name: "overridden", Body: "# Default"})
+
+	custom := resources.NewInlineProvider()
+	custom.AddReference(&resources.Reference{Name: "overridden", Body: "# Custom"})
+
+	manager := NewSkillManager(memStore,
+		WithDefaultProvider(shared),
+		WithManagerResourceProvider("custom", custom),
+	)
+
+	// 没有专属 Provider 的 Skill 使用默认 Provider
+	body, err := manager.ReadReference(ctx, "plain", "style_guide")
+	if err != nil || body != "# Shared" {
+		t.Errorf("Expected shared reference, got %q (%v)", body, err)
+	}
+
+	// 专属 Provider 优先，默认 Provider 作为后备
+	body, err = manager.ReadReference(ctx, "custom", "overridden")
+	if err != nil || body != "# Custom" {
+		t.Errorf("Expected skill-specific reference, got %q (%v)", body, err)
+	}
+	body, err = manager.ReadReference(ctx, "custom", "style_guide")
+	if err != nil || body != "# Shared" {
+		t.Errorf("Expected fallback to shared reference, got %q (%v)", body, err)
+	}
+
+	// 重新加载后依然生效
+	reloaded, err := manager.ReloadSkill(ctx, "plain")
+	if err != nil {
+		t.Fatalf("Failed to reload skill: %v", err)
+	}
+	if body, err := reloaded.ReadReference("style_guide"); err != nil || body != "# Shared" {
+		t.Errorf("Expected shared reference after reload, got %q (%v)", body, err)
+	}
+}
+
 func TestSkillManager_GetStore(t *testing.T) {
 	memStore := store.NewMemoryStore()
 	manager := NewSkillManager(memStore)
@@ -461,3 +520,1614 @@ func TestSkillManager_GetStore(t *testing.T) {
 		t.Error("Expected GetStore to return the same store")
 	}
 }
+
+func TestSkillManager_ReloadChanged(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	manager := NewSkillManager(memStore)
+
+	for _, name := range []string{"skill_a", "skill_b", "skill_c"} {
+		skill := &schema.Skill{
+			Metadata: &schema.SkillMetadata{Name: name, Description: "Original"},
+			Body:     "Original body",
+		}
+		if err := memStore.Put(ctx, skill); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+		if _, err := manager.GetSkill(ctx, name); err != nil {
+			t.Fatalf("Failed to get skill: %v", err)
+		}
+	}
+
+	// 只修改 skill_b
+	updated := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "skill_b", Description: "Updated"},
+		Body:     "Updated body",
+	}
+	if err := memStore.Put(ctx, updated); err != nil {
+		t.Fatalf("Failed to put updated skill: %v", err)
+	}
+
+	reloaded, err := manager.ReloadChanged(ctx)
+	if err != nil {
+		t.Fatalf("ReloadChanged failed: %v", err)
+	}
+	if len(reloaded) != 1 || reloaded[0] != "skill_b" {
+		t.Errorf("Expected [skill_b] reloaded, got %v", reloaded)
+	}
+
+	cached, _ := manager.GetSkill(ctx, "skill_b")
+	if cached.Body != "Updated body" {
+		t.Errorf("Expected cached body 'Updated body', got '%s'", cached.Body)
+	}
+
+	// 再次调用不应有变化
+	reloaded, err = manager.ReloadChanged(ctx)
+	if err != nil {
+		t.Fatalf("ReloadChanged failed: %v", err)
+	}
+	if len(reloaded) != 0 {
+		t.Errorf("Expected no reloads, got %v", reloaded)
+	}
+}
+
+// scriptlessStore 像持久化的 Store 一样丢弃内联脚本
+type scriptlessStore struct {
+	store.SkillStore
+}
+
+func (s *scriptlessStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	skill, err := s.SkillStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	skill = skill.Clone()
+	skill.Scripts = nil
+	return skill, nil
+}
+
+func TestSkillManager_ReloadChangedKeepsInlineScripts(t *testing.T) {
+	ctx := context.Background()
+	st := &scriptlessStore{SkillStore: store.NewMemoryStore()}
+	manager := NewSkillManager(st)
+
+	// 内联脚本不会被持久化，缓存副本与 Store 副本只在脚本上不同
+	skill := CreateSkill("greeter", "Greets", WithBody("<script>greet</script>"),
+		WithScript(CreateScript("greet", func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return "hello", nil
+		})))
+	if err := manager.SaveSkill(ctx, skill); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if reloaded, err := manager.ReloadChanged(ctx); err != nil || len(reloaded) != 0 {
+		t.Errorf("Expected no reloads for unchanged content, got %v (%v)", reloaded, err)
+	}
+
+	// 内容变化时重新加载，注册的内联脚本仍可用
+	updated := CreateSkill("greeter", "Greets politely", WithBody("<script>greet</script>"))
+	if err := st.Put(ctx, updated); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	if reloaded, err := manager.ReloadChanged(ctx); err != nil || len(reloaded) != 1 {
+		t.Fatalf("Expected greeter to be reloaded, got %v (%v)", reloaded, err)
+	}
+	cached, _ := manager.GetSkill(ctx, "greeter")
+	if cached.GetDescription() != "Greets politely" {
+		t.Errorf("Expected updated description, got %s", cached.GetDescription())
+	}
+	if result, err := manager.UseScript(ctx, "greeter", "greet", `{}`); err != nil || result != `"hello"` {
+		t.Errorf("Expected inline script to survive reload, got %q (%v)", result, err)
+	}
+
+	if _, err := manager.ReloadSkill(ctx, "greeter"); err != nil {
+		t.Fatalf("ReloadSkill failed: %v", err)
+	}
+	if _, err := manager.UseScript(ctx, "greeter", "greet", `{}`); err != nil {
+		t.Errorf("Expected inline script to survive ReloadSkill, got %v", err)
+	}
+}
+
+func TestSkillManager_WriteBehind(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	manager := NewSkillManager(memStore, WithWriteBehind(10, time.Hour))
+	defer manager.Close()
+
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "wb_skill", Description: "Write-behind"},
+		Body:     "Body",
+	}
+	if err := manager.SaveSkill(ctx, skill); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+
+	// 缓存立即更新
+	cached, err := manager.GetSkill(ctx, "wb_skill")
+	if err != nil || cached != skill {
+		t.Fatalf("Expected skill to be cached immediately, got %v (%v)", cached, err)
+	}
+
+	// 刷新间隔很长，Store 中尚不存在
+	if exists, _ := memStore.Exists(ctx, "wb_skill"); exists {
+		t.Error("Expected store write to be deferred")
+	}
+
+	if err := manager.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if exists, _ := memStore.Exists(ctx, "wb_skill"); !exists {
+		t.Error("Expected skill in store after Flush")
+	}
+
+	// Close 会写入排队中的 Skill
+	second := &schema.Skill{Metadata: &schema.SkillMetadata{Name: "wb_second"}}
+	if err := manager.SaveSkill(ctx, second); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	manager.Close()
+	if exists, _ := memStore.Exists(ctx, "wb_second"); !exists {
+		t.Error("Expected queued skill to be written on Close")
+	}
+
+	// 关闭后不再接收写入
+	if err := manager.SaveSkill(ctx, skill); !errors.Is(err, ErrWriteBehindClosed) {
+		t.Errorf("Expected ErrWriteBehindClosed, got %v", err)
+	}
+}
+
+// blockingStore Put 会阻塞直到 release 被关闭
+type blockingStore struct {
+	*store.MemoryStore
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Put(ctx context.Context, skill *schema.Skill) error {
+	select {
+	case s.entered <- struct{}{}:
+	default:
+	}
+	<-s.release
+	return s.MemoryStore.Put(ctx, skill)
+}
+
+func TestSkillManager_WriteBehindDeleteAndReload(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	manager := NewSkillManager(memStore, WithWriteBehind(10, time.Hour))
+	defer manager.Close()
+
+	// 删除会丢弃排队中的写入，刷新后不会重新出现在 Store 中
+	if err := manager.SaveSkill(ctx, CreateSkill("queued", "Queued")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if err := manager.DeleteSkill(ctx, "queued"); err != nil {
+		t.Fatalf("DeleteSkill failed: %v", err)
+	}
+	if err := manager.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if exists, _ := memStore.Exists(ctx, "queued"); exists {
+		t.Error("Expected deleted skill to stay out of the store")
+	}
+
+	// 已在 Store 中的 Skill：删除同样丢弃排队中的新版本
+	memStore.Put(ctx, CreateSkill("stored", "v1"))
+	if err := manager.SaveSkill(ctx, CreateSkill("stored", "v2")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if err := manager.DeleteSkill(ctx, "stored"); err != nil {
+		t.Fatalf("DeleteSkill failed: %v", err)
+	}
+	manager.Flush(ctx)
+	if exists, _ := memStore.Exists(ctx, "stored"); exists {
+		t.Error("Expected deleted skill to stay out of the store")
+	}
+
+	// 重命名丢弃旧名称的排队写入
+	memStore.Put(ctx, CreateSkill("old", "v1"))
+	if err := manager.SaveSkill(ctx, CreateSkill("old", "v2")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if err := manager.RenameSkill(ctx, "old", "new"); err != nil {
+		t.Fatalf("RenameSkill failed: %v", err)
+	}
+	manager.Flush(ctx)
+	if exists, _ := memStore.Exists(ctx, "old"); exists {
+		t.Error("Expected renamed skill to stay out of the store under its old name")
+	}
+
+	// 重新加载前先写入排队中的版本，不会用 Store 中的旧版本覆盖缓存
+	memStore.Put(ctx, CreateSkill("reloaded", "v1"))
+	if err := manager.SaveSkill(ctx, CreateSkill("reloaded", "v2")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	skill, err := manager.ReloadSkill(ctx, "reloaded")
+	if err != nil || skill.GetDescription() != "v2" {
+		t.Errorf("Expected reloaded v2, got %v (%v)", skill, err)
+	}
+	if err := manager.SaveSkill(ctx, CreateSkill("reloaded", "v3")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if reloaded, err := manager.ReloadChanged(ctx); err != nil || len(reloaded) != 0 {
+		t.Errorf("Expected nothing to reload after flushing, got %v (%v)", reloaded, err)
+	}
+	if skill, _ := manager.GetSkill(ctx, "reloaded"); skill.GetDescription() != "v3" {
+		t.Errorf("Expected cached v3, got %s", skill.GetDescription())
+	}
+}
+
+func TestSkillManager_WriteBehindNonBlocking(t *testing.T) {
+	ctx := context.Background()
+	bs := &blockingStore{
+		MemoryStore: store.NewMemoryStore(),
+		entered:     make(chan struct{}, 1),
+		release:     make(chan struct{}),
+	}
+	manager := NewSkillManager(bs, WithWriteBehind(1, time.Hour), WithWriteBehindNonBlocking())
+
+	newSkill := func(name string) *schema.Skill {
+		return &schema.Skill{Metadata: &schema.SkillMetadata{Name: name}}
+	}
+
+	if err := manager.SaveSkill(ctx, newSkill("a")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+
+	// 触发刷新，后台协程阻塞在 Store.Put 中
+	flushed := make(chan error, 1)
+	go func() { flushed <- manager.Flush(ctx) }()
+	<-bs.entered
+
+	// 队列容量为 1：第一个入队成功，第二个返回 ErrWriteQueueFull
+	if err := manager.SaveSkill(ctx, newSkill("b")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if err := manager.SaveSkill(ctx, newSkill("c")); !errors.Is(err, ErrWriteQueueFull) {
+		t.Errorf("Expected ErrWriteQueueFull, got %v", err)
+	}
+
+	close(bs.release)
+	if err := <-flushed; err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	manager.Close()
+
+	for _, name := range []string{"a", "b"} {
+		if exists, _ := bs.Exists(ctx, name); !exists {
+			t.Errorf("Expected skill %s in store", name)
+		}
+	}
+}
+
+func TestSkillManager_ReadOnlyStore(t *testing.T) {
+	ctx := context.Background()
+	inner := store.NewMemoryStore()
+	skill := &schema.Skill{Metadata: &schema.SkillMetadata{Name: "readonly_skill"}}
+	if err := inner.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	manager := NewSkillManager(store.NewReadOnlyStore(inner))
+
+	if _, err := manager.GetSkill(ctx, "readonly_skill"); err != nil {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+	if err := manager.SaveSkill(ctx, skill); !errors.Is(err, store.ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from SaveSkill, got %v", err)
+	}
+	if err := manager.DeleteSkill(ctx, "readonly_skill"); !errors.Is(err, store.ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from DeleteSkill, got %v", err)
+	}
+}
+
+func TestSkillManager_SetScriptConcurrency(t *testing.T) {
+	ctx := context.Background()
+	manager := NewSkillManager(nil)
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+	slow := resources.NewEasyScript("expensive", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return map[string]interface{}{}, nil
+	})
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "limited"},
+		Scripts:  []resources.Script{slow},
+	}
+	if err := manager.RegisterSkill(skill); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	manager.SetScriptConcurrency("expensive", 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := manager.UseScript(ctx, "limited", "expensive", `{}`); err != nil {
+				t.Errorf("UseScript failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent executions, got %d", peak)
+	}
+
+	// 等待时 context 取消返回 context 错误
+	manager.SetScriptConcurrency("limited.expensive", 1)
+	release, err := manager.acquireScript(ctx, "limited", "expensive")
+	if err != nil {
+		t.Fatalf("Failed to acquire: %v", err)
+	}
+	defer release()
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := manager.UseScript(cancelCtx, "limited", "expensive", `{}`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded while waiting, got %v", err)
+	}
+}
+
+func TestSkillManager_WithFailureHandler(t *testing.T) {
+	ctx := context.Background()
+
+	type failure struct {
+		skillName, scriptName, args string
+		err                         error
+	}
+	var failures []failure
+	manager := NewSkillManager(nil, WithFailureHandler(func(ctx context.Context, skillName, scriptName, args string, err error) {
+		failures = append(failures, failure{skillName, scriptName, args, err})
+	}))
+
+	scriptErr := errors.New("division by zero")
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "calc"},
+		Scripts: []resources.Script{
+			resources.NewEasyScript("divide", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return nil, scriptErr
+			}),
+			resources.NewEasyScript("ok", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{}, nil
+			}),
+		},
+	}
+	if err := manager.RegisterSkill(skill); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	// 成功时不调用
+	if _, err := manager.UseScript(ctx, "calc", "ok", `{}`); err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if len(failures) != 0 {
+		t.Fatalf("Expected no failures, got %v", failures)
+	}
+
+	// 脚本失败
+	_, err := manager.UseScript(ctx, "calc", "divide", `{"a":1,"b":0}`)
+	if err != scriptErr {
+		t.Errorf("Expected original error to be returned, got %v", err)
+	}
+	if len(failures) != 1 {
+		t.Fatalf("Expected 1 failure, got %d", len(failures))
+	}
+	f := failures[0]
+	if f.skillName != "calc" || f.scriptName != "divide" || f.args != `{"a":1,"b":0}` || f.err != scriptErr {
+		t.Errorf("Unexpected failure details: %+v", f)
+	}
+
+	// Skill 不存在时同样调用
+	if _, err := manager.UseScript(ctx, "missing", "divide", `{}`); err == nil {
+		t.Error("Expected error for missing skill")
+	}
+	if len(failures) != 2 || failures[1].skillName != "missing" {
+		t.Errorf("Expected resolution failure to be reported, got %+v", failures)
+	}
+}
+
+func TestSkillManager_SkillsWithReference(t *testing.T) {
+	ctx := context.Background()
+	manager := NewSkillManager(store.NewMemoryStore())
+
+	// 通过 Body 标记引用
+	if err := manager.SaveSkill(ctx, CreateSkill("beta", "Beta", WithBody("See <reference>guide</reference>"))); err != nil {
+		t.Fatalf("SaveSkill failed: %v", err)
+	}
+	if err := manager.SaveSkill(ctx, CreateSkill("gamma", "Gamma", WithBody("No references"))); err != nil {
+		t.Fatalf("SaveSkill failed: %v", err)
+	}
+	manager.ClearCache()
+	// 仅内联提供，且仅注册在缓存中
+	if err := manager.RegisterSkill(CreateSkill("alpha", "Alpha",
+		WithReference("guide", "# Guide"),
+		WithAsset(CreateAsset("logo", []byte("png"), resources.PNG)),
+	)); err != nil {
+		t.Fatalf("RegisterSkill failed: %v", err)
+	}
+
+	names, err := manager.SkillsWithReference(ctx, "guide")
+	if err != nil {
+		t.Fatalf("SkillsWithReference failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != "alpha" || names[1] != "beta" {
+		t.Errorf("Expected [alpha beta], got %v", names)
+	}
+
+	names, err = manager.SkillsWithAsset(ctx, "logo")
+	if err != nil {
+		t.Fatalf("SkillsWithAsset failed: %v", err)
+	}
+	if len(names) != 1 || names[0] != "alpha" {
+		t.Errorf("Expected [alpha], got %v", names)
+	}
+}
+
+func TestSkillManager_SkillsWithReferenceUncached(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("alpha", "Alpha", WithBody("See <reference>guide</reference>")))
+	memStore.Put(ctx, CreateSkill("broken", "Broken", WithBody("See <reference>guide</reference>")))
+	memStore.Put(ctx, CreateSkill("gamma", "Gamma", WithBody("See <reference>guide</reference>")))
+
+	manager := NewSkillManager(&brokenStore{MemoryStore: memStore, broken: "broken"})
+	names, err := manager.SkillsWithReference(ctx, "guide")
+	if err == nil || !strings.Contains(err.Error(), "disk error") {
+		t.Errorf("Expected per-skill load error, got %v", err)
+	}
+	if len(names) != 2 || names[0] != "alpha" || names[1] != "gamma" {
+		t.Errorf("Expected [alpha gamma] despite broken skill, got %v", names)
+	}
+	if cached := manager.GetCachedSkillNames(); len(cached) != 0 {
+		t.Errorf("Expected query not to populate cache, got %v", cached)
+	}
+}
+
+// slowStore 记录并发 Get 数的慢速 Store
+type slowStore struct {
+	*store.MemoryStore
+	current int32
+	peak    int32
+}
+
+func (s *slowStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	n := atomic.AddInt32(&s.current, 1)
+	defer atomic.AddInt32(&s.current, -1)
+	for {
+		peak := atomic.LoadInt32(&s.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&s.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return s.MemoryStore.Get(ctx, name)
+}
+
+func TestSkillManager_WithMaxConcurrentLoads(t *testing.T) {
+	ctx := context.Background()
+	ss := &slowStore{MemoryStore: store.NewMemoryStore()}
+	const total = 50
+	for i := 0; i < total; i++ {
+		name := fmt.Sprintf("skill_%d", i)
+		if err := ss.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: name}}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	manager := NewSkillManager(ss, WithMaxConcurrentLoads(3))
+
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := manager.GetSkill(ctx, fmt.Sprintf("skill_%d", i)); err != nil {
+				t.Errorf("GetSkill failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if peak := atomic.LoadInt32(&ss.peak); peak > 3 {
+		t.Errorf("Expected at most 3 concurrent loads, got %d", peak)
+	}
+
+	// 缓存命中不占用名额
+	manager.loadSem <- struct{}{}
+	manager.loadSem <- struct{}{}
+	manager.loadSem <- struct{}{}
+	if _, err := manager.GetSkill(ctx, "skill_0"); err != nil {
+		t.Errorf("Expected cache hit without semaphore, got %v", err)
+	}
+
+	// 名额耗尽时等待响应 context 取消
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	manager.ClearCache()
+	if _, err := manager.GetSkill(cancelCtx, "skill_0"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSkillManager_WithEnvExpansion(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	skill := CreateSkill("api", "API skill",
+		WithBody("Call ${API_BASE}/v1 with $TOKEN, costs $$5"),
+		WithReference("endpoints", "Base: ${API_BASE}"),
+	)
+	if err := st.Put(ctx, skill); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	env := map[string]string{"API_BASE": "https://api.example.com"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	manager := NewSkillManager(st, WithEnvExpansion(WithEnvLookup(lookup)))
+	loaded, err := manager.GetSkill(ctx, "api")
+	if err != nil {
+		t.Fatalf("GetSkill failed: %v", err)
+	}
+	if loaded.Body != "Call https://api.example.com/v1 with , costs $5" {
+		t.Errorf("Unexpected body: %s", loaded.Body)
+	}
+	ref, err := loaded.ReadReference("endpoints")
+	if err != nil {
+		t.Fatalf("ReadReference failed: %v", err)
+	}
+	if ref != "Base: https://api.example.com" {
+		t.Errorf("Unexpected reference: %s", ref)
+	}
+
+	// Store 中的原始内容不受影响
+	stored, _ := st.Get(ctx, "api")
+	if stored.References[0].Body != "Base: ${API_BASE}" {
+		t.Errorf("Expected stored reference to be unchanged, got %s", stored.References[0].Body)
+	}
+
+	// 未设置的变量报错
+	strict := NewSkillManager(st, WithEnvExpansion(WithEnvLookup(lookup), WithEnvErrorOnUnset()))
+	if _, err := strict.GetSkill(ctx, "api"); err == nil || !strings.Contains(err.Error(), "TOKEN") {
+		t.Errorf("Expected unset variable error mentioning TOKEN, got %v", err)
+	}
+}
+
+func TestSkillManager_EnvExpansionSaveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	skill := CreateSkill("api", "API skill",
+		WithBody("Call ${API_BASE} with $TOKEN"),
+		WithReference("endpoints", "Base: ${API_BASE}"),
+		WithReference("notes", "plain"),
+	)
+	if err := st.Put(ctx, skill); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	env := map[string]string{"API_BASE": "https://api.example.com", "TOKEN": "secret"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	manager := NewSkillManager(st, WithEnvExpansion(WithEnvLookup(lookup)))
+
+	// 修改元数据后保存，展开后的内容不应写入 Store
+	loaded, err := manager.GetSkill(ctx, "api")
+	if err != nil {
+		t.Fatalf("GetSkill failed: %v", err)
+	}
+	loaded.Metadata.Description = "Updated"
+	loaded.References[1].Body = "edited"
+	if err := manager.SaveSkill(ctx, loaded); err != nil {
+		t.Fatalf("SaveSkill failed: %v", err)
+	}
+
+	stored, err := st.Get(ctx, "api")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Body != "Call ${API_BASE} with $TOKEN" {
+		t.Errorf("Expected stored body to keep placeholders, got %s", stored.Body)
+	}
+	if stored.References[0].Body != "Base: ${API_BASE}" {
+		t.Errorf("Expected stored reference to keep placeholders, got %s", stored.References[0].Body)
+	}
+	if stored.References[1].Body != "edited" {
+		t.Errorf("Expected edited reference to be saved, got %s", stored.References[1].Body)
+	}
+	if stored.Metadata.Description != "Updated" {
+		t.Errorf("Expected updated description, got %s", stored.Metadata.Description)
+	}
+
+	// 缓存中仍为展开后的 Skill
+	cached, err := manager.GetSkill(ctx, "api")
+	if err != nil {
+		t.Fatalf("GetSkill failed: %v", err)
+	}
+	if cached.Body != "Call https://api.example.com with secret" {
+		t.Errorf("Expected cached body to stay expanded, got %s", cached.Body)
+	}
+
+	// 修改过的 Body 按原样写入
+	cached.Body = "Call ${API_BASE} only"
+	if err := manager.SaveSkill(ctx, cached); err != nil {
+		t.Fatalf("SaveSkill failed: %v", err)
+	}
+	stored, _ = st.Get(ctx, "api")
+	if stored.Body != "Call ${API_BASE} only" {
+		t.Errorf("Expected edited body to be saved as given, got %s", stored.Body)
+	}
+
+	// 重新加载后再次展开
+	reloaded, err := manager.ReloadSkill(ctx, "api")
+	if err != nil {
+		t.Fatalf("ReloadSkill failed: %v", err)
+	}
+	if reloaded.Body != "Call https://api.example.com only" {
+		t.Errorf("Expected reloaded body to be expanded, got %s", reloaded.Body)
+	}
+}
+
+func TestEnvExpansion_LeavesShellSpecials(t *testing.T) {
+	e := &envExpansion{lookup: func(key string) (string, bool) {
+		if key == "HOME" {
+			return "/home/u", true
+		}
+		return "", false
+	}}
+
+	cases := map[string]string{
+		"pay $5 now":            "pay $5 now",
+		"price $@ each":         "price $@ each",
+		"args $* $# $1 ${1}":    "args $* $# $1 ${1}",
+		"trailing $":            "trailing $",
+		"unclosed ${HOME":       "unclosed ${HOME",
+		"$HOME/x and ${HOME}_y": "/home/u/x and /home/u_y",
+		"$$HOME":                "$HOME",
+	}
+	for in, want := range cases {
+		got, err := e.expand(in)
+		if err != nil || got != want {
+			t.Errorf("Expected %q for %q, got %q (%v)", want, in, got, err)
+		}
+	}
+}
+
+// memoryAuditSink 在内存中收集审计记录
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (s *memoryAuditSink) Record(entry AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+func TestSkillManager_WithAuditSink(t *testing.T) {
+	sink := &memoryAuditSink{}
+	manager := NewSkillManager(nil, WithAuditSink(sink))
+
+	skill := CreateSkill("calc", "Calculator",
+		WithScript(CreateScript("add", func(ctx context.Context, input map[string]float64) (float64, error) {
+			return input["a"] + input["b"], nil
+		})),
+	)
+	if err := manager.RegisterSkill(skill); err != nil {
+		t.Fatalf("RegisterSkill failed: %v", err)
+	}
+
+	ctx := WithActor(context.Background(), "alice")
+	if _, err := manager.UseScript(ctx, "calc", "add", `{"a":1,"b":2}`); err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if _, err := manager.UseScript(ctx, "calc", "missing", `{"secret":"x"}`); err == nil {
+		t.Fatal("Expected error for missing script")
+	}
+
+	// Close 会写入所有排队的记录
+	manager.Close()
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", len(sink.entries))
+	}
+	ok := sink.entries[0]
+	if ok.Actor != "alice" || ok.SkillName != "calc" || ok.ScriptName != "add" {
+		t.Errorf("Unexpected entry: %+v", ok)
+	}
+	if ok.ArgsHash != hashString(`{"a":1,"b":2}`) || ok.ResultHash != hashString("3") {
+		t.Errorf("Unexpected hashes: %+v", ok)
+	}
+	if ok.Args != "" || ok.Result != "" {
+		t.Error("Expected raw values to be omitted by default")
+	}
+	if ok.Timestamp.IsZero() || ok.Error != "" {
+		t.Errorf("Unexpected entry: %+v", ok)
+	}
+
+	failed := sink.entries[1]
+	if failed.Error == "" || failed.ResultHash != "" {
+		t.Errorf("Expected failed entry with error, got %+v", failed)
+	}
+}
+
+func TestJSONLinesAuditSink(t *testing.T) {
+	var buf strings.Builder
+	manager := NewSkillManager(nil, WithAuditSink(NewJSONLinesAuditSink(&buf), WithAuditRawValues()))
+	skill := CreateSkill("echo", "Echo",
+		WithScript(CreateScript("echo", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return input, nil
+		})),
+	)
+	manager.RegisterSkill(skill)
+	manager.UseScript(context.Background(), "echo", "echo", `{"x":1}`)
+	manager.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d", len(lines))
+	}
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Failed to parse audit line: %v", err)
+	}
+	if entry.Args != `{"x":1}` || entry.Result != `{"x":1}` {
+		t.Errorf("Expected raw values, got %+v", entry)
+	}
+}
+
+// blockingProvider GetScript 阻塞到 release 关闭
+type blockingProvider struct {
+	*resources.InlineProvider
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) GetScript(ctx context.Context, name string) (resources.Script, error) {
+	p.entered <- struct{}{}
+	<-p.release
+	return p.InlineProvider.GetScript(ctx, name)
+}
+
+func TestSkillManager_ReplaceScriptSlowProvider(t *testing.T) {
+	ctx := context.Background()
+	inline := CreateInlineProvider()
+	inline.AddScript(CreateScript("version", func(ctx context.Context, input map[string]interface{}) (string, error) {
+		return "v1", nil
+	}))
+	provider := &blockingProvider{InlineProvider: inline, entered: make(chan struct{}, 1), release: make(chan struct{})}
+
+	manager := NewSkillManager(nil)
+	skill := CreateSkill("deploy", "Deploy", WithExample("version", `{}`, `"v1"`))
+	skill.SetProvider(provider)
+	if err := manager.RegisterSkill(skill); err != nil {
+		t.Fatalf("RegisterSkill failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.ReplaceScript(ctx, "deploy", "version", CreateScript("version", func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return "v2", nil
+		}))
+	}()
+
+	// Provider 解析期间 GetSkill 不被阻塞
+	<-provider.entered
+	if _, err := manager.GetSkill(ctx, "deploy"); err != nil {
+		t.Errorf("GetSkill failed: %v", err)
+	}
+	close(provider.release)
+	if err := <-done; err != nil {
+		t.Fatalf("ReplaceScript failed: %v", err)
+	}
+
+	// 替换后的副本保留原 Skill 的全部内容
+	replaced, _ := manager.GetSkill(ctx, "deploy")
+	if replaced == skill || len(replaced.Examples()) != 1 {
+		t.Errorf("Expected a full copy of the skill, got %+v", replaced.Metadata)
+	}
+	result, err := manager.UseScript(ctx, "deploy", "version", `{}`)
+	if err != nil || result != `"v2"` {
+		t.Errorf("Expected v2, got %s (%v)", result, err)
+	}
+}
+
+func TestSkillManager_ReplaceScript(t *testing.T) {
+	ctx := context.Background()
+	versioned := func(version string) resources.Script {
+		return resources.NewEasyScript("version", func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return version, nil
+		})
+	}
+
+	st := store.NewMemoryStore()
+	if err := st.Put(ctx, CreateSkill("deploy", "Deploy", WithScript(versioned("v1")))); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	manager := NewSkillManager(st)
+
+	// 未缓存时先加载
+	if err := manager.ReplaceScript(ctx, "deploy", "version", versioned("v2")); err != nil {
+		t.Fatalf("ReplaceScript failed: %v", err)
+	}
+	result, err := manager.UseScript(ctx, "deploy", "version", `{}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if result != `"v2"` {
+		t.Errorf("Expected v2, got %s", result)
+	}
+
+	// 旧的 Skill 对象不受影响，缓存中为新的对象
+	before, _ := manager.GetSkill(ctx, "deploy")
+	if err := manager.ReplaceScript(ctx, "deploy", "version", versioned("v3")); err != nil {
+		t.Fatalf("ReplaceScript failed: %v", err)
+	}
+	after, _ := manager.GetSkill(ctx, "deploy")
+	if before == after {
+		t.Error("Expected cached skill to be refreshed")
+	}
+	if result, _ := before.UseScript(ctx, "version", `{}`); result != `"v2"` {
+		t.Errorf("Expected old skill to keep v2, got %s", result)
+	}
+	if result, _ := after.UseScript(ctx, "version", `{}`); result != `"v3"` {
+		t.Errorf("Expected new skill to use v3, got %s", result)
+	}
+
+	// Provider 提供的脚本
+	provider := resources.NewInlineProvider()
+	provider.AddScript(versioned("p1"))
+	manager.SetResourceProvider("deploy", provider)
+	if err := manager.ReplaceScript(ctx, "deploy", "version", versioned("p2")); err != nil {
+		t.Fatalf("ReplaceScript failed: %v", err)
+	}
+	if result, _ := manager.UseScript(ctx, "deploy", "version", `{}`); result != `"p2"` {
+		t.Errorf("Expected provider script to be replaced, got %s", result)
+	}
+
+	// 不存在的脚本和 Skill
+	if err := manager.ReplaceScript(ctx, "deploy", "missing", versioned("x")); err == nil {
+		t.Error("Expected error for missing script")
+	}
+	if err := manager.ReplaceScript(ctx, "missing", "version", versioned("x")); err == nil {
+		t.Error("Expected error for missing skill")
+	}
+}
+
+func TestNewScriptServer(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	manager := NewSkillManager(memStore)
+	manager.RegisterSkill(CreateSkill("calc", "Calculator",
+		WithScript(resources.NewEasyScript("add", func(ctx context.Context, input struct {
+			A int `json:"a"`
+			B int `json:"b"`
+		}) (map[string]interface{}, error) {
+			return map[string]interface{}{"sum": input.A + input.B}, nil
+		})),
+		WithScript(resources.NewEasyScript("fail", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, errors.New("boom")
+		})),
+	))
+
+	server := httptest.NewServer(NewScriptServer(manager))
+	defer server.Close()
+
+	client := resources.NewHTTPRemoteScriptClient(server.URL + "/calc")
+	result, err := resources.NewRemoteScript("add", client).Run(ctx, `{"a":2,"b":3}`)
+	if err != nil {
+		t.Fatalf("Remote call failed: %v", err)
+	}
+	if result != `{"sum":5}` {
+		t.Errorf(`Expected {"sum":5}, got %s`, result)
+	}
+
+	// 执行失败：200 + 响应中的错误
+	_, err = client.Call(ctx, "fail", `{}`)
+	var scriptErr *resources.RemoteScriptError
+	if !errors.As(err, &scriptErr) || !strings.Contains(scriptErr.Message, "boom") {
+		t.Errorf("Expected RemoteScriptError with boom, got %v", err)
+	}
+
+	// 脚本或 Skill 不存在：404
+	var statusErr *resources.RemoteStatusError
+	if _, err := client.Call(ctx, "missing", `{}`); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing script, got %v", err)
+	}
+	other := resources.NewHTTPRemoteScriptClient(server.URL + "/no_such_skill")
+	if _, err := other.Call(ctx, "add", `{}`); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing skill, got %v", err)
+	}
+
+	// 加载 Skill 的其他失败：500
+	broken := httptest.NewServer(NewScriptServer(NewSkillManager(&brokenStore{MemoryStore: memStore, broken: "calc"})))
+	defer broken.Close()
+	brokenClient := resources.NewHTTPRemoteScriptClient(broken.URL + "/calc")
+	if _, err := brokenClient.Call(ctx, "add", `{}`); !errors.As(err, &statusErr) || statusErr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for store failure, got %v", err)
+	}
+
+	// 非 POST 请求：405
+	resp, err := http.Get(server.URL + "/calc/add")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", resp.StatusCode)
+	}
+}
+
+// brokenStore Get 指定名称时失败的存储
+type brokenStore struct {
+	*store.MemoryStore
+	broken string
+}
+
+func (s *brokenStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	if name == s.broken {
+		return nil, errors.New("disk error")
+	}
+	return s.MemoryStore.Get(ctx, name)
+}
+
+func TestSkillManager_ValidateAll(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("valid", "Valid skill",
+		WithBody("See <reference>guide</reference>"),
+		WithReference("guide", "# Guide"),
+	))
+	memStore.Put(ctx, CreateSkill("dangling", "Dangling reference",
+		WithBody("See <reference>missing</reference>"),
+	))
+	memStore.Put(ctx, CreateSkill("unloadable", "Fails to load"))
+
+	manager := NewSkillManager(&brokenStore{MemoryStore: memStore, broken: "unloadable"})
+	report, err := manager.ValidateAll(ctx)
+	if err != nil {
+		t.Fatalf("ValidateAll failed: %v", err)
+	}
+
+	if len(report) != 3 {
+		t.Fatalf("Expected every skill in report, got %v", report)
+	}
+	if issues, ok := report["valid"]; !ok || len(issues) != 0 {
+		t.Errorf("Expected valid skill without issues, got %v", issues)
+	}
+	if issues := report["dangling"]; len(issues) != 1 || !errors.Is(issues[0], schema.ErrDanglingTag) || !strings.Contains(issues[0].Error(), "missing") {
+		t.Errorf("Expected dangling reference issue, got %v", issues)
+	}
+	if issues := report["unloadable"]; len(issues) != 1 || !strings.Contains(issues[0].Error(), "disk error") {
+		t.Errorf("Expected load failure recorded as issue, got %v", issues)
+	}
+	if cached := manager.GetCachedSkillNames(); len(cached) != 0 {
+		t.Errorf("Expected validation not to populate cache, got %v", cached)
+	}
+}
+
+func TestSkillManager_ValidateAllInvalidExample(t *testing.T) {
+	ctx := context.Background()
+	script := resources.NewEasyScript("add", func(ctx context.Context, input map[string]float64) (float64, error) {
+		return input["a"] + input["b"], nil
+	}).WithExample(`{"a":"one"}`)
+
+	manager := NewSkillManager(nil)
+	if err := manager.RegisterSkill(CreateSkill("calc", "Calculator", WithBody("<script>add</script>"), WithScript(script))); err != nil {
+		t.Fatalf("RegisterSkill failed: %v", err)
+	}
+
+	report, err := manager.ValidateAll(ctx)
+	if err != nil {
+		t.Fatalf("ValidateAll failed: %v", err)
+	}
+	if issues := report["calc"]; len(issues) != 1 || !errors.Is(issues[0], schema.ErrInvalidScript) || !strings.Contains(issues[0].Error(), "add") {
+		t.Errorf("Expected invalid example issue, got %v", issues)
+	}
+}
+
+func TestSkillManager_CacheTTL(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("ttl_skill", "v1"))
+
+	manager := NewSkillManager(memStore, WithCacheTTL(time.Minute))
+	now := time.Now()
+	manager.cache.(*lruCache[*schema.Skill]).now = func() time.Time { return now }
+
+	first, _ := manager.GetSkill(ctx, "ttl_skill")
+	memStore.Put(ctx, CreateSkill("ttl_skill", "v2"))
+
+	// 未过期时使用缓存
+	now = now.Add(30 * time.Second)
+	if cached, _ := manager.GetSkill(ctx, "ttl_skill"); cached != first {
+		t.Error("Expected cached skill before TTL")
+	}
+
+	// 过期后重新加载
+	now = now.Add(time.Minute)
+	reloaded, err := manager.GetSkill(ctx, "ttl_skill")
+	if err != nil {
+		t.Fatalf("GetSkill failed: %v", err)
+	}
+	if reloaded.Metadata.Description != "v2" {
+		t.Errorf("Expected reload after TTL, got %s", reloaded.Metadata.Description)
+	}
+
+	stats := manager.CacheStats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Expirations != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestSkillManager_CacheMaxSize(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	for _, name := range []string{"a", "b", "c"} {
+		memStore.Put(ctx, CreateSkill(name, name))
+	}
+
+	manager := NewSkillManager(memStore, WithCacheMaxSize(2))
+	manager.GetSkill(ctx, "a")
+	manager.GetSkill(ctx, "b")
+	manager.GetSkill(ctx, "a") // a 成为最近使用
+	manager.GetSkill(ctx, "c") // 淘汰 b
+
+	names := manager.GetCachedSkillNames()
+	sort.Strings(names)
+	if strings.Join(names, ",") != "a,c" {
+		t.Errorf("Expected a,c cached after LRU eviction, got %v", names)
+	}
+
+	stats := manager.CacheStats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestSkillManager_CacheKeepsRegisteredSkills(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	for _, name := range []string{"a", "b", "c"} {
+		memStore.Put(ctx, CreateSkill(name, name))
+	}
+
+	manager := NewSkillManager(memStore, WithCacheMaxSize(2), WithCacheTTL(time.Minute))
+	now := time.Now()
+	manager.cache.(*lruCache[*schema.Skill]).now = func() time.Time { return now }
+
+	// registered 只存在于缓存中
+	if err := manager.RegisterSkill(CreateSkill("registered", "inline only")); err != nil {
+		t.Fatalf("RegisterSkill failed: %v", err)
+	}
+	manager.GetSkill(ctx, "a")
+	manager.GetSkill(ctx, "b")
+	manager.GetSkill(ctx, "c") // 淘汰 a，而不是最久未使用的 registered
+
+	names := manager.GetCachedSkillNames()
+	sort.Strings(names)
+	if strings.Join(names, ",") != "b,c,registered" {
+		t.Errorf("Expected registered skill to survive LRU eviction, got %v", names)
+	}
+
+	now = now.Add(2 * time.Minute)
+	skill, err := manager.GetSkill(ctx, "registered")
+	if err != nil {
+		t.Fatalf("Expected registered skill to survive TTL, got %v", err)
+	}
+	if skill.Metadata.Description != "inline only" {
+		t.Errorf("Expected registered skill, got %s", skill.Metadata.Description)
+	}
+
+	// ClearCache 后不再保留
+	manager.ClearCache()
+	if _, err := manager.GetSkill(ctx, "registered"); err == nil {
+		t.Error("Expected registered skill to be gone after ClearCache")
+	}
+}
+
+func TestSkillManager_CacheDefaultUnbounded(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	manager := NewSkillManager(memStore)
+	for i := 0; i < 100; i++ {
+		manager.RegisterSkill(CreateSkill(fmt.Sprintf("skill_%d", i), ""))
+	}
+	if len(manager.GetCachedSkillNames()) != 100 {
+		t.Errorf("Expected default cache to keep all skills, got %d", len(manager.GetCachedSkillNames()))
+	}
+	if stats := manager.CacheStats(); stats.Evictions != 0 || stats.Expirations != 0 {
+		t.Errorf("Expected no evictions by default, got %+v", stats)
+	}
+}
+
+// searchCountingStore 记录 Search 调用次数的可查询 Store
+type searchCountingStore struct {
+	*store.MemoryStore
+	searches int
+}
+
+func (s *searchCountingStore) Search(ctx context.Context, query store.SkillQuery) ([]*schema.SkillMetadata, error) {
+	s.searches++
+	metadatas, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Filter(metadatas), nil
+}
+
+func TestSkillManager_SearchSkills(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	manager := NewSkillManager(memStore)
+	for _, s := range []struct{ name, desc, team string }{
+		{"time_skill", "Get the current Time", "infra"},
+		{"timer", "Countdown clocks", "infra"},
+		{"weather", "Weather forecast by time and place", "apps"},
+	} {
+		memStore.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{
+			Name: s.name, Description: s.desc, Labels: map[string]string{"team": s.team},
+		}})
+	}
+
+	names := func(query store.SkillQuery) []string {
+		t.Helper()
+		metadatas, err := manager.SearchSkills(ctx, query)
+		if err != nil {
+			t.Fatalf("SearchSkills failed: %v", err)
+		}
+		var result []string
+		for _, metadata := range metadatas {
+			result = append(result, metadata.Name)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	// 按描述子串查询，默认区分大小写
+	if got := names(store.SkillQuery{DescriptionContains: "time"}); !reflect.DeepEqual(got, []string{"weather"}) {
+		t.Errorf("Expected [weather], got %v", got)
+	}
+	if got := names(store.SkillQuery{DescriptionContains: "TIME", CaseInsensitive: true}); !reflect.DeepEqual(got, []string{"time_skill", "weather"}) {
+		t.Errorf("Expected [time_skill weather] case-insensitively, got %v", got)
+	}
+	// 名称与标签条件同时满足
+	if got := names(store.SkillQuery{NamePrefix: "time", Labels: map[string]string{"team": "infra"}, NameContains: "skill"}); !reflect.DeepEqual(got, []string{"time_skill"}) {
+		t.Errorf("Expected [time_skill], got %v", got)
+	}
+	// 空查询返回全部
+	if got := names(store.SkillQuery{}); len(got) != 3 {
+		t.Errorf("Expected 3 skills for empty query, got %v", got)
+	}
+
+	// 可查询的 Store 由 Store 执行查询
+	searchable := &searchCountingStore{MemoryStore: memStore}
+	manager = NewSkillManager(searchable)
+	if got := names(store.SkillQuery{NameContains: "weather"}); !reflect.DeepEqual(got, []string{"weather"}) || searchable.searches != 1 {
+		t.Errorf("Expected store-side search for [weather], got %v (%d searches)", got, searchable.searches)
+	}
+}
+
+func TestSkillManager_ResultCache(t *testing.T) {
+	ctx := context.Background()
+	manager := NewSkillManager(nil, WithResultCache(time.Minute, 10), WithCacheableScripts("lookup"))
+
+	var lookups, others atomic.Int32
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "cached"},
+		Scripts: []resources.Script{
+			resources.NewEasyScript("lookup", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				lookups.Add(1)
+				return map[string]interface{}{"key": input["key"]}, nil
+			}),
+			resources.NewEasyScript("other", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				others.Add(1)
+				return map[string]interface{}{}, nil
+			}),
+		},
+	}
+	if err := manager.RegisterSkill(skill); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	// 相同参数只执行一次
+	first, err := manager.UseScript(ctx, "cached", "lookup", `{"key":"a"}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	second, err := manager.UseScript(ctx, "cached", "lookup", `{"key":"a"}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if first != second {
+		t.Errorf("Expected cached result %q, got %q", first, second)
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("Expected lookup to run once, got %d", n)
+	}
+
+	// 不同参数重新执行
+	if _, err := manager.UseScript(ctx, "cached", "lookup", `{"key":"b"}`); err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if n := lookups.Load(); n != 2 {
+		t.Errorf("Expected lookup to run twice, got %d", n)
+	}
+
+	// 未列出的脚本每次都执行
+	for i := 0; i < 3; i++ {
+		if _, err := manager.UseScript(ctx, "cached", "other", `{}`); err != nil {
+			t.Fatalf("UseScript failed: %v", err)
+		}
+	}
+	if n := others.Load(); n != 3 {
+		t.Errorf("Expected other to run 3 times, got %d", n)
+	}
+
+	// ClearCache 同时清空结果缓存
+	manager.ClearCache()
+	if err := manager.RegisterSkill(skill); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+	if _, err := manager.UseScript(ctx, "cached", "lookup", `{"key":"a"}`); err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if n := lookups.Load(); n != 3 {
+		t.Errorf("Expected lookup to run again after ClearCache, got %d", n)
+	}
+}
+
+func TestSkillManager_ResultCacheInvalidatedOnSave(t *testing.T) {
+	ctx := context.Background()
+	manager := NewSkillManager(store.NewMemoryStore(), WithResultCache(time.Minute, 10), WithCacheableScripts("version"))
+
+	versioned := func(name, version string) *schema.Skill {
+		return CreateSkill(name, "Versioned", WithScript(CreateScript("version",
+			func(ctx context.Context, input map[string]interface{}) (string, error) {
+				return version, nil
+			})))
+	}
+	if err := manager.SaveSkill(ctx, versioned("a", "v1")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if err := manager.RegisterSkill(versioned("b", "v1")); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if result, _ := manager.UseScript(ctx, name, "version", `{}`); result != `"v1"` {
+			t.Fatalf("Expected v1 for %s, got %s", name, result)
+		}
+	}
+
+	// 重新保存后不再返回旧结果
+	if err := manager.SaveSkill(ctx, versioned("a", "v2")); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	if result, _ := manager.UseScript(ctx, "a", "version", `{}`); result != `"v2"` {
+		t.Errorf("Expected v2 after SaveSkill, got %s", result)
+	}
+
+	// 重新注册同样失效，其他 Skill 的结果不受影响
+	if err := manager.RegisterSkill(versioned("a", "v3")); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+	if result, _ := manager.UseScript(ctx, "a", "version", `{}`); result != `"v3"` {
+		t.Errorf("Expected v3 after RegisterSkill, got %s", result)
+	}
+	manager.SetResourceProvider("b", resources.NewInlineProvider())
+	if result, _ := manager.UseScript(ctx, "b", "version", `{}`); result != `"v1"` {
+		t.Errorf("Expected v1 for b, got %s", result)
+	}
+}
+
+func TestSkillManager_ResultCacheSkipsErrors(t *testing.T) {
+	ctx := context.Background()
+	manager := NewSkillManager(nil, WithResultCache(0, 0), WithCacheableScripts("cached.flaky"))
+
+	var calls atomic.Int32
+	if err := manager.RegisterSkill(&schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "cached"},
+		Scripts: []resources.Script{
+			resources.NewEasyScript("flaky", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				calls.Add(1)
+				return nil, errors.New("unavailable")
+			}),
+		},
+	}); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := manager.UseScript(ctx, "cached", "flaky", `{}`); err == nil {
+			t.Fatal("Expected error from flaky script")
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected errors not to be cached, got %d calls", n)
+	}
+}
+
+// versionedProvider 参考文档内容可修改的 Provider
+type versionedProvider struct {
+	*resources.InlineProvider
+	body string
+}
+
+func (p *versionedProvider) GetReference(ctx context.Context, name string) (string, error) {
+	return p.body, nil
+}
+
+func TestSkillManager_ClearProviderCacheOnReload(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	if err := memStore.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "docs"}}); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	for _, clear := range []bool{false, true} {
+		base := &versionedProvider{InlineProvider: resources.NewInlineProvider(), body: "v1"}
+		opts := []ManagerOption{WithManagerResourceProvider("docs", resources.NewCachingProvider(base))}
+		if clear {
+			opts = append(opts, WithClearProviderCacheOnReload())
+		}
+		manager := NewSkillManager(memStore, opts...)
+
+		if body, err := manager.ReadReference(ctx, "docs", "guide"); err != nil || body != "v1" {
+			t.Fatalf("Expected v1, got %q (%v)", body, err)
+		}
+		base.body = "v2"
+
+		if _, err := manager.ReloadSkill(ctx, "docs"); err != nil {
+			t.Fatalf("Failed to reload skill: %v", err)
+		}
+		expected := "v1" // 默认不清空缓存
+		if clear {
+			expected = "v2"
+		}
+		if body, err := manager.ReadReference(ctx, "docs", "guide"); err != nil || body != expected {
+			t.Errorf("Expected %s after reload (clear=%v), got %q (%v)", expected, clear, body, err)
+		}
+	}
+}
+
+func TestSkillManager_CloseUnsubscribes(t *testing.T) {
+	bus := store.NewInProcessBus(1).WithDropHandler(func(evt store.SkillEvent) {})
+	defer bus.Close()
+	manager := NewSkillManager(store.NewMemoryStore(), WithEventBus(bus))
+	manager.Close()
+
+	// Close 之后发布的事件不会填满已停止的订阅者
+	for i := 0; i < 5; i++ {
+		bus.Publish(store.SkillEvent{Name: "skill", Op: store.SkillOpPut})
+	}
+	if bus.Dropped() != 0 {
+		t.Errorf("Expected no dropped events after Close, got %d", bus.Dropped())
+	}
+}
+
+func TestSkillManager_WithEventBus(t *testing.T) {
+	ctx := context.Background()
+	bus := store.NewInProcessBus(0)
+	defer bus.Close()
+
+	// 两个实例共享同一份数据：writer 通过带事件总线的 Store 写入，manager 订阅事件
+	shared := store.NewMemoryStore(store.WithEventBus(bus))
+	if err := shared.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "shared", Description: "v1"}}); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	manager := NewSkillManager(shared, WithEventBus(bus))
+	defer manager.Close()
+
+	skill, err := manager.GetSkill(ctx, "shared")
+	if err != nil || skill.Metadata.Description != "v1" {
+		t.Fatalf("Expected v1, got %+v (%v)", skill, err)
+	}
+
+	if err := shared.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "shared", Description: "v2"}}); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	// 事件异步处理，等待缓存失效
+	deadline := time.Now().Add(time.Second)
+	for slices.Contains(manager.GetCachedSkillNames(), "shared") {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected cache entry to be invalidated by event")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	skill, err = manager.GetSkill(ctx, "shared")
+	if err != nil || skill.Metadata.Description != "v2" {
+		t.Errorf("Expected v2 after event, got %+v (%v)", skill.Metadata, err)
+	}
+
+	// 本实例 SaveSkill 引起的事件被忽略，缓存中带内联脚本的 Skill 得以保留
+	own := CreateSkill("own", "Own", WithScript(CreateScript("hello", func(ctx context.Context, input map[string]interface{}) (string, error) {
+		return "hi", nil
+	})))
+	if err := manager.SaveSkill(ctx, own); err != nil {
+		t.Fatalf("Failed to save skill: %v", err)
+	}
+	// 事件按顺序处理：外部写入 shared 的失效表明之前 own 的事件已处理完
+	if err := shared.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "shared", Description: "v3"}}); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	deadline = time.Now().Add(time.Second)
+	for slices.Contains(manager.GetCachedSkillNames(), "shared") {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected cache entry to be invalidated by event")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !slices.Contains(manager.GetCachedSkillNames(), "own") {
+		t.Fatal("Expected self-originated event to keep the cache entry")
+	}
+	if result, err := manager.UseScript(ctx, "own", "hello", `{}`); err != nil || result != `"hi"` {
+		t.Errorf("Expected inline script to survive, got %q (%v)", result, err)
+	}
+}
+
+// healthClient 可配置健康状态的远程脚本客户端
+type healthClient struct {
+	err error
+}
+
+func (c *healthClient) Call(ctx context.Context, scriptName string, args string) (string, error) {
+	return "{}", nil
+}
+
+func (c *healthClient) HealthCheck(ctx context.Context) error {
+	return c.err
+}
+
+func TestSkillManager_Ready(t *testing.T) {
+	ctx := context.Background()
+	client := &healthClient{}
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("remote", "Remote", WithBody("<script>calc</script>"),
+		WithScript(CreateRemoteScript("calc", client))))
+	memStore.Put(ctx, CreateSkill("local", "Local"))
+	manager := NewSkillManager(memStore, WithReadinessHealthChecks())
+
+	if err := manager.Ready(ctx); err != nil {
+		t.Errorf("Expected ready without required skills, got %v", err)
+	}
+	if err := manager.Ready(ctx, "remote", "local"); err != nil {
+		t.Errorf("Expected ready, got %v", err)
+	}
+
+	// 失败汇总为一个 error，分别指明缺失的 Skill 和不健康的后端
+	client.err = errors.New("backend down")
+	err := manager.Ready(ctx, "remote", "missing", "local")
+	if err == nil {
+		t.Fatal("Expected error for missing skill")
+	}
+	if !strings.Contains(err.Error(), "required skill missing unavailable") {
+		t.Errorf("Expected error naming missing skill, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "script remote.calc unhealthy: backend down") {
+		t.Errorf("Expected error naming unhealthy script, got %v", err)
+	}
+
+	// 未开启健康检查时不检查后端
+	if err := NewSkillManager(memStore).Ready(ctx, "remote"); err != nil {
+		t.Errorf("Expected ready without health checks, got %v", err)
+	}
+}
+
+func TestSkillManager_ReadyUnresolvedScript(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("dangling", "Dangling", WithBody("<script>ghost</script>")))
+
+	// 开启健康检查时，Body 引用但无法解析的脚本计入失败
+	err := NewSkillManager(memStore, WithReadinessHealthChecks()).Ready(ctx, "dangling")
+	if !errors.Is(err, resources.ErrScriptNotFound) {
+		t.Errorf("Expected ErrScriptNotFound, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "script dangling.ghost unavailable") {
+		t.Errorf("Expected error naming unresolved script, got %v", err)
+	}
+}
+
+func TestSkillManager_RenameSkill(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("old", "v1"))
+	memStore.Put(ctx, CreateSkill("taken", "Taken"))
+	manager := NewSkillManager(memStore)
+
+	skill, err := manager.GetSkill(ctx, "old")
+	if err != nil {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+
+	// 描述更新与并发读取互不干扰
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			skill.SetDescription("v2")
+			_ = skill.GetDescription()
+		}()
+	}
+	wg.Wait()
+	if skill.GetDescription() != "v2" {
+		t.Errorf("Expected description v2, got %s", skill.GetDescription())
+	}
+
+	if err := manager.RenameSkill(ctx, "old", "taken"); err == nil {
+		t.Error("Expected error renaming onto an existing skill")
+	}
+	if err := manager.RenameSkill(ctx, "old", "new"); err != nil {
+		t.Fatalf("RenameSkill failed: %v", err)
+	}
+
+	// Store 键与名称一致，旧名称不再可用
+	stored, err := memStore.Get(ctx, "new")
+	if err != nil || stored.GetName() != "new" {
+		t.Errorf("Expected skill stored under new, got %+v (%v)", stored, err)
+	}
+	if exists, _ := memStore.Exists(ctx, "old"); exists {
+		t.Error("Expected old key to be removed")
+	}
+	if _, err := manager.GetSkill(ctx, "old"); err == nil {
+		t.Error("Expected old name to be unavailable from the manager")
+	}
+	renamed, err := manager.GetSkill(ctx, "new")
+	if err != nil || renamed.GetName() != "new" {
+		t.Errorf("Expected manager to resolve new, got %+v (%v)", renamed, err)
+	}
+	if err := manager.RenameSkill(ctx, "new", ""); err == nil {
+		t.Error("Expected error for empty name")
+	}
+	// 改名写入的是副本，之前取得的 Skill 不受影响
+	if skill.GetName() != "old" {
+		t.Errorf("Expected previously loaded skill to keep its name, got %s", skill.GetName())
+	}
+}
+
+// failingPutStore Put 总是失败
+type failingPutStore struct {
+	store.SkillStore
+}
+
+func (s *failingPutStore) Put(ctx context.Context, skill *schema.Skill) error {
+	return errors.New("disk full")
+}
+
+func TestRenameSkill_FailedPutLeavesStoreIntact(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	memStore.Put(ctx, CreateSkill("old", "Old"))
+
+	if err := RenameSkill(ctx, &failingPutStore{SkillStore: memStore}, "old", "new"); err == nil {
+		t.Fatal("Expected error when Put fails")
+	}
+	stored, err := memStore.Get(ctx, "old")
+	if err != nil || stored.GetName() != "old" {
+		t.Errorf("Expected stored skill to keep its name, got %+v (%v)", stored, err)
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+)
+
+// openAPIVersion 导出文档使用的 OpenAPI 版本
+const openAPIVersion = "3.0.3"
+
+// genericObjectSchema 没有类型信息的脚本使用的请求体 schema
+var genericObjectSchema = json.RawMessage(`{"type":"object"}`)
+
+// ExportOpenAPI 将 Skill 的脚本导出为 OpenAPI 3 文档
+// 每个脚本对应一个 POST /{skill}/{script} 路径（与 NewScriptServer 的路由一致），
+// 请求体和响应的 schema 取自实现了 resources.SchemaProvider 的脚本（EasyScript 由输入输出类型反射生成，
+// RemoteScript 使用远程服务声明的 schema）。没有类型信息的脚本使用通用的对象请求体；
+// 获取 schema 失败的脚本同样使用通用的对象请求体，失败原因记录在 operation 的 x-schema-error 字段中。
+// 列举或获取脚本失败、路径重复时返回错误
+func ExportOpenAPI(skills ...*schema.Skill) (json.RawMessage, error) {
+	ctx := context.Background()
+	paths := make(map[string]interface{})
+	for _, skill := range skills {
+		names, err := scriptNames(ctx, skill)
+		if err != nil {
+			return nil, err
+		}
+		skillName := skill.GetName()
+		for _, name := range names {
+			script, err := skill.GetScript(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			operation := scriptOperation(ctx, skillName, script)
+			path := "/" + url.PathEscape(skillName) + "/" + url.PathEscape(name)
+			if _, exists := paths[path]; exists {
+				return nil, fmt.Errorf("duplicate script path: %s", path)
+			}
+			paths[path] = map[string]interface{}{"post": operation}
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "Skill scripts",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal openapi document: %w", err)
+	}
+	return data, nil
+}
+
+// scriptNames 返回 Skill 的内联脚本与 Provider 脚本名称的并集（已排序）
+func scriptNames(ctx context.Context, skill *schema.Skill) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, script := range skill.Scripts {
+		seen[script.GetName()] = true
+	}
+	if provider := skill.GetProvider(); provider != nil {
+		names, err := provider.ListScripts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list provider scripts of skill %s: %w", skill.GetName(), err)
+		}
+		for _, name := range names {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// scriptOperation 生成脚本对应的 OpenAPI operation
+// 获取 schema 失败时使用通用 schema，并把失败原因记录在 x-schema-error 中
+func scriptOperation(ctx context.Context, skillName string, script resources.Script) map[string]interface{} {
+	input, output := genericObjectSchema, json.RawMessage(`{}`)
+	var schemaErr error
+	if provider, ok := script.(resources.SchemaProvider); ok {
+		scriptSchema, err := provider.Schema(ctx)
+		if err != nil && !errors.Is(err, resources.ErrNoSchema) {
+			schemaErr = err
+		}
+		if err == nil && scriptSchema != nil {
+			if len(scriptSchema.Input) > 0 {
+				input = scriptSchema.Input
+			}
+			if len(scriptSchema.Output) > 0 {
+				output = scriptSchema.Output
+			}
+		}
+	}
+
+	usage := script.GetUsage()
+	summary, _, _ := strings.Cut(usage, "\n")
+	operation := map[string]interface{}{
+		"operationId": skillName + "." + script.GetName(),
+		"summary":     summary,
+		"description": usage,
+		"tags":        []string{skillName},
+		"requestBody": map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": input},
+			},
+		},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "Script result",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": output},
+				},
+			},
+		},
+	}
+	if schemaErr != nil {
+		operation["x-schema-error"] = schemaErr.Error()
+	}
+	return operation
+}
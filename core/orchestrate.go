@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alois132/skill/schema"
+)
+
+// DefaultMaxSteps is the default iteration limit of Orchestrate
+const DefaultMaxSteps = 10
+
+// ErrMaxStepsExceeded is returned when Orchestrate hits its iteration limit before the decision callback is done
+var ErrMaxStepsExceeded = errors.New("orchestrate: max steps exceeded")
+
+// DecideFunc picks the next script to run from the disclosed context
+// Returning done=true stops the loop; a non-empty scriptName returned together with done is run as the final step
+type DecideFunc func(body string) (scriptName, args string, done bool)
+
+// OrchestrateOption configures Orchestrate
+type OrchestrateOption func(*orchestrateConfig)
+
+type orchestrateConfig struct {
+	maxSteps int
+}
+
+// WithMaxSteps limits the number of scripts Orchestrate may run
+func WithMaxSteps(n int) OrchestrateOption {
+	return func(c *orchestrateConfig) {
+		c.maxSteps = n
+	}
+}
+
+// Orchestrate runs the progressive-disclosure loop without an agent framework:
+// the skill body is handed to decide, the chosen script is run, and its result is appended
+// to the body seen by the next decision as a <result script="name"> block.
+// It returns the accumulated result blocks once decide reports done.
+func Orchestrate(ctx context.Context, skill *schema.Skill, decide DecideFunc, opts ...OrchestrateOption) (string, error) {
+	if skill == nil {
+		return "", errors.New("skill is nil")
+	}
+	if decide == nil {
+		return "", errors.New("decide func is nil")
+	}
+
+	cfg := &orchestrateConfig{maxSteps: DefaultMaxSteps}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var results strings.Builder
+	for step := 0; ; step++ {
+		if err := ctx.Err(); err != nil {
+			return results.String(), err
+		}
+
+		scriptName, args, done := decide(skill.Body + results.String())
+		if scriptName == "" {
+			if done {
+				return results.String(), nil
+			}
+			return results.String(), fmt.Errorf("orchestrate: step %d chose no script", step)
+		}
+		if step >= cfg.maxSteps {
+			return results.String(), ErrMaxStepsExceeded
+		}
+
+		result, err := skill.UseScript(ctx, scriptName, args)
+		if err != nil {
+			return results.String(), fmt.Errorf("script %s failed: %w", scriptName, err)
+		}
+		fmt.Fprintf(&results, "\n<result script=\"%s\">%s</result>", scriptName, result)
+
+		if done {
+			return results.String(), nil
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
+)
+
+// WithReadinessHealthChecks 让 Ready 额外检查必需 Skill 的脚本后端
+// 实现了 resources.HealthChecker 的脚本（如 Client 支持健康检查的 RemoteScript）会被调用 HealthCheck
+func WithReadinessHealthChecks() ManagerOption {
+	return func(m *SkillManager) {
+		m.readinessHealthChecks = true
+	}
+}
+
+// Ready 检查管理器是否可以对外服务，适合作为就绪探针
+// 依次检查：Store 可访问（有必需 Skill 时用 Exists，否则用 List）、每个必需 Skill 都能加载（使用缓存），
+// 以及开启 WithReadinessHealthChecks 时必需 Skill 中的脚本都能解析且后端健康。
+// 所有失败汇总为一个 error 返回，全部通过时返回 nil
+func (m *SkillManager) Ready(ctx context.Context, requiredSkills ...string) error {
+	if m.store != nil {
+		var err error
+		if len(requiredSkills) > 0 {
+			_, err = m.store.Exists(ctx, requiredSkills[0])
+		} else {
+			_, err = m.store.List(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("skill store unreachable: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, name := range requiredSkills {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		skill, err := m.GetSkill(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("required skill %s unavailable: %w", name, err))
+			continue
+		}
+		if !m.readinessHealthChecks {
+			continue
+		}
+		for _, scriptName := range util.UniqueStrings(skill.GetScriptNames()) {
+			script, err := skill.GetScript(ctx, scriptName)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("script %s.%s unavailable: %w", name, scriptName, err))
+				continue
+			}
+			if checker, ok := script.(resources.HealthChecker); ok {
+				if err := checker.HealthCheck(ctx); err != nil {
+					errs = append(errs, fmt.Errorf("script %s.%s unhealthy: %w", name, scriptName, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
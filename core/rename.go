@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/store"
+)
+
+// errUnchanged aborts a patch when the skill does not reference the renamed tag
+var errUnchanged = errors.New("skill unchanged")
+
+// RenameTag rewrites <kind>oldName</kind> to <kind>newName</kind> in the skill body,
+// renames the matching inline script/reference/asset and resets the parse cache
+func RenameTag(skill *schema.Skill, kind, oldName, newName string) error {
+	_, err := skill.RenameTag(kind, oldName, newName)
+	return err
+}
+
+// RenameScriptEverywhere renames a script across all skills in the store and returns the names
+// of the skills that were updated (sorted). Stores implementing store.PatchStore are updated atomically.
+func RenameScriptEverywhere(ctx context.Context, st store.SkillStore, oldName, newName string) ([]string, error) {
+	metadatas, err := st.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+
+	rename := func(skill *schema.Skill) error {
+		changed, err := skill.RenameTag("script", oldName, newName)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	}
+
+	updated := make([]string, 0)
+	for _, metadata := range metadatas {
+		name := metadata.Name
+		if ps, ok := st.(store.PatchStore); ok {
+			err = ps.Patch(ctx, name, rename)
+		} else {
+			var skill *schema.Skill
+			skill, err = st.Get(ctx, name)
+			if err == nil {
+				if err = rename(skill); err == nil {
+					err = st.Put(ctx, skill)
+				}
+			}
+		}
+
+		if errors.Is(err, errUnchanged) {
+			continue
+		}
+		if err != nil {
+			return updated, fmt.Errorf("failed to rename script in skill %s: %w", name, err)
+		}
+		updated = append(updated, name)
+	}
+
+	sort.Strings(updated)
+	return updated, nil
+}
+
+// RenameSkill renames a stored skill, moving it from oldName to newName so the name stays consistent with its store key.
+// It fails if newName already exists; if removing the old entry fails the new entry is removed again (best effort)
+func RenameSkill(ctx context.Context, st store.SkillStore, oldName, newName string) error {
+	if newName == "" {
+		return errors.New("skill name must not be empty")
+	}
+	if oldName == newName {
+		return nil
+	}
+	exists, err := st.Exists(ctx, newName)
+	if err != nil {
+		return fmt.Errorf("failed to check skill %s: %w", newName, err)
+	}
+	if exists {
+		return errors.New("skill already exists: " + newName)
+	}
+
+	skill, err := st.Get(ctx, oldName)
+	if err != nil {
+		return fmt.Errorf("failed to get skill: %w", err)
+	}
+	// The store may share Metadata with its stored entry (and with cached copies), so rename a clone
+	renamed := skill.Clone()
+	if renamed.Metadata == nil {
+		renamed.Metadata = &schema.SkillMetadata{}
+	}
+	renamed.Metadata.Name = newName
+	if err := st.Put(ctx, renamed); err != nil {
+		return fmt.Errorf("failed to save skill: %w", err)
+	}
+	if err := st.Delete(ctx, oldName); err != nil {
+		_ = st.Delete(ctx, newName)
+		return fmt.Errorf("failed to delete skill: %w", err)
+	}
+	return nil
+}
+
+// RenameSkill renames a skill in the store via RenameSkill and keeps the manager consistent:
+// cached entries for both names are dropped, a provider set for oldName moves to newName and
+// a write-behind save still queued for oldName is discarded so it cannot bring the old name back
+func (m *SkillManager) RenameSkill(ctx context.Context, oldName, newName string) error {
+	if m.store == nil {
+		return errors.New("skill store not configured")
+	}
+	if _, err := m.discardPendingWrite(ctx, oldName); err != nil {
+		return err
+	}
+	if err := RenameSkill(m.storeContext(ctx), m.store, oldName, newName); err != nil {
+		return err
+	}
+	if m.envExpansion != nil {
+		m.envExpansion.forget(oldName)
+		m.envExpansion.forget(newName)
+	}
+
+	m.mu.Lock()
+	if provider, ok := m.providers[oldName]; ok {
+		delete(m.providers, oldName)
+		m.providers[newName] = provider
+	}
+	m.cache.delete(oldName)
+	m.cache.delete(newName)
+	m.mu.Unlock()
+	m.invalidateSkillResults(oldName)
+	m.invalidateSkillResults(newName)
+
+	return nil
+}
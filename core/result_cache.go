@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// WithResultCache 开启脚本结果缓存：UseScript 对允许缓存的脚本按 Skill、脚本名和参数缓存成功结果
+// ttl <= 0 表示不过期，maxEntries <= 0 表示不限制容量，超出时淘汰最久未使用的结果；执行失败的结果不会被缓存
+// 只有通过 WithCacheableScripts 列出的脚本才会被缓存
+func WithResultCache(ttl time.Duration, maxEntries int) ManagerOption {
+	return func(m *SkillManager) {
+		m.resultCache = newLRUCache[string](ttl, maxEntries)
+	}
+}
+
+// WithCacheableScripts 设置允许缓存结果的脚本，名称可以是 "skill.script"（仅对指定 Skill 生效）或 "script"（对所有 Skill 生效）
+// 仅在同时设置了 WithResultCache 时生效，多次调用会累加
+func WithCacheableScripts(names ...string) ManagerOption {
+	return func(m *SkillManager) {
+		if m.cacheableScripts == nil {
+			m.cacheableScripts = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			m.cacheableScripts[name] = true
+		}
+	}
+}
+
+// isCacheable 判断脚本的结果是否允许缓存
+func (m *SkillManager) isCacheable(skillName, scriptName string) bool {
+	if m.resultCache == nil {
+		return false
+	}
+	return m.cacheableScripts[skillName+"."+scriptName] || m.cacheableScripts[scriptName]
+}
+
+// resultCacheKey 返回脚本结果的缓存键
+func resultCacheKey(skillName, scriptName, args string) string {
+	return skillName + "\x00" + scriptName + "\x00" + args
+}
+
+// cachedUseScript 在允许缓存时先查询结果缓存，未命中则执行脚本并缓存成功结果
+func (m *SkillManager) cachedUseScript(ctx context.Context, skillName, scriptName, args string, run func() (string, error)) (string, error) {
+	if !m.isCacheable(skillName, scriptName) {
+		return run()
+	}
+
+	key := resultCacheKey(skillName, scriptName, args)
+	if result, ok := m.resultCache.get(key); ok {
+		return result, nil
+	}
+
+	result, err := run()
+	if err != nil {
+		return "", err
+	}
+	m.resultCache.set(key, result)
+	return result, nil
+}
+
+// invalidateResults 清空结果缓存
+func (m *SkillManager) invalidateResults() {
+	if m.resultCache != nil {
+		m.resultCache.clear()
+	}
+}
+
+// invalidateSkillResults 移除指定 Skill 的所有缓存结果，在 Skill、其脚本实现或 Provider 变化时调用
+func (m *SkillManager) invalidateSkillResults(skillName string) {
+	if m.resultCache != nil {
+		m.resultCache.deletePrefix(skillName + "\x00")
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/schema/store"
+)
+
+// NewScriptServer 创建一个通过 HTTP 暴露 SkillManager 中脚本的处理器
+// 协议与 resources.HTTPRemoteScriptClient 一致：POST /{skill}/{script}，请求体为 ScriptCallRequest，
+// 响应体为 ScriptCallResponse。客户端的 BaseURL 设置为 <server>/{skill} 即可调用该 Skill 的脚本。
+//
+// 状态码：Skill 或脚本不存在返回 404，加载 Skill 或脚本的其他失败（如 Store 读取错误）返回 500，
+// 请求格式错误返回 400，非 POST 请求返回 405；
+// 脚本执行失败返回 200 并在响应的 error 字段中携带错误（客户端将其作为 *RemoteScriptError 返回）
+func NewScriptServer(manager *SkillManager) http.Handler {
+	return &scriptServer{manager: manager}
+}
+
+// scriptServer NewScriptServer 返回的处理器
+type scriptServer struct {
+	manager *SkillManager
+}
+
+// ServeHTTP 处理脚本调用请求
+func (s *scriptServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeScriptResponse(w, http.StatusMethodNotAllowed, resources.ScriptCallResponse{Error: "method not allowed: " + r.Method})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		writeScriptResponse(w, http.StatusNotFound, resources.ScriptCallResponse{Error: "expected path /{skill}/{script}: " + r.URL.Path})
+		return
+	}
+	skillName, scriptName := parts[0], parts[1]
+
+	var req resources.ScriptCallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeScriptResponse(w, http.StatusBadRequest, resources.ScriptCallResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	if req.Args == "" {
+		req.Args = "{}"
+	}
+
+	ctx := r.Context()
+	skill, err := s.manager.GetSkill(ctx, skillName)
+	if err != nil {
+		writeScriptResponse(w, lookupStatus(err, store.ErrNotFound), resources.ScriptCallResponse{Error: err.Error()})
+		return
+	}
+	if _, err := skill.GetScript(ctx, scriptName); err != nil {
+		writeScriptResponse(w, lookupStatus(err, resources.ErrScriptNotFound), resources.ScriptCallResponse{Error: err.Error()})
+		return
+	}
+
+	// 通过 manager 执行，以便应用审计、失败回调等配置
+	result, err := s.manager.UseScript(ctx, skillName, scriptName, req.Args)
+	if err != nil {
+		writeScriptResponse(w, http.StatusOK, resources.ScriptCallResponse{Error: err.Error()})
+		return
+	}
+	writeScriptResponse(w, http.StatusOK, resources.ScriptCallResponse{Result: result})
+}
+
+// lookupStatus 查找失败时的状态码：err 包装了 notFound 时为 404，否则为 500
+func lookupStatus(err error, notFound error) int {
+	if errors.Is(err, notFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// writeScriptResponse 写入 JSON 格式的响应
+func writeScriptResponse(w http.ResponseWriter, status int, resp resources.ScriptCallResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
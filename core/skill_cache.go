@@ -0,0 +1,245 @@
+package core
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alois132/skill/schema"
+)
+
+// CacheStats SkillManager 缓存的统计信息
+type CacheStats struct {
+	Hits        uint64 // GetSkill 命中缓存的次数
+	Misses      uint64 // GetSkill 未命中缓存的次数（包括已过期）
+	Evictions   uint64 // 因超出最大容量被淘汰的条目数
+	Expirations uint64 // 因 TTL 过期被移除的条目数
+}
+
+// WithCacheTTL 设置缓存条目的存活时间，过期后下次 GetSkill 会从 Store 重新加载；d <= 0 表示不过期
+// 通过 RegisterSkill 注册的 Skill 不会过期
+func WithCacheTTL(d time.Duration) ManagerOption {
+	return func(m *SkillManager) {
+		m.cacheTTL = d
+	}
+}
+
+// WithCacheMaxSize 设置缓存的最大条目数，超出时淘汰最久未使用的 Skill；n <= 0 表示不限制
+// 通过 RegisterSkill 注册的 Skill 不计入容量，也不会被淘汰
+func WithCacheMaxSize(n int) ManagerOption {
+	return func(m *SkillManager) {
+		m.cacheMaxSize = n
+	}
+}
+
+// CacheStats 返回缓存的命中、未命中、淘汰和过期计数
+func (m *SkillManager) CacheStats() CacheStats {
+	return m.cache.stats()
+}
+
+// skillCache SkillManager 使用的缓存，实现需要并发安全
+type skillCache interface {
+	// get 获取 Skill 并计入命中/未命中统计，命中时更新最近使用时间
+	get(name string) (*schema.Skill, bool)
+	// peek 获取 Skill，不影响统计和淘汰顺序
+	peek(name string) (*schema.Skill, bool)
+	// set 写入 Skill；更新已有条目时保留其固定状态
+	set(name string, skill *schema.Skill)
+	// pin 写入一个固定的 Skill，固定条目不会过期或被淘汰，直到被 delete 或 clear 移除
+	pin(name string, skill *schema.Skill)
+	delete(name string)
+	clear()
+	// snapshot 返回所有未过期条目的副本
+	snapshot() map[string]*schema.Skill
+	stats() CacheStats
+}
+
+// cacheEntry 缓存条目
+type cacheEntry[V any] struct {
+	name    string
+	value   V
+	expires time.Time // 零值表示不过期
+	pinned  bool      // 固定条目不会过期或被淘汰
+}
+
+// lruCache 支持可选 TTL 和可选最大容量（LRU 淘汰）的缓存，用于 Skill 缓存和脚本结果缓存
+// ttl 和 maxSize 都为 0 时等价于一个普通的 map
+type lruCache[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	now     func() time.Time
+
+	entries map[string]*list.Element
+	order   *list.List // 最近使用的在前
+	pinned  int        // 固定条目数，不计入 maxSize
+	counts  CacheStats
+}
+
+// newLRUCache 创建缓存，ttl <= 0 表示不过期，maxSize <= 0 表示不限制容量
+func newLRUCache[V any](ttl time.Duration, maxSize int) *lruCache[V] {
+	return &lruCache[V]{
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *lruCache[V]) get(name string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.lookup(name)
+	if !ok {
+		c.counts.Misses++
+		var zero V
+		return zero, false
+	}
+	c.counts.Hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry[V]).value, true
+}
+
+func (c *lruCache[V]) peek(name string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.lookup(name)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return elem.Value.(*cacheEntry[V]).value, true
+}
+
+func (c *lruCache[V]) set(name string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(name, value, false)
+}
+
+func (c *lruCache[V]) pin(name string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(name, value, true)
+}
+
+// store 写入条目并按容量淘汰，调用方需持有锁
+// pin 为 false 时已有条目保留原来的固定状态
+func (c *lruCache[V]) store(name string, value V, pin bool) {
+	if elem, ok := c.entries[name]; ok {
+		entry := elem.Value.(*cacheEntry[V])
+		if pin && !entry.pinned {
+			entry.pinned = true
+			c.pinned++
+		}
+		entry.value = value
+		entry.expires = c.expiry(entry.pinned)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[name] = c.order.PushFront(&cacheEntry[V]{name: name, value: value, expires: c.expiry(pin), pinned: pin})
+	if pin {
+		c.pinned++
+		return
+	}
+	if c.maxSize > 0 {
+		for elem := c.order.Back(); elem != nil && c.order.Len()-c.pinned > c.maxSize; {
+			prev := elem.Prev()
+			if !elem.Value.(*cacheEntry[V]).pinned {
+				c.remove(elem)
+				c.counts.Evictions++
+			}
+			elem = prev
+		}
+	}
+}
+
+// expiry 返回新写入条目的过期时间，固定条目和未设置 TTL 时为零值
+func (c *lruCache[V]) expiry(pinned bool) time.Time {
+	if pinned || c.ttl <= 0 {
+		return time.Time{}
+	}
+	return c.now().Add(c.ttl)
+}
+
+func (c *lruCache[V]) delete(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[name]; ok {
+		c.remove(elem)
+	}
+}
+
+// deletePrefix 移除所有以 prefix 开头的条目
+func (c *lruCache[V]) deletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, elem := range c.entries {
+		if strings.HasPrefix(name, prefix) {
+			c.remove(elem)
+		}
+	}
+}
+
+func (c *lruCache[V]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.pinned = 0
+}
+
+func (c *lruCache[V]) snapshot() map[string]V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]V, len(c.entries))
+	for name := range c.entries {
+		if elem, ok := c.lookup(name); ok {
+			result[name] = elem.Value.(*cacheEntry[V]).value
+		}
+	}
+	return result
+}
+
+func (c *lruCache[V]) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts
+}
+
+// lookup 查找未过期的条目，过期条目会被移除，调用方需持有锁
+func (c *lruCache[V]) lookup(name string) (*list.Element, bool) {
+	elem, ok := c.entries[name]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry[V])
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(elem)
+		c.counts.Expirations++
+		return nil, false
+	}
+	return elem, true
+}
+
+// remove 移除条目，调用方需持有锁
+func (c *lruCache[V]) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry[V])
+	if entry.pinned {
+		c.pinned--
+	}
+	c.order.Remove(elem)
+	delete(c.entries, entry.name)
+}
+
+// Ensure lruCache implements skillCache
+var _ skillCache = (*lruCache[*schema.Skill])(nil)
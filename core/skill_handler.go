@@ -0,0 +1,132 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+)
+
+// SkillHandler 创建一个通过 HTTP 暴露单个 Skill 的处理器，不依赖 SkillManager，适合快速演示
+//
+//	GET  /                  返回 Skill 的 Body，ETag 由 ContentHash 得出，If-None-Match 匹配时返回 304
+//	POST /scripts/{name}    以请求体为参数执行脚本（请求体为空时使用 {}），返回脚本结果
+//	GET  /references/{name} 返回参考文档内容
+//	GET  /assets/{name}     返回资源文件字节，Content-Type 按扩展名设置
+//
+// 状态码：脚本、参考文档或资源文件不存在返回 404，参数不是合法 JSON 或缺少必填输入返回 400，
+// 脚本执行失败返回 500；错误信息以纯文本返回
+func SkillHandler(skill *schema.Skill) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + skill.ContentHash() + `"`
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writeText(w, http.StatusOK, "text/markdown; charset=utf-8", skill.Inspect())
+	})
+	mux.HandleFunc("POST /scripts/{name}", func(w http.ResponseWriter, r *http.Request) {
+		serveSkillScript(w, r, skill)
+	})
+	mux.HandleFunc("GET /references/{name}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := skill.ReadReferenceContext(r.Context(), r.PathValue("name"))
+		if err != nil {
+			writeSkillError(w, err)
+			return
+		}
+		writeText(w, http.StatusOK, "text/markdown; charset=utf-8", body)
+	})
+	mux.HandleFunc("GET /assets/{name}", func(w http.ResponseWriter, r *http.Request) {
+		asset, err := skill.GetAsset(r.Context(), r.PathValue("name"))
+		if err != nil {
+			writeSkillError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", asset.ContentType())
+		w.WriteHeader(http.StatusOK)
+		w.Write(asset.Bytes)
+	})
+	return mux
+}
+
+// serveSkillScript 处理脚本调用请求
+func serveSkillScript(w http.ResponseWriter, r *http.Request, skill *schema.Skill) {
+	ctx := r.Context()
+	name := r.PathValue("name")
+	if _, err := skill.GetScript(ctx, name); err != nil {
+		writeSkillError(w, err)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeText(w, http.StatusBadRequest, "text/plain; charset=utf-8", "failed to read request body: "+err.Error())
+		return
+	}
+	args := strings.TrimSpace(string(data))
+	if args == "" {
+		args = "{}"
+	}
+	if !json.Valid([]byte(args)) {
+		writeText(w, http.StatusBadRequest, "text/plain; charset=utf-8", "invalid args: request body is not valid JSON")
+		return
+	}
+	// 声明了必填输入时才检查，避免拒绝数组等非对象参数
+	if skill.Metadata != nil && len(skill.Metadata.RequiredInputs) > 0 {
+		missing, err := skill.CheckInputs(args)
+		if err != nil {
+			writeText(w, http.StatusBadRequest, "text/plain; charset=utf-8", err.Error())
+			return
+		}
+		if len(missing) > 0 {
+			writeText(w, http.StatusBadRequest, "text/plain; charset=utf-8", "missing required inputs: "+strings.Join(missing, ", "))
+			return
+		}
+	}
+
+	result, err := skill.UseScript(ctx, name, args)
+	if err != nil {
+		writeText(w, http.StatusInternalServerError, "text/plain; charset=utf-8", err.Error())
+		return
+	}
+	writeText(w, http.StatusOK, "application/json", result)
+}
+
+// etagMatches 判断 If-None-Match 头是否匹配 etag
+// 支持 "*" 和逗号分隔的多个值，按弱比较忽略 W/ 前缀
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSkillError 按错误类型写入状态码：不存在的资源返回 404，其他返回 500
+func writeSkillError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, resources.ErrScriptNotFound) ||
+		errors.Is(err, resources.ErrReferenceNotFound) ||
+		errors.Is(err, resources.ErrAssetNotFound) {
+		status = http.StatusNotFound
+	}
+	writeText(w, status, "text/plain; charset=utf-8", err.Error())
+}
+
+// writeText 写入文本响应
+func writeText(w http.ResponseWriter, status int, contentType, body string) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	io.WriteString(w, body)
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/alois132/skill/schema/resources"
+)
+
+// StructScriptsOption configures ScriptsFromStruct
+type StructScriptsOption func(*structScriptsConfig)
+
+type structScriptsConfig struct {
+	strict bool
+}
+
+// WithStrictSignatures makes ScriptsFromStruct return an error for exported methods
+// that don't match func(context.Context, I) (O, error) instead of skipping them
+func WithStrictSignatures() StructScriptsOption {
+	return func(c *structScriptsConfig) {
+		c.strict = true
+	}
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ScriptsFromStruct creates a script for each exported method of v matching
+// func(ctx context.Context, input I) (output O, err error)
+// Script names are the snake_cased method names (GetCurrentTime -> get_current_time)
+// Methods with other signatures are skipped unless WithStrictSignatures is given
+func ScriptsFromStruct(v any, opts ...StructScriptsOption) ([]resources.Script, error) {
+	config := &structScriptsConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if v == nil {
+		return nil, errors.New("struct value cannot be nil")
+	}
+
+	value := reflect.ValueOf(v)
+	typ := value.Type()
+
+	scripts := make([]resources.Script, 0, typ.NumMethod())
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		fn := value.Method(i)
+		if !isScriptSignature(fn.Type()) {
+			if config.strict {
+				return nil, fmt.Errorf("method %s does not match func(context.Context, I) (O, error)", method.Name)
+			}
+			continue
+		}
+		scripts = append(scripts, &methodScript{
+			name: toSnakeCase(method.Name),
+			fn:   fn,
+		})
+	}
+
+	return scripts, nil
+}
+
+// isScriptSignature checks for func(context.Context, I) (O, error)
+func isScriptSignature(t reflect.Type) bool {
+	return t.NumIn() == 2 && t.In(0) == contextType &&
+		t.NumOut() == 2 && t.Out(1) == errorType
+}
+
+// methodScript wraps a bound method as a script using reflection
+type methodScript struct {
+	name string
+	fn   reflect.Value
+}
+
+// Run decodes args into the method's input type, calls it, and encodes the output as JSON
+func (s *methodScript) Run(ctx context.Context, args string) (string, error) {
+	input := newInstance(s.fn.Type().In(1))
+	if err := json.Unmarshal([]byte(args), input.Interface()); err != nil {
+		return "", err
+	}
+
+	out := s.fn.Call([]reflect.Value{reflect.ValueOf(ctx), input.Elem()})
+	if errVal := out[1].Interface(); errVal != nil {
+		return "", errVal.(error)
+	}
+
+	result, err := json.Marshal(out[0].Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// GetName returns the snake_cased method name
+func (s *methodScript) GetName() string {
+	return s.name
+}
+
+// GetUsage describes the input and output types, like EasyScript
+func (s *methodScript) GetUsage() string {
+	t := s.fn.Type()
+	return fmt.Sprintf("Input: %s, Output: %s", t.In(1).String(), t.Out(0).String())
+}
+
+// newInstance returns a pointer to a fresh value of t, initializing maps and nested pointers
+// like util.NewInstance does for generic types
+func newInstance(t reflect.Type) reflect.Value {
+	ptr := reflect.New(t)
+	switch t.Kind() {
+	case reflect.Map:
+		ptr.Elem().Set(reflect.MakeMap(t))
+	case reflect.Ptr:
+		inst := ptr.Elem()
+		for inst.Kind() == reflect.Ptr {
+			inst.Set(reflect.New(inst.Type().Elem()))
+			inst = inst.Elem()
+		}
+	}
+	return ptr
+}
+
+// toSnakeCase converts a Go identifier to snake_case, keeping acronyms together (GetURL -> get_url)
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Ensure methodScript implements Script
+var _ resources.Script = (*methodScript)(nil)
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/store"
+)
+
+// ErrWriteQueueFull 非阻塞模式下写入队列已满
+var ErrWriteQueueFull = errors.New("write-behind queue is full")
+
+// ErrWriteBehindClosed 写后队列已关闭
+var ErrWriteBehindClosed = errors.New("write-behind queue is closed")
+
+// writeBehindConfig 写后模式的配置
+type writeBehindConfig struct {
+	queueSize     int
+	flushInterval time.Duration
+	nonBlocking   bool
+	onError       func(skill *schema.Skill, err error)
+}
+
+// writeBehind 后台异步写入 Store 的队列
+// 同名 Skill 的多次写入会在刷新前合并，只写入最新版本
+type writeBehind struct {
+	ctx    context.Context
+	store  store.SkillStore
+	config writeBehindConfig
+
+	mu        sync.RWMutex // 保护 closed，并保证 Close 之后没有新的入队
+	closed    bool
+	queue     chan *schema.Skill
+	flushCh   chan chan error
+	discardCh chan discardRequest
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// discardRequest 丢弃指定名称的排队写入，reply 返回是否有写入被丢弃
+type discardRequest struct {
+	name  string
+	reply chan bool
+}
+
+// WithWriteBehind 开启写后模式
+// SaveSkill 立即更新缓存，并将 Store 写入放入后台队列，每隔 flushInterval 批量写入
+// 队列已满时默认阻塞（响应 context 取消），可通过 WithWriteBehindNonBlocking 改为返回 ErrWriteQueueFull
+func WithWriteBehind(queueSize int, flushInterval time.Duration) ManagerOption {
+	return func(m *SkillManager) {
+		if m.writeBehindConfig == nil {
+			m.writeBehindConfig = &writeBehindConfig{}
+		}
+		m.writeBehindConfig.queueSize = queueSize
+		m.writeBehindConfig.flushInterval = flushInterval
+	}
+}
+
+// WithWriteBehindNonBlocking 队列已满时 SaveSkill 立即返回 ErrWriteQueueFull 而不是阻塞
+func WithWriteBehindNonBlocking() ManagerOption {
+	return func(m *SkillManager) {
+		if m.writeBehindConfig == nil {
+			m.writeBehindConfig = &writeBehindConfig{}
+		}
+		m.writeBehindConfig.nonBlocking = true
+	}
+}
+
+// WithWriteBehindErrorHandler 设置后台写入失败时的回调
+func WithWriteBehindErrorHandler(fn func(skill *schema.Skill, err error)) ManagerOption {
+	return func(m *SkillManager) {
+		if m.writeBehindConfig == nil {
+			m.writeBehindConfig = &writeBehindConfig{}
+		}
+		m.writeBehindConfig.onError = fn
+	}
+}
+
+// newWriteBehind 创建写后队列，ctx 用于后台写入 Store（携带事件来源等值）
+func newWriteBehind(ctx context.Context, s store.SkillStore, config writeBehindConfig) *writeBehind {
+	if config.queueSize <= 0 {
+		config.queueSize = 1
+	}
+	if config.flushInterval <= 0 {
+		config.flushInterval = time.Second
+	}
+
+	wb := &writeBehind{
+		ctx:       ctx,
+		store:     s,
+		config:    config,
+		queue:     make(chan *schema.Skill, config.queueSize),
+		flushCh:   make(chan chan error),
+		discardCh: make(chan discardRequest),
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go wb.run()
+	return wb
+}
+
+// enqueue 将 Skill 放入写入队列
+func (wb *writeBehind) enqueue(ctx context.Context, skill *schema.Skill) error {
+	wb.mu.RLock()
+	defer wb.mu.RUnlock()
+
+	if wb.closed {
+		return ErrWriteBehindClosed
+	}
+
+	if wb.config.nonBlocking {
+		select {
+		case wb.queue <- skill:
+			return nil
+		default:
+			return ErrWriteQueueFull
+		}
+	}
+
+	select {
+	case wb.queue <- skill:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 后台刷新循环
+func (wb *writeBehind) run() {
+	defer close(wb.done)
+
+	ticker := time.NewTicker(wb.config.flushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[string]*schema.Skill)
+	var order []string
+
+	add := func(skill *schema.Skill) {
+		name := skill.Metadata.Name
+		if _, ok := pending[name]; !ok {
+			order = append(order, name)
+		}
+		pending[name] = skill
+	}
+	drain := func() {
+		for {
+			select {
+			case skill := <-wb.queue:
+				add(skill)
+			default:
+				return
+			}
+		}
+	}
+	discard := func(name string) bool {
+		if _, ok := pending[name]; !ok {
+			return false
+		}
+		delete(pending, name)
+		for i, n := range order {
+			if n == name {
+				order = append(order[:i], order[i+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	write := func() error {
+		var errs []error
+		for _, name := range order {
+			skill := pending[name]
+			if err := wb.store.Put(wb.ctx, skill); err != nil {
+				errs = append(errs, err)
+				if wb.config.onError != nil {
+					wb.config.onError(skill, err)
+				}
+			}
+		}
+		pending = make(map[string]*schema.Skill)
+		order = nil
+		return errors.Join(errs...)
+	}
+
+	for {
+		select {
+		case skill := <-wb.queue:
+			add(skill)
+		case <-ticker.C:
+			write()
+		case reply := <-wb.flushCh:
+			drain()
+			reply <- write()
+		case req := <-wb.discardCh:
+			drain()
+			req.reply <- discard(req.name)
+		case <-wb.stopCh:
+			drain()
+			write()
+			return
+		}
+	}
+}
+
+// flush 立即写入所有排队的 Skill
+func (wb *writeBehind) flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+	select {
+	case wb.flushCh <- reply:
+	case <-wb.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discard 丢弃指定名称尚未写入的排队写入，返回是否有写入被丢弃
+// 持有写锁，保证丢弃期间没有新的入队；已关闭时为空操作
+func (wb *writeBehind) discard(ctx context.Context, name string) (bool, error) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if wb.closed {
+		return false, nil
+	}
+
+	reply := make(chan bool, 1)
+	select {
+	case wb.discardCh <- discardRequest{name: name, reply: reply}:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	return <-reply, nil
+}
+
+// close 停止接收新的写入，写入所有排队的 Skill 后退出
+func (wb *writeBehind) close() {
+	wb.mu.Lock()
+	if wb.closed {
+		wb.mu.Unlock()
+		<-wb.done
+		return
+	}
+	wb.closed = true
+	wb.mu.Unlock()
+
+	close(wb.stopCh)
+	<-wb.done
+}
+
+// Flush 立即将写后队列中的 Skill 写入 Store
+// 返回本次写入中发生的错误；未开启写后模式时为空操作
+func (m *SkillManager) Flush(ctx context.Context) error {
+	if m.writeBehind == nil {
+		return nil
+	}
+	return m.writeBehind.flush(ctx)
+}
+
+// discardPendingWrite 丢弃 name 尚未写入的排队写入，返回是否有写入被丢弃；未开启写后模式时为空操作
+// 删除或重命名 Skill 前调用，避免后台刷新把旧副本重新写回 Store
+func (m *SkillManager) discardPendingWrite(ctx context.Context, name string) (bool, error) {
+	if m.writeBehind == nil {
+		return false, nil
+	}
+	return m.writeBehind.discard(ctx, name)
+}
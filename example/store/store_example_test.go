@@ -282,7 +282,7 @@ Dynamic ref: <reference>dynamic_ref</reference>
 	loaded, _ := memStore.Get(ctx, "hybrid")
 
 	// 重新设置 Provider
-	loaded.Provider = provider
+	loaded.SetProvider(provider)
 
 	// 执行内联脚本
 	result1, _ := loaded.UseScript(ctx, "inline_script", `{}`)
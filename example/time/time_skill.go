@@ -33,11 +33,42 @@ type TimezoneOutput struct {
 	Timezones []string `json:"timezones"`  // 常见时区列表
 }
 
+// Clock 时钟接口，便于测试时注入固定时间
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 使用系统时间的默认时钟
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// timeService 时间脚本的实现，持有时钟和默认时区
+type timeService struct {
+	clock Clock
+	loc   *time.Location // 未指定 timezone 时使用的默认时区
+}
+
+// defaultTimeService 使用系统时间和本地时区
+var defaultTimeService = &timeService{clock: systemClock{}, loc: time.Local}
+
+// getCurrentTime 使用系统时间和本地时区获取当前时间
+func getCurrentTime(ctx context.Context, input TimeInput) (TimeOutput, error) {
+	return defaultTimeService.getCurrentTime(ctx, input)
+}
+
+// getTimezone 使用系统时间和本地时区获取时区信息
+func getTimezone(ctx context.Context, input map[string]interface{}) (TimezoneOutput, error) {
+	return defaultTimeService.getTimezone(ctx, input)
+}
+
 // getCurrentTime 获取当前时间
 // 支持多种格式：iso, local, unix, custom
-func getCurrentTime(ctx context.Context, input TimeInput) (TimeOutput, error) {
+func (s *timeService) getCurrentTime(ctx context.Context, input TimeInput) (TimeOutput, error) {
 	// 确定时区
-	loc := time.Local
+	loc := s.loc
 	if input.Timezone != "" {
 		var err error
 		loc, err = time.LoadLocation(input.Timezone)
@@ -46,7 +77,7 @@ func getCurrentTime(ctx context.Context, input TimeInput) (TimeOutput, error) {
 		}
 	}
 
-	now := time.Now().In(loc)
+	now := s.clock.Now().In(loc)
 	output := TimeOutput{
 		Unix:     now.Unix(),
 		Timezone: loc.String(),
@@ -78,12 +109,12 @@ func getCurrentTime(ctx context.Context, input TimeInput) (TimeOutput, error) {
 }
 
 // getTimezone 获取时区信息
-func getTimezone(ctx context.Context, input map[string]interface{}) (TimezoneOutput, error) {
-	now := time.Now()
+func (s *timeService) getTimezone(ctx context.Context, input map[string]interface{}) (TimezoneOutput, error) {
+	now := s.clock.Now().In(s.loc)
 	_, offset := now.Zone()
 
 	output := TimezoneOutput{
-		Timezone:  time.Local.String(),
+		Timezone:  s.loc.String(),
 		Offset:    offset,
 		LocalTime: now.Format("2006-01-02 15:04:05"),
 		UTCTime:   now.UTC().Format("2006-01-02 15:04:05"),
@@ -106,13 +137,35 @@ func getTimezone(ctx context.Context, input map[string]interface{}) (TimezoneOut
 	return output, nil
 }
 
-// createTimeSkill 创建时间 Skill
+// createTimeSkill 创建使用系统时间和本地时区的时间 Skill
 func createTimeSkill() *schema.Skill {
+	return buildTimeSkill(defaultTimeService)
+}
+
+// newTimeSkill 创建使用指定时钟和默认时区的时间 Skill
+// defaultTimezone 为空时使用本地时区，无效时返回错误
+func newTimeSkill(clock Clock, defaultTimezone string) (*schema.Skill, error) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	loc := time.Local
+	if defaultTimezone != "" {
+		var err error
+		loc, err = time.LoadLocation(defaultTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default timezone: %s", defaultTimezone)
+		}
+	}
+	return buildTimeSkill(&timeService{clock: clock, loc: loc}), nil
+}
+
+// buildTimeSkill 基于 timeService 构建时间 Skill
+func buildTimeSkill(svc *timeService) *schema.Skill {
 	return core.CreateSkill(
 		"time_skill",
 		"Get current time in various formats and timezone information",
-		core.WithScript(core.CreateScript("get_current_time", getCurrentTime)),
-		core.WithScript(core.CreateScript("get_timezone", getTimezone)),
+		core.WithScript(core.CreateScript("get_current_time", svc.getCurrentTime)),
+		core.WithScript(core.CreateScript("get_timezone", svc.getTimezone)),
 		core.WithAutoParsedBody(`
 获取当前时间的 Skill
 
@@ -2,9 +2,19 @@ package time
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/alois132/skill/core"
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/schema/store"
 )
 
 // TestGetCurrentTime_ISOFormat 测试 ISO 格式时间获取
@@ -351,3 +361,684 @@ func BenchmarkGetCurrentTime(b *testing.B) {
 		}
 	}
 }
+
+// TestTimeSkill_AutoExecuteReduce 测试将两个脚本的结果合并为一个对象
+func TestTimeSkill_AutoExecuteReduce(t *testing.T) {
+	ctx := context.Background()
+	skill := createTimeSkill()
+
+	combined, err := skill.AutoExecuteReduce(ctx, `{"format":"iso","timezone":"UTC"}`, core.MergeResults)
+	if err != nil {
+		t.Fatalf("AutoExecuteReduce failed: %v", err)
+	}
+	if len(combined) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %v", len(combined), combined)
+	}
+
+	current, ok := combined["get_current_time"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected get_current_time object, got %T", combined["get_current_time"])
+	}
+	if current["timezone"] != "UTC" {
+		t.Errorf("Expected timezone UTC, got %v", current["timezone"])
+	}
+
+	if _, ok := combined["get_timezone"].(map[string]interface{}); !ok {
+		t.Errorf("Expected get_timezone object, got %T", combined["get_timezone"])
+	}
+}
+
+// TestTimeSkill_CapabilityManifest 测试生成能力清单
+func TestTimeSkill_CapabilityManifest(t *testing.T) {
+	ctx := context.Background()
+	empty := core.CreateSkill("a_empty_skill", "Skill without scripts")
+
+	manifest, err := core.CapabilityManifest(ctx, createTimeSkill(), empty)
+	if err != nil {
+		t.Fatalf("CapabilityManifest failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"## time_skill",
+		"Get current time in various formats and timezone information",
+		"- `get_current_time`: Input: time.TimeInput, Output: time.TimeOutput",
+		"- `get_timezone`: ",
+		"## a_empty_skill\nSkill without scripts\n",
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("Manifest should contain %q, got:\n%s", want, manifest)
+		}
+	}
+
+	// 按名称排序，结果稳定
+	if strings.Index(manifest, "## a_empty_skill") > strings.Index(manifest, "## time_skill") {
+		t.Errorf("Expected skills sorted by name, got:\n%s", manifest)
+	}
+	again, _ := core.CapabilityManifest(ctx, empty, createTimeSkill())
+	if again != manifest {
+		t.Error("Expected deterministic manifest")
+	}
+}
+
+// TestTimeSkill_Orchestrate 测试先披露 body 再执行脚本的编排循环
+func TestTimeSkill_Orchestrate(t *testing.T) {
+	skill := createTimeSkill()
+
+	var bodies []string
+	result, err := core.Orchestrate(context.Background(), skill, func(body string) (string, string, bool) {
+		bodies = append(bodies, body)
+		if strings.Contains(body, `<result script="get_current_time">`) {
+			return "", "", true
+		}
+		return "get_current_time", `{"format":"unix"}`, false
+	})
+	if err != nil {
+		t.Fatalf("Orchestrate failed: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 decisions, got %d", len(bodies))
+	}
+	if bodies[0] != skill.Body {
+		t.Error("Expected first decision to see the skill body")
+	}
+	if !strings.Contains(bodies[1], `"unix":`) {
+		t.Errorf("Expected second decision to see previous result, got %s", bodies[1])
+	}
+	if !strings.Contains(result, `<result script="get_current_time">`) {
+		t.Errorf("Expected accumulated result, got %s", result)
+	}
+}
+
+// TestTimeSkill_OrchestrateMaxSteps 测试循环次数上限
+func TestTimeSkill_OrchestrateMaxSteps(t *testing.T) {
+	skill := createTimeSkill()
+
+	steps := 0
+	_, err := core.Orchestrate(context.Background(), skill, func(body string) (string, string, bool) {
+		steps++
+		return "get_current_time", `{}`, false
+	}, core.WithMaxSteps(3))
+	if !errors.Is(err, core.ErrMaxStepsExceeded) {
+		t.Errorf("Expected ErrMaxStepsExceeded, got %v", err)
+	}
+	if steps != 4 {
+		t.Errorf("Expected 4 decisions (3 runs), got %d", steps)
+	}
+}
+
+// fixedClock 返回固定时间的时钟
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// TestNewTimeSkill_FixedClock 测试注入固定时钟和默认时区
+func TestNewTimeSkill_FixedClock(t *testing.T) {
+	clock := fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	skill, err := newTimeSkill(clock, "Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("newTimeSkill failed: %v", err)
+	}
+
+	result, err := skill.UseScript(context.Background(), "get_current_time", `{"format":"iso"}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	expected := `{"time":"2024-01-02T11:04:05+08:00","unix":1704164645,"timezone":"Asia/Shanghai"}`
+	if result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+
+	// 显式指定的时区优先于默认时区
+	result, err = skill.UseScript(context.Background(), "get_current_time", `{"format":"iso","timezone":"UTC"}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if !strings.Contains(result, `"time":"2024-01-02T03:04:05Z"`) {
+		t.Errorf("Expected UTC time, got %s", result)
+	}
+}
+
+// TestNewTimeSkill_InvalidTimezone 测试无效的默认时区
+func TestNewTimeSkill_InvalidTimezone(t *testing.T) {
+	if _, err := newTimeSkill(nil, "Invalid/Zone"); err == nil {
+		t.Error("Expected error for invalid default timezone")
+	}
+}
+
+// TestTimeSkill_UseScriptAt 测试按 Body 中的顺序执行脚本
+func TestTimeSkill_UseScriptAt(t *testing.T) {
+	skill := createTimeSkill()
+	ctx := context.Background()
+
+	// 第二个脚本是 get_timezone
+	result, err := skill.UseScriptAt(ctx, 1, `{}`)
+	if err != nil {
+		t.Fatalf("UseScriptAt failed: %v", err)
+	}
+	if !strings.Contains(result, `"timezones"`) {
+		t.Errorf("Expected get_timezone result, got %s", result)
+	}
+
+	if _, err := skill.UseScriptAt(ctx, 2, `{}`); err == nil {
+		t.Error("Expected out-of-range error")
+	}
+	if _, err := skill.UseScriptAt(ctx, -1, `{}`); err == nil {
+		t.Error("Expected out-of-range error for negative index")
+	}
+
+	empty := core.CreateSkill("empty", "No scripts", core.WithBody("nothing here"))
+	if _, err := empty.UseScriptAt(ctx, 0, `{}`); err == nil {
+		t.Error("Expected error for body without scripts")
+	}
+}
+
+// recordingScript 记录收到的参数后委托给内部脚本
+type recordingScript struct {
+	resources.Script
+	args []string
+}
+
+func (s *recordingScript) Run(ctx context.Context, args string) (string, error) {
+	s.args = append(s.args, args)
+	return s.Script.Run(ctx, args)
+}
+
+// TestTimeSkill_AutoExecuteDefaults 测试 AutoExecute 使用脚本的默认参数
+func TestTimeSkill_AutoExecuteDefaults(t *testing.T) {
+	skill, err := newTimeSkill(fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, "UTC")
+	if err != nil {
+		t.Fatalf("newTimeSkill failed: %v", err)
+	}
+	recorders := make(map[string]*recordingScript)
+	for i, script := range skill.Scripts {
+		recorder := &recordingScript{Script: script}
+		recorders[script.GetName()] = recorder
+		skill.Scripts[i] = recorder
+	}
+	core.WithAutoExecuteDefaults(map[string]string{"get_timezone": `{}`})(skill)
+
+	args := `{"format":"unix"}`
+	results, err := skill.AutoExecute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("AutoExecute failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if got := recorders["get_timezone"].args; len(got) != 1 || got[0] != `{}` {
+		t.Errorf("Expected get_timezone to receive default {}, got %v", got)
+	}
+	if got := recorders["get_current_time"].args; len(got) != 1 || got[0] != args {
+		t.Errorf("Expected get_current_time to receive caller args, got %v", got)
+	}
+
+	// 默认参数随快照保留
+	snap := skill.Snapshot()
+	skill.Metadata.AutoExecuteDefaults = nil
+	skill.Restore(snap)
+	if skill.Metadata.AutoExecuteDefaults["get_timezone"] != `{}` {
+		t.Errorf("Expected defaults to survive snapshot restore, got %v", skill.Metadata.AutoExecuteDefaults)
+	}
+}
+
+// TestTimeSkill_CloneWith 测试基于时间 Skill 创建变体
+func TestTimeSkill_CloneWith(t *testing.T) {
+	original := createTimeSkill()
+	originalBody := original.Body
+	originalRefs := len(original.References)
+
+	variant := core.CloneWith(original,
+		core.WithName("world_clock"),
+		core.WithDescription("World clock"),
+		core.WithReference("city_list", "# Cities"),
+	)
+
+	if variant.Metadata.Name != "world_clock" || variant.Metadata.Description != "World clock" {
+		t.Errorf("Expected renamed variant, got %+v", variant.Metadata)
+	}
+	if len(variant.References) != originalRefs+1 {
+		t.Errorf("Expected %d references, got %d", originalRefs+1, len(variant.References))
+	}
+	if body, err := variant.ReadReference("city_list"); err != nil || body != "# Cities" {
+		t.Errorf("Expected added reference, got %q (%v)", body, err)
+	}
+	if _, err := variant.UseScript(context.Background(), "get_timezone", `{}`); err != nil {
+		t.Errorf("Expected cloned scripts to work, got %v", err)
+	}
+
+	// 原 Skill 保持不变
+	if original.Metadata.Name != "time_skill" {
+		t.Errorf("Expected original name unchanged, got %s", original.Metadata.Name)
+	}
+	if len(original.References) != originalRefs {
+		t.Errorf("Expected original references unchanged, got %d", len(original.References))
+	}
+	if original.Body != originalBody {
+		t.Error("Expected original body unchanged")
+	}
+	variant.References[0].Body = "changed"
+	if original.References[0].Body == "changed" {
+		t.Error("Expected references to be deep-copied")
+	}
+}
+
+// TestTimeSkill_SkillHandler 测试通过 HTTP 暴露时间 Skill 的各个路由
+func TestTimeSkill_SkillHandler(t *testing.T) {
+	skill, err := newTimeSkill(fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, "UTC")
+	if err != nil {
+		t.Fatalf("newTimeSkill failed: %v", err)
+	}
+	skill.Assets = append(skill.Assets, &resources.Asset{Name: "clock", Bytes: []byte("PNGDATA"), Ext: resources.PNG})
+
+	server := httptest.NewServer(core.SkillHandler(skill))
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+	post := func(path, body string) (*http.Response, string) {
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	// GET / 返回 Body
+	if resp, body := get("/"); resp.StatusCode != http.StatusOK || body != skill.Inspect() {
+		t.Errorf("Expected 200 with skill body, got %d", resp.StatusCode)
+	}
+
+	// POST /scripts/{name} 执行脚本
+	resp, body := post("/scripts/get_current_time", `{"format":"date"}`)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "2024-01-02") {
+		t.Errorf("Expected 200 with date, got %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := post("/scripts/get_current_time", `{not json`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for bad args, got %d", resp.StatusCode)
+	}
+	if resp, _ := post("/scripts/missing", `{}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing script, got %d", resp.StatusCode)
+	}
+
+	// GET /references/{name}
+	if resp, body := get("/references/time_format_guide"); resp.StatusCode != http.StatusOK || !strings.Contains(body, "时间格式指南") {
+		t.Errorf("Expected 200 with reference, got %d", resp.StatusCode)
+	}
+	if resp, _ := get("/references/missing"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing reference, got %d", resp.StatusCode)
+	}
+
+	// GET /assets/{name} 按扩展名设置 Content-Type
+	resp, body = get("/assets/clock")
+	if resp.StatusCode != http.StatusOK || body != "PNGDATA" {
+		t.Errorf("Expected 200 with asset bytes, got %d: %q", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected Content-Type image/png, got %s", ct)
+	}
+	if resp, _ := get("/assets/missing"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing asset, got %d", resp.StatusCode)
+	}
+}
+
+// TestTimeSkill_ExportOpenAPI 测试将时间 Skill 的脚本导出为 OpenAPI 文档
+func TestTimeSkill_ExportOpenAPI(t *testing.T) {
+	skill := createTimeSkill()
+	// 没有 schema 端点的远程脚本使用通用的对象请求体
+	skill.Scripts = append(skill.Scripts, resources.NewRemoteScript("remote_now", resources.NewMockRemoteScriptClient()))
+
+	data, err := core.ExportOpenAPI(skill)
+	if err != nil {
+		t.Fatalf("ExportOpenAPI failed: %v", err)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Post struct {
+				OperationID string `json:"operationId"`
+				RequestBody struct {
+					Content map[string]struct {
+						Schema struct {
+							Type       string                     `json:"type"`
+							Properties map[string]json.RawMessage `json:"properties"`
+						} `json:"schema"`
+					} `json:"content"`
+				} `json:"requestBody"`
+				Responses map[string]json.RawMessage `json:"responses"`
+			} `json:"post"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("Expected OpenAPI 3 document, got %s", doc.OpenAPI)
+	}
+
+	op, ok := doc.Paths["/time_skill/get_current_time"]
+	if !ok {
+		t.Fatalf("Expected path /time_skill/get_current_time, got %v", doc.Paths)
+	}
+	if op.Post.OperationID != "time_skill.get_current_time" {
+		t.Errorf("Expected operationId time_skill.get_current_time, got %s", op.Post.OperationID)
+	}
+	body := op.Post.RequestBody.Content["application/json"].Schema
+	if _, ok := body.Properties["format"]; !ok {
+		t.Errorf("Expected format property, got %v", body.Properties)
+	}
+	if _, ok := op.Post.Responses["200"]; !ok {
+		t.Error("Expected 200 response")
+	}
+
+	remote := doc.Paths["/time_skill/remote_now"].Post.RequestBody.Content["application/json"].Schema
+	if remote.Type != "object" || len(remote.Properties) != 0 {
+		t.Errorf("Expected generic object body for remote script, got %+v", remote)
+	}
+}
+
+// TestTimeSkill_ExportOpenAPISchemaFailure 测试远程 schema 获取失败时仍能导出文档
+func TestTimeSkill_ExportOpenAPISchemaFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := resources.NewHTTPRemoteScriptClient(server.URL)
+	skill := createTimeSkill()
+	skill.Scripts = append(skill.Scripts, resources.NewRemoteScript("remote_now", client).WithSchemaEndpoint(client, "scripts"))
+
+	data, err := core.ExportOpenAPI(skill)
+	if err != nil {
+		t.Fatalf("Expected export to succeed despite schema failure, got %v", err)
+	}
+
+	var doc struct {
+		Paths map[string]struct {
+			Post map[string]json.RawMessage `json:"post"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON document: %v", err)
+	}
+	if _, ok := doc.Paths["/time_skill/get_current_time"]; !ok {
+		t.Errorf("Expected other scripts to be exported, got %v", doc.Paths)
+	}
+	remote := doc.Paths["/time_skill/remote_now"].Post
+	if !strings.Contains(string(remote["requestBody"]), `{"type":"object"}`) {
+		t.Errorf("Expected generic object body, got %s", remote["requestBody"])
+	}
+	if !strings.Contains(string(remote["x-schema-error"]), "404") {
+		t.Errorf("Expected schema failure recorded, got %s", remote["x-schema-error"])
+	}
+}
+
+// TestTimeSkill_SkillHandlerETag 测试 Body 响应的 ETag 和条件请求
+func TestTimeSkill_SkillHandlerETag(t *testing.T) {
+	server := httptest.NewServer(core.SkillHandler(createTimeSkill()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with ETag, got %d (%q)", resp.StatusCode, etag)
+	}
+
+	// 相同内容的 Skill（如重启后）得到相同的 ETag
+	other := httptest.NewServer(core.SkillHandler(createTimeSkill()))
+	defer other.Close()
+	resp, err = http.Get(other.URL + "/")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("ETag") != etag {
+		t.Errorf("Expected stable ETag %s, got %s", etag, resp.Header.Get("ETag"))
+	}
+
+	conditional := func(ifNoneMatch string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Conditional GET failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := conditional(etag); resp.StatusCode != http.StatusNotModified || resp.Header.Get("ETag") != etag {
+		t.Errorf("Expected 304 with ETag, got %d", resp.StatusCode)
+	}
+	if resp := conditional(`"stale", W/` + etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 for weak match in list, got %d", resp.StatusCode)
+	}
+	if resp := conditional(`"stale"`); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for non-matching ETag, got %d", resp.StatusCode)
+	}
+}
+
+// TestTimeSkill_UseScriptTyped 测试通过类型化的输入输出调用脚本
+func TestTimeSkill_UseScriptTyped(t *testing.T) {
+	ctx := context.Background()
+	skill, err := newTimeSkill(fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, "UTC")
+	if err != nil {
+		t.Fatalf("newTimeSkill failed: %v", err)
+	}
+
+	output, err := core.UseScriptTyped[TimeInput, TimeOutput](ctx, skill, "get_current_time", TimeInput{Format: "iso", Timezone: "Asia/Shanghai"})
+	if err != nil {
+		t.Fatalf("UseScriptTyped failed: %v", err)
+	}
+	expected := TimeOutput{Time: "2024-01-02T11:04:05+08:00", Unix: 1704164645, Timezone: "Asia/Shanghai"}
+	if output != expected {
+		t.Errorf("Expected %+v, got %+v", expected, output)
+	}
+
+	// 结果无法解析为 O 时返回带脚本名称的错误
+	_, err = core.UseScriptTyped[TimeInput, []string](ctx, skill, "get_current_time", TimeInput{Format: "iso"})
+	if err == nil || !strings.Contains(err.Error(), "failed to unmarshal result of script get_current_time") {
+		t.Errorf("Expected unmarshal error, got %v", err)
+	}
+
+	// 脚本不存在
+	if _, err := core.UseScriptTyped[TimeInput, TimeOutput](ctx, skill, "missing", TimeInput{}); err == nil {
+		t.Error("Expected error for missing script")
+	}
+}
+
+// TestTimeSkill_RunExamples 测试调用示例的存储与执行
+func TestTimeSkill_RunExamples(t *testing.T) {
+	ctx := context.Background()
+	base, err := newTimeSkill(fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, "UTC")
+	if err != nil {
+		t.Fatalf("newTimeSkill failed: %v", err)
+	}
+	skill := core.CloneWith(base,
+		// 只匹配部分字段
+		core.WithExample("get_current_time", `{"format":"unix"}`, `{"unix":1704164645,"timezone":"UTC"}`),
+		core.WithExample("get_current_time", `{"format":"iso"}`, `{"time":"1999-01-01T00:00:00Z"}`),
+		core.WithExample("missing", `{}`, `{}`),
+	)
+
+	// 示例保存在元数据中，可随 Store 序列化
+	data, err := json.Marshal(skill.Metadata)
+	if err != nil {
+		t.Fatalf("Failed to marshal metadata: %v", err)
+	}
+	var decoded struct {
+		Examples []map[string]string `json:"examples"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Examples) != 3 || decoded.Examples[0]["script"] != "get_current_time" {
+		t.Errorf("Expected examples in serialized metadata, got %s", data)
+	}
+	if len(base.Examples()) != 0 {
+		t.Errorf("Expected original skill to have no examples, got %d", len(base.Examples()))
+	}
+
+	results := skill.RunExamples(ctx)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].Passed || results[0].Err != nil {
+		t.Errorf("Expected subset example to pass, got %+v", results[0])
+	}
+	if results[1].Passed || results[1].Err != nil || !strings.Contains(results[1].Actual, "2024-01-02T03:04:05Z") {
+		t.Errorf("Expected mismatching example to fail without error, got %+v", results[1])
+	}
+	if results[2].Passed || results[2].Err == nil {
+		t.Errorf("Expected missing script example to fail with error, got %+v", results[2])
+	}
+}
+
+// TestTimeSkill_RunExamplesAfterFileStore 测试示例随 FileStore 持久化，加载后仍可执行
+func TestTimeSkill_RunExamplesAfterFileStore(t *testing.T) {
+	ctx := context.Background()
+	base, err := newTimeSkill(fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, "UTC")
+	if err != nil {
+		t.Fatalf("newTimeSkill failed: %v", err)
+	}
+	skill := core.CloneWith(base,
+		core.WithExample("get_current_time", `{"format":"unix"}`, `{"unix":1704164645}`),
+		core.WithExample("get_timezone", `{}`, `{"timezone":"UTC"}`),
+	)
+
+	fileStore, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	// 内联脚本（Go 函数）无法序列化，只保存其余内容，加载后通过 Provider 提供脚本
+	stored := skill.Clone()
+	stored.Scripts = nil
+	if err := fileStore.Put(ctx, stored); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	loaded, err := fileStore.Get(ctx, "time_skill")
+	if err != nil {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+	if len(loaded.Examples()) != 2 {
+		t.Fatalf("Expected 2 examples after round trip, got %d", len(loaded.Examples()))
+	}
+
+	provider := core.CreateInlineProvider()
+	for _, script := range base.Scripts {
+		provider.AddScript(script)
+	}
+	loaded.SetProvider(provider)
+
+	for _, result := range loaded.RunExamples(ctx) {
+		if !result.Passed || result.Err != nil {
+			t.Errorf("Expected example to pass after round trip, got %+v", result)
+		}
+	}
+}
+
+// TestTimeSkill_GlanceRich 测试包含脚本使用说明和参考文档名称的摘要
+func TestTimeSkill_GlanceRich(t *testing.T) {
+	skill := createTimeSkill()
+	out, err := skill.GlanceRich(context.Background())
+	if err != nil {
+		t.Fatalf("GlanceRich failed: %v", err)
+	}
+
+	var glance schema.RichGlance
+	if err := json.Unmarshal([]byte(out), &glance); err != nil {
+		t.Fatalf("Expected JSON output, got %s: %v", out, err)
+	}
+	if glance.Name != "time_skill" || glance.Description == "" {
+		t.Errorf("Unexpected name or description: %+v", glance)
+	}
+	if len(glance.Scripts) != 2 || glance.Scripts[0].Name != "get_current_time" || glance.Scripts[1].Name != "get_timezone" {
+		t.Fatalf("Expected both scripts in body order, got %+v", glance.Scripts)
+	}
+	for _, script := range glance.Scripts {
+		if !strings.HasPrefix(script.Usage, "Input: ") {
+			t.Errorf("Expected usage for %s, got %q", script.Name, script.Usage)
+		}
+	}
+	if len(glance.References) != 1 || glance.References[0] != "time_format_guide" {
+		t.Errorf("Expected time_format_guide reference, got %v", glance.References)
+	}
+	if strings.Contains(out, "使用方法") {
+		t.Error("Expected body to be excluded")
+	}
+
+	// 输出稳定
+	again, _ := skill.GlanceRich(context.Background())
+	if again != out {
+		t.Errorf("Expected deterministic output, got %s and %s", out, again)
+	}
+}
+
+// brokenStore 对指定 Skill 的 Get 返回错误
+type brokenStore struct {
+	store.SkillStore
+	broken string
+}
+
+func (s *brokenStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	if name == s.broken {
+		return nil, errors.New("corrupted skill data")
+	}
+	return s.SkillStore.Get(ctx, name)
+}
+
+func TestTimeSkill_CatalogScripts(t *testing.T) {
+	ctx := context.Background()
+	memStore := store.NewMemoryStore()
+	if err := memStore.Put(ctx, createTimeSkill()); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	manager := core.NewSkillManager(memStore)
+
+	entries, err := manager.CatalogScripts(ctx)
+	if err != nil {
+		t.Fatalf("CatalogScripts failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Script != "get_current_time" || entries[1].Script != "get_timezone" {
+		t.Fatalf("Expected two entries in body order, got %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Skill != "time_skill" {
+			t.Errorf("Expected skill time_skill, got %s", entry.Skill)
+		}
+		if !strings.HasPrefix(entry.Usage, "Input: ") {
+			t.Errorf("Expected usage for %s, got %q", entry.Script, entry.Usage)
+		}
+		if !json.Valid(entry.InputSchema) {
+			t.Errorf("Expected input schema for %s, got %s", entry.Script, entry.InputSchema)
+		}
+	}
+
+	// 单个 Skill 加载失败不影响其余 Skill
+	if err := memStore.Put(ctx, core.CreateSkill("broken", "Broken", core.WithBody("<script>x</script>"))); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	manager = core.NewSkillManager(&brokenStore{SkillStore: memStore, broken: "broken"})
+	entries, err = manager.CatalogScripts(ctx)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected load error for broken skill, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected time skill entries despite failure, got %+v", entries)
+	}
+}
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Example 脚本调用示例：使用 Args 调用 Script 应得到 Expected
+type Example struct {
+	Script   string `json:"script"`
+	Args     string `json:"args"`
+	Expected string `json:"expected"`
+}
+
+// ExampleResult 执行示例的结果
+type ExampleResult struct {
+	Example Example
+	Actual  string // 脚本的实际输出
+	Passed  bool   // 实际输出与 Expected 匹配
+	Err     error  // 脚本执行错误，非 nil 时 Passed 为 false
+}
+
+// Examples 返回 Skill 的调用示例，Metadata 为 nil 时返回 nil
+func (skill *Skill) Examples() []Example {
+	if skill.Metadata == nil {
+		return nil
+	}
+	return skill.Metadata.Examples
+}
+
+// RunExamples 依次执行所有示例并报告实际输出是否与期望匹配，可作为轻量的集成测试
+// Expected 为 JSON 对象时按子集匹配：实际输出中对应字段（可嵌套）的值相同即可，多出的字段被忽略；
+// 否则要求实际输出与 Expected 完全相同
+func (skill *Skill) RunExamples(ctx context.Context) []ExampleResult {
+	examples := skill.Examples()
+	results := make([]ExampleResult, 0, len(examples))
+	for _, example := range examples {
+		r := ExampleResult{Example: example}
+		r.Actual, r.Err = skill.UseScript(ctx, example.Script, example.Args)
+		r.Passed = r.Err == nil && matchExpected(example.Expected, r.Actual)
+		results = append(results, r)
+	}
+	return results
+}
+
+// matchExpected 判断 actual 是否匹配 expected
+func matchExpected(expected, actual string) bool {
+	var want map[string]interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil || want == nil {
+		return expected == actual
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(actual), &got); err != nil {
+		return false
+	}
+	return containsSubset(got, want)
+}
+
+// containsSubset 判断 got 是否包含 want 的所有字段，嵌套对象递归按子集比较
+func containsSubset(got, want map[string]interface{}) bool {
+	for key, wantValue := range want {
+		gotValue, ok := got[key]
+		if !ok {
+			return false
+		}
+		wantObj, wantIsObj := wantValue.(map[string]interface{})
+		gotObj, gotIsObj := gotValue.(map[string]interface{})
+		if wantIsObj && gotIsObj {
+			if !containsSubset(gotObj, wantObj) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			return false
+		}
+	}
+	return true
+}
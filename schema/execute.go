@@ -0,0 +1,295 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
+)
+
+// ScriptResult 单个脚本的执行结果
+type ScriptResult struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Err    error  `json:"-"`
+}
+
+// ScriptExecution 单次脚本调用的结构化结果
+type ScriptExecution struct {
+	Name     string          `json:"name"`
+	Usage    string          `json:"usage,omitempty"`
+	Result   string          `json:"result"`         // 与 UseScript 返回的字符串完全相同
+	JSON     json.RawMessage `json:"json,omitempty"` // Result 为合法 JSON 时的原始消息
+	Duration time.Duration   `json:"duration"`       // 解析和执行脚本的耗时
+	Err      error           `json:"-"`
+}
+
+// UseScriptResult 执行脚本并返回结构化结果，查找顺序与 UseScript 相同
+// 返回的 ScriptExecution 总是非 nil；执行失败时 Err 与返回的 error 相同
+func (skill *Skill) UseScriptResult(ctx context.Context, name string, args string) (*ScriptExecution, error) {
+	exec := &ScriptExecution{Name: name}
+	start := time.Now()
+	defer func() { exec.Duration = time.Since(start) }()
+
+	script, err := skill.resolveScript(ctx, name)
+	if err != nil {
+		exec.Err = err
+		return exec, err
+	}
+	exec.Usage = script.GetUsage()
+
+	exec.Result, exec.Err = skill.runScript(ctx, script, args)
+	if json.Valid([]byte(exec.Result)) {
+		exec.JSON = json.RawMessage(exec.Result)
+	}
+	return exec, exec.Err
+}
+
+// AutoExecute 按 Body 中 <script> 标记的出现顺序依次执行所有脚本
+// 每个脚本都使用相同的 args，标记上带有内联 args 属性（<script args='{...}'>name</script>）时改用内联参数；单个脚本失败不会中断后续脚本，错误记录在对应的 ScriptResult 中，
+// 返回的 error 为所有失败的合并（全部成功时为 nil）。context 取消后，剩余脚本记录为 context 错误
+func (skill *Skill) AutoExecute(ctx context.Context, args string) ([]ScriptResult, error) {
+	return skill.autoExecute(ctx, args, nil)
+}
+
+// AutoExecuteResilient 与 AutoExecute 相同，但按 policy 对每个失败的脚本重试
+// 顺序和逐个脚本的错误记录与 AutoExecute 一致；默认只重试远程服务不可用的错误（见 ResiliencePolicy.Retryable），
+// 本地脚本的错误不会重试。context 取消后停止重试，剩余脚本记录为 context 错误
+func (skill *Skill) AutoExecuteResilient(ctx context.Context, args string, policy ResiliencePolicy) ([]ScriptResult, error) {
+	return skill.autoExecute(ctx, args, &policy)
+}
+
+// autoExecute AutoExecute 和 AutoExecuteResilient 的实现
+func (skill *Skill) autoExecute(ctx context.Context, args string, policy *ResiliencePolicy) ([]ScriptResult, error) {
+	calls := skill.scriptCalls()
+	results := make([]ScriptResult, 0, len(calls))
+	var errs []error
+
+	skill.runScripts(ctx, calls, args, policy, func(r ScriptResult) error {
+		results = append(results, r)
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+		return nil
+	})
+
+	return results, errors.Join(errs...)
+}
+
+// RunAllInlineScripts 按 Scripts 切片顺序执行所有内联脚本，包括 Body 中未引用的脚本
+// 与 AutoExecute 的区别：AutoExecute 由 Body 中的 <script> 标记驱动（可解析到 Provider 中的脚本），
+// 而本方法只执行内联脚本，不经过 Provider，仅存在于 Provider 中的脚本不会执行。
+// 错误处理与 AutoExecute 相同：单个脚本失败不会中断后续脚本，返回所有失败的合并
+func (skill *Skill) RunAllInlineScripts(ctx context.Context, args string) ([]ScriptResult, error) {
+	results := make([]ScriptResult, 0, len(skill.Scripts))
+	shared := resources.NewDecodedArgs(args)
+	var errs []error
+
+	for _, script := range skill.Scripts {
+		name := script.GetName()
+		r := ScriptResult{Name: name}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			r.Err = fmt.Errorf("script %s not executed: %w", name, ctxErr)
+		} else {
+			r.Result, r.Err = skill.runScriptDecoded(ctx, script, args, shared)
+			if r.Err != nil {
+				r.Err = fmt.Errorf("script %s failed: %w", name, r.Err)
+			}
+		}
+
+		results = append(results, r)
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// UseFirstWorking 按顺序尝试执行脚本，返回第一个成功的结果及其脚本名称
+// scriptNames 为空时使用 Body 中 <script> 标记的顺序；遇到成功即停止，不再执行后续脚本。
+// 全部失败时返回所有尝试的错误合并（每个错误都带有脚本名称）；context 取消后不再尝试剩余脚本
+func (skill *Skill) UseFirstWorking(ctx context.Context, args string, scriptNames ...string) (string, string, error) {
+	if len(scriptNames) == 0 {
+		scriptNames = skill.GetScriptNames()
+	}
+	if len(scriptNames) == 0 {
+		return "", "", errors.New("no scripts to try")
+	}
+
+	var errs []error
+	for _, name := range scriptNames {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			errs = append(errs, fmt.Errorf("script %s not executed: %w", name, ctxErr))
+			break
+		}
+		result, err := skill.UseScript(ctx, name, args)
+		if err == nil {
+			return result, name, nil
+		}
+		errs = append(errs, fmt.Errorf("script %s failed: %w", name, err))
+	}
+	return "", "", errors.Join(errs...)
+}
+
+// AutoExecuteReduce 执行 Body 中的所有脚本，并通过 reducer 将结果折叠到累加器中
+// 脚本错误也会传给 reducer（ScriptResult.Err），由 reducer 决定跳过（返回 nil）或中止（返回 error）；
+// 中止时剩余脚本不再执行，返回已累加的结果和 reducer 的错误
+func (skill *Skill) AutoExecuteReduce(ctx context.Context, args string, reducer func(acc map[string]interface{}, r ScriptResult) error) (map[string]interface{}, error) {
+	acc := make(map[string]interface{})
+	err := skill.runScripts(ctx, skill.scriptCalls(), args, nil, func(r ScriptResult) error {
+		return reducer(acc, r)
+	})
+	return acc, err
+}
+
+// scriptCall AutoExecute 中对单个脚本标记的调用
+type scriptCall struct {
+	name       string
+	inlineArgs string // 标记上的 args 属性
+	hasInline  bool
+}
+
+// scriptCalls 按出现顺序返回 Body 中的脚本标记及其内联参数
+func (skill *Skill) scriptCalls() []scriptCall {
+	tags, _ := parseBody(skill.bodyFormat(), skill.Body)
+	var calls []scriptCall
+	for _, tag := range tags {
+		if tag.Kind() != util.TagScript {
+			continue
+		}
+		args, ok := tag.Attrs[util.ArgsAttr]
+		calls = append(calls, scriptCall{name: tag.Content, inlineArgs: args, hasInline: ok})
+	}
+	return calls
+}
+
+// runScripts 依次执行指定的脚本，并将每个结果交给 visit
+// 参数优先级：标记上的内联 args、Metadata.AutoExecuteDefaults 中的默认参数、args；
+// 内联 args 不是合法 JSON 时该脚本不执行，结果记录为参数错误；policy 非 nil 时按策略重试失败的脚本；
+// args 只解析一次（resources.DecodedArgs），实现了 resources.DecodedScript 的脚本共享解析结果，其余脚本走字符串路径。
+// visit 返回错误时停止执行并返回该错误
+func (skill *Skill) runScripts(ctx context.Context, calls []scriptCall, args string, policy *ResiliencePolicy, visit func(r ScriptResult) error) error {
+	shared := resources.NewDecodedArgs(args)
+	for _, call := range calls {
+		name := call.name
+		var r ScriptResult
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			r = ScriptResult{Name: name, Err: fmt.Errorf("script %s not executed: %w", name, ctxErr)}
+		} else {
+			result, err := skill.runCall(ctx, call, args, shared, policy)
+			if err != nil {
+				err = fmt.Errorf("script %s failed: %w", name, err)
+			}
+			r = ScriptResult{Name: name, Result: result, Err: err}
+		}
+
+		if err := visit(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCall 执行单个脚本标记，policy 非 nil 时按策略重试（内联 args 的校验错误不重试）
+func (skill *Skill) runCall(ctx context.Context, call scriptCall, args string, shared *resources.DecodedArgs, policy *ResiliencePolicy) (string, error) {
+	run := func() (string, error) {
+		return skill.useScriptShared(ctx, call.name, args, shared)
+	}
+	if call.hasInline {
+		if err := validateInlineArgs(call.inlineArgs); err != nil {
+			return "", err
+		}
+		run = func() (string, error) {
+			return skill.UseScript(ctx, call.name, call.inlineArgs)
+		}
+	}
+	if policy == nil {
+		return run()
+	}
+	return policy.do(ctx, run)
+}
+
+// useScriptShared 解析并执行脚本，使用共享的 args/shared，脚本有默认参数时改用默认参数
+func (skill *Skill) useScriptShared(ctx context.Context, name string, args string, shared *resources.DecodedArgs) (string, error) {
+	script, err := skill.resolveScript(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if defaults := skill.autoExecuteArgs(name, args); defaults != args {
+		return skill.runScript(ctx, script, defaults)
+	}
+	return skill.runScriptDecoded(ctx, script, args, shared)
+}
+
+// validateInlineArgs 检查标记上的内联 args 是否为合法 JSON
+func validateInlineArgs(args string) error {
+	var v interface{}
+	if err := json.Unmarshal([]byte(args), &v); err != nil {
+		return fmt.Errorf("invalid inline args: %w", err)
+	}
+	return nil
+}
+
+// autoExecuteArgs 返回脚本在 AutoExecute 中使用的参数
+func (skill *Skill) autoExecuteArgs(name string, args string) string {
+	if skill.Metadata != nil {
+		if defaults, ok := skill.Metadata.AutoExecuteDefaults[name]; ok {
+			return defaults
+		}
+	}
+	return args
+}
+
+// AutoExecuteBudget 在总时间预算内执行所有脚本
+// 预算在所有脚本间共享（不会为每个脚本重置）；脚本自身设置的更短超时依然有效，两者取较紧者。
+// 预算耗尽后剩余脚本不再执行，其结果记录为可通过 errors.Is 判断的 context.DeadlineExceeded；
+// 已完成脚本的结果保留
+func (skill *Skill) AutoExecuteBudget(ctx context.Context, args string, budget time.Duration) ([]ScriptResult, error) {
+	budgetCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+	return skill.AutoExecute(budgetCtx, args)
+}
+
+// ExecutionReport Execute 的渲染数据：Skill 名称和 AutoExecute 的结果
+type ExecutionReport struct {
+	SkillName string
+	Results   []ScriptResult
+}
+
+// DefaultExecuteTemplate Execute 使用的默认输出格式
+// 每个脚本一段，序号从 0 开始；失败的脚本输出 Error 行，成功的输出 Result 行
+const DefaultExecuteTemplate = `Skill: {{.SkillName}}
+{{range $i, $r := .Results}}[{{$i}}] Script: {{$r.Name}}
+{{if $r.Err}}Error: {{$r.Err}}{{else}}Result: {{$r.Result}}{{end}}
+{{end}}`
+
+// defaultExecuteTemplate 解析后的 DefaultExecuteTemplate
+var defaultExecuteTemplate = template.Must(template.New("execute").Parse(DefaultExecuteTemplate))
+
+// Execute 通过 AutoExecute 执行所有脚本，并按 DefaultExecuteTemplate 输出可读的结果
+func (skill *Skill) Execute(ctx context.Context, args string) (string, error) {
+	return skill.ExecuteFormat(ctx, args, nil)
+}
+
+// ExecuteFormat 通过 AutoExecute 执行所有脚本，并用 tmpl 渲染 ExecutionReport；tmpl 为 nil 时使用默认格式
+// 模板执行失败时返回包装后的模板错误；否则返回渲染结果和 AutoExecute 的错误（脚本失败的合并）
+func (skill *Skill) ExecuteFormat(ctx context.Context, args string, tmpl *template.Template) (string, error) {
+	if tmpl == nil {
+		tmpl = defaultExecuteTemplate
+	}
+	results, execErr := skill.AutoExecute(ctx, args)
+
+	report := ExecutionReport{SkillName: skill.GetName(), Results: results}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, report); err != nil {
+		return "", fmt.Errorf("failed to render execution report: %w", err)
+	}
+	return sb.String(), execErr
+}
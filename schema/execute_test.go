@@ -0,0 +1,507 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/alois132/skill/schema/resources"
+)
+
+// sleepScript 创建一个等待指定时长的脚本，会响应 context 取消
+func sleepScript(name string, d time.Duration) resources.Script {
+	return resources.NewEasyScript(name, func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		select {
+		case <-time.After(d):
+			return map[string]interface{}{"script": name}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
+
+func TestSkill_AutoExecute(t *testing.T) {
+	ctx := context.Background()
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "auto_skill"},
+		Body:     "<script>first</script> then <script>missing</script> then <script>second</script>",
+		Scripts: []resources.Script{
+			sleepScript("second", 0),
+			sleepScript("first", 0),
+		},
+	}
+
+	results, err := skill.AutoExecute(ctx, `{}`)
+	if err == nil {
+		t.Error("Expected error for missing script")
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].Name != "first" || results[0].Err != nil || results[0].Result != `{"script":"first"}` {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[1].Name != "missing" || results[1].Err == nil {
+		t.Errorf("Expected missing script error, got %+v", results[1])
+	}
+	if results[2].Name != "second" || results[2].Err != nil {
+		t.Errorf("Unexpected second result: %+v", results[2])
+	}
+}
+
+func TestSkill_AutoExecuteBudget(t *testing.T) {
+	ctx := context.Background()
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "budget_skill"},
+		Body:     "<script>step1</script><script>step2</script><script>step3</script>",
+		Scripts: []resources.Script{
+			sleepScript("step1", 20*time.Millisecond),
+			sleepScript("step2", 200*time.Millisecond),
+			sleepScript("step3", 20*time.Millisecond),
+		},
+	}
+
+	results, err := skill.AutoExecuteBudget(ctx, `{}`, 60*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	// 第一个脚本在预算内完成，结果保留
+	if results[0].Err != nil || results[0].Result != `{"script":"step1"}` {
+		t.Errorf("Expected step1 to complete, got %+v", results[0])
+	}
+	// 预算在第二个脚本执行中耗尽，剩余脚本均记录为超时
+	for _, r := range results[1:] {
+		if !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("Expected %s to exceed budget, got %v", r.Name, r.Err)
+		}
+	}
+}
+
+func TestSkill_AutoExecuteReduce(t *testing.T) {
+	ctx := context.Background()
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "reduce_skill"},
+		Body:     "<script>first</script><script>missing</script><script>second</script>",
+		Scripts: []resources.Script{
+			sleepScript("first", 0),
+			sleepScript("second", 0),
+		},
+	}
+
+	// reducer 跳过错误
+	var failed []string
+	acc, err := skill.AutoExecuteReduce(ctx, `{}`, func(acc map[string]interface{}, r ScriptResult) error {
+		if r.Err != nil {
+			failed = append(failed, r.Name)
+			return nil
+		}
+		acc[r.Name] = r.Result
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AutoExecuteReduce failed: %v", err)
+	}
+	if len(acc) != 2 || len(failed) != 1 || failed[0] != "missing" {
+		t.Errorf("Unexpected reduce result: %v, failed: %v", acc, failed)
+	}
+
+	// reducer 在错误时中止，后续脚本不再执行
+	acc, err = skill.AutoExecuteReduce(ctx, `{}`, func(acc map[string]interface{}, r ScriptResult) error {
+		if r.Err != nil {
+			return r.Err
+		}
+		acc[r.Name] = r.Result
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected reducer error to abort")
+	}
+	if _, ok := acc["second"]; ok || len(acc) != 1 {
+		t.Errorf("Expected only first result before abort, got %v", acc)
+	}
+}
+
+func TestSkill_RunAllInlineScripts(t *testing.T) {
+	ctx := context.Background()
+	echo := func(name string) resources.Script {
+		return resources.NewEasyScript(name, func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return name, nil
+		})
+	}
+
+	provider := resources.NewInlineProvider()
+	provider.AddScript(echo("provider_only"))
+
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "inline"},
+		Body:     "Run <script>referenced</script> then <script>provider_only</script>",
+		Scripts:  []resources.Script{echo("unreferenced"), echo("referenced")},
+	}
+	skill.SetProvider(provider)
+
+	results, err := skill.RunAllInlineScripts(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("RunAllInlineScripts failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "unreferenced" || results[1].Name != "referenced" {
+		t.Errorf("Expected inline scripts in slice order, got %+v", results)
+	}
+
+	// AutoExecute 只执行 Body 中引用的脚本
+	results, err = skill.AutoExecute(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("AutoExecute failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Name == "unreferenced" {
+			t.Error("Expected AutoExecute to skip scripts not referenced in the body")
+		}
+	}
+	if len(results) != 2 || results[1].Name != "provider_only" {
+		t.Errorf("Expected AutoExecute to run body scripts, got %+v", results)
+	}
+}
+
+func TestSkill_UseScriptResult(t *testing.T) {
+	ctx := context.Background()
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "result_skill"},
+		Body:     "<script>slow</script>",
+		Scripts:  []resources.Script{sleepScript("slow", 10*time.Millisecond)},
+	}
+
+	exec, err := skill.UseScriptResult(ctx, "slow", `{}`)
+	if err != nil {
+		t.Fatalf("UseScriptResult failed: %v", err)
+	}
+	expected, _ := skill.UseScript(ctx, "slow", `{}`)
+	if exec.Result != expected {
+		t.Errorf("Expected result %s, got %s", expected, exec.Result)
+	}
+	if string(exec.JSON) != expected {
+		t.Errorf("Expected raw JSON %s, got %s", expected, exec.JSON)
+	}
+	if exec.Name != "slow" {
+		t.Errorf("Expected name slow, got %s", exec.Name)
+	}
+	if exec.Usage == "" {
+		t.Error("Expected usage to be populated")
+	}
+	if exec.Duration < 10*time.Millisecond {
+		t.Errorf("Expected duration >= 10ms, got %v", exec.Duration)
+	}
+	if exec.Err != nil {
+		t.Errorf("Expected no error, got %v", exec.Err)
+	}
+
+	exec, err = skill.UseScriptResult(ctx, "missing", `{}`)
+	if err == nil || exec.Err != err {
+		t.Errorf("Expected error recorded in execution, got %v / %v", err, exec.Err)
+	}
+}
+
+func TestSkill_ExecuteFormat(t *testing.T) {
+	ctx := context.Background()
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "report_skill"},
+		Body:     "<script>first</script> <script>missing</script>",
+		Scripts:  []resources.Script{sleepScript("first", 0)},
+	}
+
+	// 默认格式
+	output, err := skill.Execute(ctx, `{}`)
+	if err == nil {
+		t.Error("Expected error for missing script")
+	}
+	expected := "Skill: report_skill\n" +
+		"[0] Script: first\nResult: {\"script\":\"first\"}\n" +
+		"[1] Script: missing\nError: script missing failed: script not found: missing\n"
+	if output != expected {
+		t.Errorf("Expected default output %q, got %q", expected, output)
+	}
+	if defaulted, _ := skill.ExecuteFormat(ctx, `{}`, nil); defaulted != output {
+		t.Errorf("Expected nil template to match Execute, got %q", defaulted)
+	}
+
+	// 自定义模板：CSV
+	csv := template.Must(template.New("csv").Parse("script,result\n{{range .Results}}{{.Name}},{{if .Err}}ERROR{{else}}{{.Result}}{{end}}\n{{end}}"))
+	output, _ = skill.ExecuteFormat(ctx, `{}`, csv)
+	if output != "script,result\nfirst,{\"script\":\"first\"}\nmissing,ERROR\n" {
+		t.Errorf("Unexpected CSV output: %q", output)
+	}
+
+	// 模板执行错误
+	broken := template.Must(template.New("broken").Parse("{{.NoSuchField}}"))
+	if _, err := skill.ExecuteFormat(ctx, `{}`, broken); err == nil || !strings.Contains(err.Error(), "failed to render execution report") {
+		t.Errorf("Expected template error, got %v", err)
+	}
+}
+
+func TestSkill_UseFirstWorking(t *testing.T) {
+	ctx := context.Background()
+	var backupRuns int
+	failing := resources.NewEasyScript("primary", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errors.New("primary down")
+	})
+	backup := resources.NewEasyScript("backup", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		backupRuns++
+		return map[string]interface{}{"from": "backup"}, nil
+	})
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "fallback_skill"},
+		Body:     "<script>primary</script> or <script>backup</script> or <script>last</script>",
+		Scripts:  []resources.Script{failing, backup, sleepScript("last", 0)},
+	}
+
+	// 按 Body 顺序，第一个失败、第二个成功后停止
+	result, name, err := skill.UseFirstWorking(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("UseFirstWorking failed: %v", err)
+	}
+	if name != "backup" || result != `{"from":"backup"}` {
+		t.Errorf("Expected backup result, got %s from %s", result, name)
+	}
+	if backupRuns != 1 {
+		t.Errorf("Expected backup to run once, got %d", backupRuns)
+	}
+
+	// 显式顺序
+	if _, name, _ := skill.UseFirstWorking(ctx, `{}`, "last", "backup"); name != "last" {
+		t.Errorf("Expected last to win, got %s", name)
+	}
+
+	// 全部失败时合并错误，包含每次尝试
+	_, _, err = skill.UseFirstWorking(ctx, `{}`, "primary", "missing")
+	if err == nil || !strings.Contains(err.Error(), "script primary failed") || !strings.Contains(err.Error(), "script missing failed") {
+		t.Errorf("Expected joined errors naming each attempt, got %v", err)
+	}
+}
+
+// stringOnlyScript 隐藏 resources.DecodedScript，只暴露字符串路径
+type stringOnlyScript struct {
+	resources.Script
+}
+
+func TestSkill_AutoExecuteSharedArgs(t *testing.T) {
+	ctx := context.Background()
+	mutate := func(name string) resources.Script {
+		return resources.NewEasyScript(name, func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			// 每个脚本都应该看到未被修改的独立输入
+			if input["touched"] != nil {
+				return nil, errors.New("input shared between scripts")
+			}
+			input["touched"] = name
+			return input, nil
+		})
+	}
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "shared_skill"},
+		Body:     "<script>a</script><script>b</script><script>c</script>",
+		Scripts:  []resources.Script{mutate("a"), stringOnlyScript{mutate("b")}, mutate("c")},
+	}
+
+	results, err := skill.AutoExecute(ctx, `{"n":1}`)
+	if err != nil {
+		t.Fatalf("AutoExecute failed: %v", err)
+	}
+	for _, r := range results {
+		want := `{"n":1,"touched":"` + r.Name + `"}`
+		if r.Result != want {
+			t.Errorf("Expected %s, got %s", want, r.Result)
+		}
+	}
+
+	// 非法 JSON 在两条路径上都返回错误
+	results, err = skill.AutoExecute(ctx, `{`)
+	if err == nil {
+		t.Fatal("Expected error for invalid args")
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			t.Errorf("Expected error for script %s", r.Name)
+		}
+	}
+}
+
+func benchmarkAutoExecute(b *testing.B, decoded bool) {
+	type input struct {
+		Items []string `json:"items"`
+	}
+	names := []string{"s0", "s1", "s2", "s3", "s4", "s5", "s6", "s7"}
+	skill := &Skill{Metadata: &SkillMetadata{Name: "bench"}}
+	for _, name := range names {
+		var script resources.Script = resources.NewEasyScript(name, func(ctx context.Context, in map[string]interface{}) (int, error) {
+			items, _ := in["items"].([]interface{})
+			return len(items), nil
+		})
+		if !decoded {
+			script = stringOnlyScript{script}
+		}
+		skill.Scripts = append(skill.Scripts, script)
+		skill.Body += "<script>" + name + "</script>"
+	}
+	items := make([]string, 512)
+	for i := range items {
+		items[i] = "item"
+	}
+	data, _ := json.Marshal(input{Items: items})
+	args := string(data)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := skill.AutoExecute(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSkill_AutoExecute(b *testing.B) {
+	benchmarkAutoExecute(b, false)
+}
+
+func BenchmarkSkill_AutoExecuteDecoded(b *testing.B) {
+	benchmarkAutoExecute(b, true)
+}
+
+func TestSkill_AutoExecuteInlineArgs(t *testing.T) {
+	ctx := context.Background()
+	echo := func(name string) resources.Script {
+		return resources.NewEasyScript(name, func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return input, nil
+		})
+	}
+
+	for _, format := range []string{BodyFormatV1, BodyFormatV2} {
+		skill := &Skill{
+			Metadata: &SkillMetadata{Name: "inline_skill", BodyFormat: format},
+			Body:     `<script args='{"format":"iso"}'>first</script> <script args='{"format":"unix"}'>second</script> <script>third</script>`,
+			Scripts:  []resources.Script{echo("first"), echo("second"), echo("third")},
+		}
+
+		results, err := skill.AutoExecute(ctx, `{"format":"call"}`)
+		if err != nil {
+			t.Fatalf("AutoExecute failed (%s): %v", format, err)
+		}
+		expected := []string{`{"format":"iso"}`, `{"format":"unix"}`, `{"format":"call"}`}
+		if len(results) != len(expected) {
+			t.Fatalf("Expected %d results (%s), got %d", len(expected), format, len(results))
+		}
+		for i, r := range results {
+			if r.Result != expected[i] {
+				t.Errorf("Expected %s to receive %s (%s), got %s", r.Name, expected[i], format, r.Result)
+			}
+		}
+	}
+
+	// 非法的内联 args 只影响对应的脚本
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "inline_skill"},
+		Body:     `<script args='{bad'>first</script> <script>second</script>`,
+		Scripts:  []resources.Script{echo("first"), echo("second")},
+	}
+	results, err := skill.AutoExecute(ctx, `{}`)
+	if err == nil || !strings.Contains(err.Error(), "invalid inline args") {
+		t.Errorf("Expected invalid inline args error, got %v", err)
+	}
+	if results[0].Err == nil || results[1].Err != nil || results[1].Result != `{}` {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+// flakyClient 前 failures 次调用返回传输错误，之后成功
+type flakyClient struct {
+	failures int
+	calls    int
+}
+
+func (c *flakyClient) Call(ctx context.Context, scriptName string, args string) (string, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return "", &resources.RemoteTransportError{Err: errors.New("connection reset")}
+	}
+	return `{"remote":"` + scriptName + `"}`, nil
+}
+
+func TestSkill_AutoExecuteResilient(t *testing.T) {
+	ctx := context.Background()
+	remote := &flakyClient{failures: 1}
+	localCalls := 0
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "resilient_skill"},
+		Body:     "<script>remote</script><script>local</script>",
+		Scripts: []resources.Script{
+			resources.NewRemoteScript("remote", remote),
+			resources.NewEasyScript("local", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				localCalls++
+				return nil, errors.New("local failure")
+			}),
+		},
+	}
+
+	// 没有策略时远程脚本第一次失败即记录错误
+	results, _ := skill.AutoExecute(ctx, `{}`)
+	if results[0].Err == nil {
+		t.Fatal("Expected remote failure without retries")
+	}
+
+	remote.calls = 0
+	localCalls = 0
+	results, err := skill.AutoExecuteResilient(ctx, `{}`, ResiliencePolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+	if err == nil {
+		t.Error("Expected local failure to be reported")
+	}
+	if results[0].Name != "remote" || results[0].Err != nil || results[0].Result != `{"remote":"remote"}` {
+		t.Errorf("Expected remote script to succeed on retry, got %+v", results[0])
+	}
+	if remote.calls != 2 {
+		t.Errorf("Expected 2 remote calls, got %d", remote.calls)
+	}
+	// 本地脚本的错误不重试
+	if results[1].Name != "local" || results[1].Err == nil || localCalls != 1 {
+		t.Errorf("Expected local script to run once and fail, got %+v (%d calls)", results[1], localCalls)
+	}
+
+	// 自定义 Retryable 时本地脚本同样重试
+	localCalls = 0
+	skill.AutoExecuteResilient(ctx, `{}`, ResiliencePolicy{MaxAttempts: 3, Retryable: func(error) bool { return true }})
+	if localCalls != 3 {
+		t.Errorf("Expected local script to be retried 3 times, got %d", localCalls)
+	}
+}
+
+func TestSkill_AutoExecuteResilientCancel(t *testing.T) {
+	remote := &flakyClient{failures: 100}
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "resilient_skill"},
+		Body:     "<script>first</script><script>second</script>",
+		Scripts: []resources.Script{
+			resources.NewRemoteScript("first", remote),
+			resources.NewRemoteScript("second", remote),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results, _ := skill.AutoExecuteResilient(ctx, `{}`, ResiliencePolicy{MaxAttempts: 100, Backoff: 10 * time.Millisecond})
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline error for %s, got %v", r.Name, r.Err)
+		}
+	}
+	if remote.calls >= 100 {
+		t.Errorf("Expected retries to stop on cancellation, got %d calls", remote.calls)
+	}
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
+)
+
+// Explain 返回 Skill 的诊断报告（多行文本），用于排查脚本或资源无法解析的原因
+// 逐个列出 Body 中的标记、类型、能否解析以及来源（context 覆盖、Provider 或内联），
+// 并指出无法解析的标记（dangling）和 Body 中未引用的内联资源（unused）。只解析不执行脚本
+func (skill *Skill) Explain(ctx context.Context) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Skill: %s (body format %s)\n", skill.GetName(), skill.bodyFormat())
+
+	tags, err := parseBody(skill.bodyFormat(), skill.Body)
+	if err != nil {
+		fmt.Fprintf(&sb, "Body: parse error: %v\n", err)
+	}
+
+	referenced := make(map[util.TagKind]map[string]bool)
+	if len(tags) == 0 {
+		sb.WriteString("Tags: none\n")
+	} else {
+		sb.WriteString("Tags:\n")
+	}
+	for _, tag := range tags {
+		kind := tag.Kind()
+		if referenced[kind] == nil {
+			referenced[kind] = make(map[string]bool)
+		}
+		referenced[kind][tag.Content] = true
+
+		if source, ok := skill.resolveSource(ctx, kind, tag.Content); ok {
+			fmt.Fprintf(&sb, "  %s %q: resolved (%s)\n", kind, tag.Content, source)
+		} else {
+			fmt.Fprintf(&sb, "  %s %q: DANGLING - not found in context override, provider or inline %ss\n", kind, tag.Content, kind)
+		}
+	}
+
+	var unused []string
+	for _, script := range skill.Scripts {
+		if !referenced[util.TagScript][script.GetName()] {
+			unused = append(unused, fmt.Sprintf("  script %q: UNUSED - inline but not referenced in body\n", script.GetName()))
+		}
+	}
+	for _, ref := range skill.References {
+		if !referenced[util.TagReference][ref.Name] {
+			unused = append(unused, fmt.Sprintf("  reference %q: UNUSED - inline but not referenced in body\n", ref.Name))
+		}
+	}
+	for _, asset := range skill.Assets {
+		if !referenced[util.TagAsset][asset.Name] {
+			unused = append(unused, fmt.Sprintf("  asset %q: UNUSED - inline but not referenced in body\n", asset.Name))
+		}
+	}
+	if len(unused) == 0 {
+		sb.WriteString("Unused inline resources: none\n")
+	} else {
+		sb.WriteString("Unused inline resources:\n")
+		for _, line := range unused {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// resolveSource 按 UseScript / ReadReference 的查找顺序判断资源从哪里解析，不执行脚本
+func (skill *Skill) resolveSource(ctx context.Context, kind util.TagKind, name string) (string, bool) {
+	if override, ok := resources.ProviderOverrideFromContext(ctx); ok && providerHas(ctx, override, kind, name) {
+		return "context override", true
+	}
+	if provider := skill.GetProvider(); provider != nil && providerHas(ctx, provider, kind, name) {
+		return "provider", true
+	}
+
+	switch kind {
+	case util.TagScript:
+		for _, script := range skill.Scripts {
+			if script.GetName() == name {
+				return "inline", true
+			}
+		}
+	case util.TagReference:
+		for _, ref := range skill.References {
+			if ref.Name == name {
+				return "inline", true
+			}
+		}
+	case util.TagAsset:
+		for _, asset := range skill.Assets {
+			if asset.Name == name {
+				return "inline", true
+			}
+		}
+	}
+	return "", false
+}
+
+// providerHas 判断提供者能否解析指定资源
+func providerHas(ctx context.Context, provider resources.ResourceProvider, kind util.TagKind, name string) bool {
+	var err error
+	switch kind {
+	case util.TagScript:
+		_, err = provider.GetScript(ctx, name)
+	case util.TagReference:
+		_, err = provider.GetReference(ctx, name)
+	case util.TagAsset:
+		_, err = provider.GetAsset(ctx, name)
+	default:
+		return false
+	}
+	return err == nil
+}
@@ -0,0 +1,52 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/alois132/skill/schema/resources"
+)
+
+// ScriptPanicError 脚本执行时发生 panic，由开启了 RecoverPanics 的 Skill 返回
+type ScriptPanicError struct {
+	Script string      // 脚本名称
+	Value  interface{} // recover() 得到的值
+	Stack  []byte      // panic 时的调用栈
+}
+
+func (e *ScriptPanicError) Error() string {
+	return fmt.Sprintf("script %s panicked: %v", e.Script, e.Value)
+}
+
+// runScript 执行脚本；开启 panic 恢复时将 panic 转换为 *ScriptPanicError
+func (skill *Skill) runScript(ctx context.Context, script resources.Script, args string) (result string, err error) {
+	return skill.runScriptDecoded(ctx, script, args, nil)
+}
+
+// runScriptDecoded 与 runScript 相同，shared 非 nil 且脚本实现了 resources.DecodedScript 时改用 shared（与 args 内容相同）
+func (skill *Skill) runScriptDecoded(ctx context.Context, script resources.Script, args string, shared *resources.DecodedArgs) (result string, err error) {
+	if skill.RecoverPanics {
+		defer RecoverScriptPanic(script.GetName(), &err)
+	}
+	if decoded, ok := script.(resources.DecodedScript); ok && shared != nil {
+		return decoded.RunDecoded(ctx, shared)
+	}
+	return script.Run(ctx, args)
+}
+
+// runScriptBytes 执行二进制脚本，panic 恢复与 runScript 相同
+func (skill *Skill) runScriptBytes(ctx context.Context, name string, script resources.BinaryScript, args string) (data []byte, contentType string, err error) {
+	if skill.RecoverPanics {
+		defer RecoverScriptPanic(name, &err)
+	}
+	return script.RunBytes(ctx, args)
+}
+
+// RecoverScriptPanic 恢复脚本执行中的 panic 并将其作为 *ScriptPanicError 写入 err
+// 必须直接以 defer 调用：defer schema.RecoverScriptPanic(name, &err)
+func RecoverScriptPanic(script string, err *error) {
+	if v := recover(); v != nil {
+		*err = &ScriptPanicError{Script: script, Value: v, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alois132/skill/schema/resources"
+)
+
+// ResiliencePolicy AutoExecuteResilient 的重试策略
+type ResiliencePolicy struct {
+	// MaxAttempts 每个脚本的最大尝试次数（含第一次），<= 1 表示不重试
+	MaxAttempts int
+	// Backoff 第一次重试前的等待时间，之后每次翻倍；0 表示立即重试
+	Backoff time.Duration
+	// MaxBackoff 单次等待时间的上限，0 表示不限制
+	MaxBackoff time.Duration
+	// Retryable 判断错误是否可重试，nil 时使用 resources.IsRemoteUnavailable（仅重试传输错误和 5xx）
+	Retryable func(err error) bool
+}
+
+// retryable 判断错误是否可重试
+func (p *ResiliencePolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return resources.IsRemoteUnavailable(err)
+}
+
+// do 执行 fn，失败且可重试时按退避策略重试
+// 等待期间 context 被取消时停止重试，返回最后一次的错误与 context 错误的合并
+func (p *ResiliencePolicy) do(ctx context.Context, fn func() (string, error)) (string, error) {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(err) {
+			return result, err
+		}
+
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return "", errors.Join(err, ctx.Err())
+			}
+			backoff *= 2
+			if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+				backoff = p.MaxBackoff
+			}
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", errors.Join(err, ctxErr)
+		}
+	}
+}
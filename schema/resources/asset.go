@@ -1,6 +1,10 @@
 package resources
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
 // todo 暂时没get到它的实际用处
 
@@ -34,3 +38,66 @@ func (a *Asset) Size() int64 {
 func (a *Asset) String() string {
 	return fmt.Sprintf("Asset{Name: %s, Ext: %s, Size: %d bytes}", a.Name, a.Ext, a.Size())
 }
+
+// ContentType returns the MIME type of the asset based on its extension
+func (a *Asset) ContentType() string {
+	return a.Ext.ContentType()
+}
+
+var (
+	assetTypesMu sync.RWMutex
+	// assetTypes 已知扩展名到 MIME 类型的注册表
+	assetTypes = map[AssetExt]string{
+		PNG:   "image/png",
+		PPTX:  "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		TTF:   "font/ttf",
+		PDF:   "application/pdf",
+		JPG:   "image/jpeg",
+		JPEG:  "image/jpeg",
+		GIF:   "image/gif",
+		SVG:   "image/svg+xml",
+		WOFF:  "font/woff",
+		WOFF2: "font/woff2",
+	}
+)
+
+// RegisterAssetExt registers (or replaces) a supported extension and its MIME type
+func RegisterAssetExt(ext AssetExt, contentType string) {
+	assetTypesMu.Lock()
+	defer assetTypesMu.Unlock()
+	assetTypes[AssetExt(strings.ToLower(string(ext)))] = contentType
+}
+
+// Valid reports whether the extension is registered
+func (e AssetExt) Valid() bool {
+	assetTypesMu.RLock()
+	defer assetTypesMu.RUnlock()
+	_, ok := assetTypes[e]
+	return ok
+}
+
+// ContentType returns the registered MIME type, or application/octet-stream for unknown extensions
+func (e AssetExt) ContentType() string {
+	assetTypesMu.RLock()
+	defer assetTypesMu.RUnlock()
+	if contentType, ok := assetTypes[e]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// AssetExtForContentType returns the registered extension for a MIME type (parameters such as charset are ignored)
+// When several extensions share a MIME type, the alphabetically first one is returned
+func AssetExtForContentType(contentType string) (AssetExt, bool) {
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+
+	assetTypesMu.RLock()
+	defer assetTypesMu.RUnlock()
+	var found AssetExt
+	for ext, registered := range assetTypes {
+		if registered == mediaType && (found == "" || ext < found) {
+			found = ext
+		}
+	}
+	return found, found != ""
+}
@@ -0,0 +1,106 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// BinaryScript 可返回原始字节的脚本
+// 实现此接口的脚本可以输出图片等二进制内容，而不经过 JSON 序列化
+type BinaryScript interface {
+	// RunBytes 执行脚本，返回原始字节和内容类型（如 "image/png"）
+	RunBytes(ctx context.Context, args string) (data []byte, contentType string, err error)
+}
+
+// runBytes 执行脚本并返回原始字节：实现 BinaryScript 时调用 RunBytes，否则将 Run 的结果作为 application/json 返回
+func runBytes(ctx context.Context, script Script, args string) ([]byte, string, error) {
+	if binary, ok := script.(BinaryScript); ok {
+		return binary.RunBytes(ctx, args)
+	}
+	result, err := script.Run(ctx, args)
+	if err != nil {
+		return nil, "", err
+	}
+	return []byte(result), "application/json", nil
+}
+
+// withBinary 在 inner 实现 BinaryScript 时返回同样实现 BinaryScript 的 wrapper，RunBytes 直接委托给 inner
+func withBinary(wrapper Script, inner Script) Script {
+	if binary, ok := inner.(BinaryScript); ok {
+		return &binaryForwardingScript{Script: wrapper, binary: binary}
+	}
+	return wrapper
+}
+
+// binaryForwardingScript 将 RunBytes 委托给内部二进制脚本的包装
+type binaryForwardingScript struct {
+	Script
+	binary BinaryScript
+}
+
+// RunBytes 委托给内部脚本
+func (s *binaryForwardingScript) RunBytes(ctx context.Context, args string) ([]byte, string, error) {
+	return s.binary.RunBytes(ctx, args)
+}
+
+// BytesFunc 返回原始字节的脚本函数
+type BytesFunc func(ctx context.Context, args string) ([]byte, error)
+
+// BytesScript 返回二进制内容的脚本实现
+// Run 返回 base64 编码的内容，RunBytes 返回原始字节
+type BytesScript struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	ContentType string `json:"content_type"`
+	Fn          BytesFunc
+}
+
+// NewBytesScript 创建一个新的二进制脚本
+func NewBytesScript(name string, contentType string, fn BytesFunc) *BytesScript {
+	return &BytesScript{
+		Name:        name,
+		ContentType: contentType,
+		Fn:          fn,
+	}
+}
+
+// Run 执行脚本，返回 base64 编码的结果
+func (s *BytesScript) Run(ctx context.Context, args string) (string, error) {
+	data, err := s.Fn(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// RunBytes 执行脚本，返回原始字节和内容类型
+func (s *BytesScript) RunBytes(ctx context.Context, args string) ([]byte, string, error) {
+	data, err := s.Fn(ctx, args)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, s.ContentType, nil
+}
+
+// GetName 获取脚本名称
+func (s *BytesScript) GetName() string {
+	return s.Name
+}
+
+// GetUsage 获取脚本使用说明
+func (s *BytesScript) GetUsage() string {
+	if s.Usage != "" {
+		return s.Usage
+	}
+	return "Output: " + s.ContentType + " bytes"
+}
+
+// WithUsage 设置脚本使用说明
+func (s *BytesScript) WithUsage(usage string) *BytesScript {
+	s.Usage = usage
+	return s
+}
+
+// Ensure BytesScript implements Script and BinaryScript
+var _ Script = (*BytesScript)(nil)
+var _ BinaryScript = (*BytesScript)(nil)
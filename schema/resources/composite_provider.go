@@ -2,7 +2,6 @@ package resources
 
 import (
 	"context"
-	"errors"
 	"fmt"
 )
 
@@ -25,9 +24,13 @@ func (p *CompositeProvider) AddProvider(provider ResourceProvider) {
 }
 
 // GetScript 按优先级从所有提供者中查找脚本
+// 每次询问提供者前检查 ctx，已取消时立即返回 ctx.Err()，不再询问剩余的提供者
 func (p *CompositeProvider) GetScript(ctx context.Context, name string) (Script, error) {
 	var lastErr error
 	for _, provider := range p.providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		script, err := provider.GetScript(ctx, name)
 		if err == nil {
 			return script, nil
@@ -37,13 +40,16 @@ func (p *CompositeProvider) GetScript(ctx context.Context, name string) (Script,
 	if lastErr != nil {
 		return nil, lastErr
 	}
-	return nil, errors.New("script not found: " + name)
+	return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
 }
 
-// GetReference 按优先级从所有提供者中查找参考文档
+// GetReference 按优先级从所有提供者中查找参考文档，取消处理与 GetScript 相同
 func (p *CompositeProvider) GetReference(ctx context.Context, name string) (string, error) {
 	var lastErr error
 	for _, provider := range p.providers {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		ref, err := provider.GetReference(ctx, name)
 		if err == nil {
 			return ref, nil
@@ -53,13 +59,16 @@ func (p *CompositeProvider) GetReference(ctx context.Context, name string) (stri
 	if lastErr != nil {
 		return "", lastErr
 	}
-	return "", errors.New("reference not found: " + name)
+	return "", fmt.Errorf("%w: %s", ErrReferenceNotFound, name)
 }
 
-// GetAsset 按优先级从所有提供者中查找资源文件
+// GetAsset 按优先级从所有提供者中查找资源文件，取消处理与 GetScript 相同
 func (p *CompositeProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
 	var lastErr error
 	for _, provider := range p.providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		asset, err := provider.GetAsset(ctx, name)
 		if err == nil {
 			return asset, nil
@@ -69,54 +78,58 @@ func (p *CompositeProvider) GetAsset(ctx context.Context, name string) (*Asset,
 	if lastErr != nil {
 		return nil, lastErr
 	}
-	return nil, errors.New("asset not found: " + name)
+	return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
 }
 
 // ListScripts 合并所有提供者的脚本列表
 func (p *CompositeProvider) ListScripts(ctx context.Context) ([]string, error) {
-	nameSet := make(map[string]struct{})
-	for _, provider := range p.providers {
-		names, err := provider.ListScripts(ctx)
-		if err != nil {
-			continue // 跳过出错的提供者
-		}
-		for _, name := range names {
-			nameSet[name] = struct{}{}
-		}
-	}
-	names := make([]string, 0, len(nameSet))
-	for name := range nameSet {
-		names = append(names, name)
-	}
-	return names, nil
+	names, _ := p.ListScriptsWithErrors(ctx)
+	return names, nil // 跳过出错的提供者
 }
 
 // ListReferences 合并所有提供者的参考文档列表
 func (p *CompositeProvider) ListReferences(ctx context.Context) ([]string, error) {
-	nameSet := make(map[string]struct{})
-	for _, provider := range p.providers {
-		names, err := provider.ListReferences(ctx)
-		if err != nil {
-			continue // 跳过出错的提供者
-		}
-		for _, name := range names {
-			nameSet[name] = struct{}{}
-		}
-	}
-	names := make([]string, 0, len(nameSet))
-	for name := range nameSet {
-		names = append(names, name)
-	}
-	return names, nil
+	names, _ := p.ListReferencesWithErrors(ctx)
+	return names, nil // 跳过出错的提供者
 }
 
 // ListAssets 合并所有提供者的资源文件列表
 func (p *CompositeProvider) ListAssets(ctx context.Context) ([]string, error) {
+	names, _ := p.ListAssetsWithErrors(ctx)
+	return names, nil // 跳过出错的提供者
+}
+
+// ListScriptsWithErrors 合并所有提供者的脚本列表，同时返回出错提供者的错误
+// 错误信息包含提供者在组合中的下标，调用方可据此判断部分结果是否可接受
+func (p *CompositeProvider) ListScriptsWithErrors(ctx context.Context) ([]string, []error) {
+	return p.mergeNames(func(provider ResourceProvider) ([]string, error) {
+		return provider.ListScripts(ctx)
+	})
+}
+
+// ListReferencesWithErrors 合并所有提供者的参考文档列表，同时返回出错提供者的错误
+func (p *CompositeProvider) ListReferencesWithErrors(ctx context.Context) ([]string, []error) {
+	return p.mergeNames(func(provider ResourceProvider) ([]string, error) {
+		return provider.ListReferences(ctx)
+	})
+}
+
+// ListAssetsWithErrors 合并所有提供者的资源文件列表，同时返回出错提供者的错误
+func (p *CompositeProvider) ListAssetsWithErrors(ctx context.Context) ([]string, []error) {
+	return p.mergeNames(func(provider ResourceProvider) ([]string, error) {
+		return provider.ListAssets(ctx)
+	})
+}
+
+// mergeNames 对每个提供者调用 list 并合并去重，出错的提供者被跳过并记录错误
+func (p *CompositeProvider) mergeNames(list func(provider ResourceProvider) ([]string, error)) ([]string, []error) {
 	nameSet := make(map[string]struct{})
-	for _, provider := range p.providers {
-		names, err := provider.ListAssets(ctx)
+	var errs []error
+	for i, provider := range p.providers {
+		names, err := list(provider)
 		if err != nil {
-			continue // 跳过出错的提供者
+			errs = append(errs, fmt.Errorf("provider %d: %w", i, err))
+			continue
 		}
 		for _, name := range names {
 			nameSet[name] = struct{}{}
@@ -126,7 +139,7 @@ func (p *CompositeProvider) ListAssets(ctx context.Context) ([]string, error) {
 	for name := range nameSet {
 		names = append(names, name)
 	}
-	return names, nil
+	return names, errs
 }
 
 // CachingProvider 带缓存的资源提供者装饰器
@@ -226,8 +239,9 @@ func (p *CachingProvider) ClearAssetCache() {
 // Ensure CompositeProvider implements ResourceProvider
 var _ ResourceProvider = (*CompositeProvider)(nil)
 
-// Ensure CachingProvider implements ResourceProvider
+// Ensure CachingProvider implements ResourceProvider and Clearable
 var _ ResourceProvider = (*CachingProvider)(nil)
+var _ Clearable = (*CachingProvider)(nil)
 
 // LazyLoadingProvider 懒加载资源提供者
 // 只在首次访问时从 loader 加载资源
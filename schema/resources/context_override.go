@@ -0,0 +1,19 @@
+package resources
+
+import "context"
+
+// providerOverrideKey 用于在 context 中传递覆盖的资源提供者
+type providerOverrideKey struct{}
+
+// WithProviderOverride 返回携带覆盖资源提供者的 context
+// Skill 在解析脚本和参考文档时会优先使用该提供者（未找到时回退到 Skill 自身的 Provider 和内联资源），
+// 覆盖只作用于使用该 context 的调用，不会修改 Skill；p 为 nil 时等同于不覆盖
+func WithProviderOverride(ctx context.Context, p ResourceProvider) context.Context {
+	return context.WithValue(ctx, providerOverrideKey{}, p)
+}
+
+// ProviderOverrideFromContext 从 context 中取出覆盖的资源提供者
+func ProviderOverrideFromContext(ctx context.Context) (ResourceProvider, bool) {
+	p, ok := ctx.Value(providerOverrideKey{}).(ResourceProvider)
+	return p, ok && p != nil
+}
@@ -0,0 +1,131 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// requestedScriptKey 用于在 context 中传递被请求的脚本名称
+type requestedScriptKey struct{}
+
+// RequestedScriptName 从 context 中获取被请求的脚本名称
+// 仅在脚本由 FallbackProvider 兜底执行时存在
+func RequestedScriptName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(requestedScriptKey{}).(string)
+	return name, ok
+}
+
+// FallbackProvider 带默认脚本兜底的资源提供者
+// 内部提供者找不到脚本时，返回默认脚本；参考文档和资源文件不做兜底
+type FallbackProvider struct {
+	inner         ResourceProvider
+	defaultScript Script
+}
+
+// NewFallbackProvider 创建一个新的兜底资源提供者
+// inner 可以为 nil，此时所有脚本请求都由 defaultScript 处理
+func NewFallbackProvider(inner ResourceProvider, defaultScript Script) *FallbackProvider {
+	return &FallbackProvider{
+		inner:         inner,
+		defaultScript: defaultScript,
+	}
+}
+
+// GetScript 优先从内部提供者获取脚本，内部提供者返回 ErrScriptNotFound 时返回默认脚本
+// 其他错误（传输失败、ctx 取消、I/O 错误等）原样返回，不会被默认脚本掩盖。
+// 返回的默认脚本名称为被请求的名称，执行时可通过 RequestedScriptName 获取
+func (p *FallbackProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	if p.inner != nil {
+		script, err := p.inner.GetScript(ctx, name)
+		if err == nil {
+			return script, nil
+		}
+		if !errors.Is(err, ErrScriptNotFound) || p.defaultScript == nil {
+			return nil, err
+		}
+	}
+	if p.defaultScript == nil {
+		return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
+	}
+	script := &fallbackScript{name: name, script: p.defaultScript}
+	if binary, ok := p.defaultScript.(BinaryScript); ok {
+		return &fallbackBinaryScript{fallbackScript: script, binary: binary}, nil
+	}
+	return script, nil
+}
+
+// GetReference 从内部提供者获取参考文档（无兜底）
+func (p *FallbackProvider) GetReference(ctx context.Context, name string) (string, error) {
+	if p.inner == nil {
+		return "", fmt.Errorf("%w: %s", ErrReferenceNotFound, name)
+	}
+	return p.inner.GetReference(ctx, name)
+}
+
+// GetAsset 从内部提供者获取资源文件（无兜底）
+func (p *FallbackProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	if p.inner == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+	}
+	return p.inner.GetAsset(ctx, name)
+}
+
+// ListScripts 列出内部提供者的脚本（不包含兜底脚本）
+func (p *FallbackProvider) ListScripts(ctx context.Context) ([]string, error) {
+	if p.inner == nil {
+		return []string{}, nil
+	}
+	return p.inner.ListScripts(ctx)
+}
+
+// ListReferences 列出内部提供者的参考文档
+func (p *FallbackProvider) ListReferences(ctx context.Context) ([]string, error) {
+	if p.inner == nil {
+		return []string{}, nil
+	}
+	return p.inner.ListReferences(ctx)
+}
+
+// ListAssets 列出内部提供者的资源文件
+func (p *FallbackProvider) ListAssets(ctx context.Context) ([]string, error) {
+	if p.inner == nil {
+		return []string{}, nil
+	}
+	return p.inner.ListAssets(ctx)
+}
+
+// fallbackScript 以被请求的名称包装默认脚本
+type fallbackScript struct {
+	name   string
+	script Script
+}
+
+// Run 执行默认脚本，并在 context 中携带被请求的脚本名称
+func (s *fallbackScript) Run(ctx context.Context, args string) (string, error) {
+	return s.script.Run(context.WithValue(ctx, requestedScriptKey{}, s.name), args)
+}
+
+// GetName 返回被请求的脚本名称
+func (s *fallbackScript) GetName() string {
+	return s.name
+}
+
+// GetUsage 返回默认脚本的使用说明
+func (s *fallbackScript) GetUsage() string {
+	return s.script.GetUsage()
+}
+
+// fallbackBinaryScript 默认脚本为二进制脚本时的包装
+type fallbackBinaryScript struct {
+	*fallbackScript
+	binary BinaryScript
+}
+
+// RunBytes 执行默认脚本的 RunBytes，并在 context 中携带被请求的脚本名称
+func (s *fallbackBinaryScript) RunBytes(ctx context.Context, args string) ([]byte, string, error) {
+	return s.binary.RunBytes(context.WithValue(ctx, requestedScriptKey{}, s.name), args)
+}
+
+// Ensure FallbackProvider implements ResourceProvider
+var _ ResourceProvider = (*FallbackProvider)(nil)
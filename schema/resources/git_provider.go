@@ -0,0 +1,95 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// GitFS 最小化的 git 仓库只读访问接口
+// 可基于 go-git 或本地 checkout 实现，文件不存在时应返回 fs.ErrNotExist
+type GitFS interface {
+	// ReadFile 读取指定版本下的文件内容
+	ReadFile(ref, path string) ([]byte, error)
+	// List 列出指定版本下目录中的文件名
+	List(ref, dir string) ([]string, error)
+}
+
+// GitReferenceProvider 从 git 仓库提供参考文档
+// 参考文档位于 dir/<name>.md，并固定在 ref（commit 或 tag）上以保证可复现；不提供脚本和资源文件
+type GitReferenceProvider struct {
+	fs  GitFS
+	ref string
+	dir string
+}
+
+// NewGitReferenceProvider 创建一个新的 git 参考文档提供者
+func NewGitReferenceProvider(gitFS GitFS, ref string, dir string) *GitReferenceProvider {
+	return &GitReferenceProvider{
+		fs:  gitFS,
+		ref: ref,
+		dir: dir,
+	}
+}
+
+// GetScript git 提供者不提供脚本
+func (p *GitReferenceProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
+}
+
+// GetReference 读取 ref 版本下的 dir/<name>.md
+// 包含路径分隔符或 ".." 的名称可能指向 dir 之外的文件，返回错误
+func (p *GitReferenceProvider) GetReference(ctx context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("invalid reference name: %q", name)
+	}
+	data, err := p.fs.ReadFile(p.ref, path.Join(p.dir, name+".md"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrReferenceNotFound, name)
+		}
+		return "", fmt.Errorf("failed to read reference %s at %s: %w", name, p.ref, err)
+	}
+	return string(data), nil
+}
+
+// GetAsset git 提供者不提供资源文件
+func (p *GitReferenceProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+}
+
+// ListScripts 返回空列表
+func (p *GitReferenceProvider) ListScripts(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+// ListReferences 列出 ref 版本下 dir 中的 .md 文件名（不含扩展名）
+func (p *GitReferenceProvider) ListReferences(ctx context.Context) ([]string, error) {
+	files, err := p.fs.List(p.ref, p.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list references at %s: %w", p.ref, err)
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		base := path.Base(file)
+		if strings.HasSuffix(base, ".md") {
+			names = append(names, strings.TrimSuffix(base, ".md"))
+		}
+	}
+	return names, nil
+}
+
+// ListAssets 返回空列表
+func (p *GitReferenceProvider) ListAssets(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+// Ensure GitReferenceProvider implements ResourceProvider
+var _ ResourceProvider = (*GitReferenceProvider)(nil)
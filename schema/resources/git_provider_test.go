@@ -0,0 +1,108 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+)
+
+// fakeGitFS 按 ref 存储文件的内存 GitFS
+type fakeGitFS map[string]map[string]string // ref -> path -> content
+
+func (f fakeGitFS) ReadFile(ref, p string) ([]byte, error) {
+	content, ok := f[ref][p]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (f fakeGitFS) List(ref, dir string) ([]string, error) {
+	files, ok := f[ref]
+	if !ok {
+		return nil, errors.New("unknown ref: " + ref)
+	}
+	var names []string
+	for p := range files {
+		if path.Dir(p) == dir {
+			names = append(names, path.Base(p))
+		}
+	}
+	return names, nil
+}
+
+func TestGitReferenceProvider(t *testing.T) {
+	ctx := context.Background()
+	gitFS := fakeGitFS{
+		"v1.0.0": {
+			"docs/refs/api.md":    "# API v1",
+			"docs/refs/notes.txt": "ignored",
+		},
+		"v2.0.0": {
+			"docs/refs/api.md": "# API v2",
+		},
+	}
+
+	provider := NewGitReferenceProvider(gitFS, "v1.0.0", "docs/refs")
+
+	// 固定版本读取
+	body, err := provider.GetReference(ctx, "api")
+	if err != nil {
+		t.Fatalf("GetReference failed: %v", err)
+	}
+	if body != "# API v1" {
+		t.Errorf("Expected '# API v1', got '%s'", body)
+	}
+
+	names, err := provider.ListReferences(ctx)
+	if err != nil {
+		t.Fatalf("ListReferences failed: %v", err)
+	}
+	if len(names) != 1 || names[0] != "api" {
+		t.Errorf("Expected [api], got %v", names)
+	}
+
+	// 文件不存在
+	_, err = provider.GetReference(ctx, "missing")
+	if !errors.Is(err, ErrReferenceNotFound) {
+		t.Errorf("Expected ErrReferenceNotFound, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected error to mention reference name, got %v", err)
+	}
+
+	// 其他版本
+	body, err = NewGitReferenceProvider(gitFS, "v2.0.0", "docs/refs").GetReference(ctx, "api")
+	if err != nil {
+		t.Fatalf("GetReference failed: %v", err)
+	}
+	if body != "# API v2" {
+		t.Errorf("Expected '# API v2', got '%s'", body)
+	}
+}
+
+func TestGitReferenceProvider_RejectsPathTraversal(t *testing.T) {
+	ctx := context.Background()
+	gitFS := fakeGitFS{
+		"v1.0.0": {
+			"docs/refs/api.md": "# API",
+			"secrets.md":       "top secret",
+			"docs/private.md":  "private",
+		},
+	}
+	provider := NewGitReferenceProvider(gitFS, "v1.0.0", "docs/refs")
+
+	// 指向 dir 之外的名称被拒绝
+	for _, name := range []string{"../../secrets", "../private", "sub/api", `..\private`, ""} {
+		body, err := provider.GetReference(ctx, name)
+		if err == nil || !strings.Contains(err.Error(), "invalid reference name") {
+			t.Errorf("Expected invalid name error for %q, got %q (%v)", name, body, err)
+		}
+	}
+	if body, err := provider.GetReference(ctx, "api"); err != nil || body != "# API" {
+		t.Errorf("Expected api reference, got %q (%v)", body, err)
+	}
+}
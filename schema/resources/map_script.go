@@ -0,0 +1,134 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/alois132/skill/util"
+)
+
+// MapFunc 输入输出均为动态 JSON 对象的脚本函数
+type MapFunc func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error)
+
+// MapScript 输入输出均为 map[string]interface{} 的脚本
+// 与 EasyScript[map[string]interface{}, map[string]interface{}] 行为和输出一致，但不经过反射创建实例，
+// 并复用参数和结果的编解码缓冲区，每次调用的内存分配更少
+// 推荐用于没有固定输入结构的动态脚本
+type MapScript struct {
+	Name  string `json:"name"`
+	Usage string `json:"usage"`
+	Fn    MapFunc
+}
+
+// mapCodec 可复用的参数缓冲区和结果编码器
+type mapCodec struct {
+	in  []byte
+	out bytes.Buffer
+	enc *json.Encoder
+}
+
+// maxPooledCodecSize 超过此大小的缓冲区不放回池中，避免长期占用内存
+const maxPooledCodecSize = 64 << 10
+
+var mapCodecPool = sync.Pool{
+	New: func() any {
+		c := &mapCodec{}
+		c.enc = json.NewEncoder(&c.out)
+		return c
+	},
+}
+
+func (c *mapCodec) release() {
+	if cap(c.in) > maxPooledCodecSize || c.out.Cap() > maxPooledCodecSize {
+		return
+	}
+	c.in = c.in[:0]
+	c.out.Reset()
+	mapCodecPool.Put(c)
+}
+
+// mapScriptUsage 与泛型版本自动生成的使用说明保持一致
+var mapScriptUsage = fmt.Sprintf("Input: %s, Output: %s",
+	util.TypeOf[map[string]interface{}]().String(), util.TypeOf[map[string]interface{}]().String())
+
+// NewMapScript creates a new MapScript with the given name and function
+func NewMapScript(name string, fn MapFunc) *MapScript {
+	return &MapScript{
+		Name: name,
+		Fn:   fn,
+	}
+}
+
+// Run 执行脚本
+func (s *MapScript) Run(ctx context.Context, args string) (string, error) {
+	// 复用缓冲区承载参数字节，json.Unmarshal 不会保留对 data 的引用
+	c := mapCodecPool.Get().(*mapCodec)
+	c.in = append(c.in[:0], args...)
+	defer c.release()
+	return s.run(ctx, c.in)
+}
+
+// RunDecoded 使用共享参数解析结果的副本执行脚本，不再反序列化
+func (s *MapScript) RunDecoded(ctx context.Context, args *DecodedArgs) (string, error) {
+	input, err := args.Object()
+	if err != nil {
+		return "", err
+	}
+	return s.call(ctx, input)
+}
+
+func (s *MapScript) run(ctx context.Context, data []byte) (string, error) {
+	// 与 util.NewInstance 相同：预先创建空 map，"null" 参数仍得到 nil
+	input := make(map[string]interface{})
+	if err := json.Unmarshal(data, &input); err != nil {
+		return "", err
+	}
+	return s.call(ctx, input)
+}
+
+// call 执行 Fn 并序列化结果
+func (s *MapScript) call(ctx context.Context, input map[string]interface{}) (string, error) {
+	output, err := s.Fn(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	c := mapCodecPool.Get().(*mapCodec)
+	defer c.release()
+	if err := c.enc.Encode(output); err != nil {
+		return "", err
+	}
+	// Encode 会追加换行，去掉后与 json.Marshal 的输出一致
+	return string(bytes.TrimSuffix(c.out.Bytes(), []byte("\n"))), nil
+}
+
+// GetName 获取脚本名称
+func (s *MapScript) GetName() string {
+	return s.Name
+}
+
+// GetUsage 获取脚本使用说明
+func (s *MapScript) GetUsage() string {
+	if s.Usage != "" {
+		return s.Usage
+	}
+	s.Usage = mapScriptUsage
+	return s.Usage
+}
+
+// Schema 返回与泛型版本相同的 JSON Schema
+func (s *MapScript) Schema(ctx context.Context) (*ScriptSchema, error) {
+	schema, err := json.Marshal(util.JSONSchema(util.TypeOf[map[string]interface{}]()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input schema: %w", err)
+	}
+	return &ScriptSchema{Input: schema, Output: schema}, nil
+}
+
+// Ensure MapScript implements Script, DecodedScript and SchemaProvider
+var _ Script = (*MapScript)(nil)
+var _ DecodedScript = (*MapScript)(nil)
+var _ SchemaProvider = (*MapScript)(nil)
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func calculate(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+	a, _ := input["a"].(float64)
+	b, _ := input["b"].(float64)
+	switch input["op"] {
+	case "add":
+		return map[string]interface{}{"result": a + b}, nil
+	case "div":
+		if b == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return map[string]interface{}{"result": a / b}, nil
+	default:
+		return nil, fmt.Errorf("unknown op: %v", input["op"])
+	}
+}
+
+func convertTimezone(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+	tz, _ := input["timezone"].(string)
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).In(loc)
+	return map[string]interface{}{"time": t.Format(time.RFC3339), "timezone": loc.String(), "input": input}, nil
+}
+
+func TestMapScript_MatchesEasyScript(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		fn   MapFunc
+		args []string
+	}{
+		{calculate, []string{`{"op":"add","a":1,"b":2.5}`, `{"op":"div","a":1,"b":0}`, `{"op":"mod"}`, `{}`, `null`, `not json`}},
+		{convertTimezone, []string{`{"timezone":"UTC"}`, `{"timezone":"Asia/Shanghai","extra":[1,{"x":true}]}`, `{"timezone":"Nowhere/City"}`}},
+	}
+
+	for _, c := range cases {
+		generic := NewEasyScript("s", ScriptFunc[map[string]interface{}, map[string]interface{}](c.fn))
+		fast := NewMapScript("s", c.fn)
+		for _, args := range c.args {
+			want, wantErr := generic.Run(ctx, args)
+			got, gotErr := fast.Run(ctx, args)
+			if got != want || (gotErr == nil) != (wantErr == nil) {
+				t.Errorf("Expected %q (err %v) for %s, got %q (err %v)", want, wantErr, args, got, gotErr)
+			}
+			decoded, _ := fast.RunDecoded(ctx, NewDecodedArgs(args))
+			if decoded != got {
+				t.Errorf("Expected RunDecoded to match Run for %s, got %q", args, decoded)
+			}
+		}
+
+		if fast.GetUsage() != generic.GetUsage() {
+			t.Errorf("Expected usage %q, got %q", generic.GetUsage(), fast.GetUsage())
+		}
+		want, _ := generic.Schema(ctx)
+		got, err := fast.Schema(ctx)
+		if err != nil || string(got.Input) != string(want.Input) || string(got.Output) != string(want.Output) {
+			t.Errorf("Expected schema %s/%s, got %+v (err %v)", want.Input, want.Output, got, err)
+		}
+	}
+}
+
+func BenchmarkMapScript_Run(b *testing.B) {
+	script := NewMapScript("calc", calculate)
+	benchmarkScript(b, script)
+}
+
+func BenchmarkMapScript_GenericRun(b *testing.B) {
+	script := NewEasyScript("calc", ScriptFunc[map[string]interface{}, map[string]interface{}](calculate))
+	benchmarkScript(b, script)
+}
+
+func benchmarkScript(b *testing.B, script Script) {
+	ctx := context.Background()
+	args := `{"op":"add","a":1,"b":2.5}`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := script.Run(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProviderOp 资源提供者的操作类型
+type ProviderOp string
+
+const (
+	OpGetScript      ProviderOp = "get_script"
+	OpGetReference   ProviderOp = "get_reference"
+	OpGetAsset       ProviderOp = "get_asset"
+	OpListScripts    ProviderOp = "list_scripts"
+	OpListReferences ProviderOp = "list_references"
+	OpListAssets     ProviderOp = "list_assets"
+)
+
+// ProviderCall 一次资源提供者调用
+// Name 为请求的资源名称，List 操作时为空
+type ProviderCall struct {
+	Op   ProviderOp
+	Name string
+}
+
+// ProviderHandler 统一的资源提供者调用处理函数
+// 返回值的类型取决于 Op：Script、string、*Asset 或 []string
+type ProviderHandler func(ctx context.Context, call ProviderCall) (interface{}, error)
+
+// ProviderMiddleware 资源提供者中间件，类似 HTTP 中间件的组合方式
+type ProviderMiddleware func(next ProviderHandler) ProviderHandler
+
+// MiddlewareProvider 应用了中间件的资源提供者
+type MiddlewareProvider struct {
+	provider ResourceProvider
+	handler  ProviderHandler
+}
+
+// WithProviderMiddleware 为资源提供者添加中间件
+// 第一个中间件位于最外层，最先执行；错误原样向外传递
+func WithProviderMiddleware(p ResourceProvider, mw ...ProviderMiddleware) *MiddlewareProvider {
+	mp := &MiddlewareProvider{provider: p}
+	handler := mp.dispatch
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	mp.handler = handler
+	return mp
+}
+
+// dispatch 将调用分发到底层提供者
+func (p *MiddlewareProvider) dispatch(ctx context.Context, call ProviderCall) (interface{}, error) {
+	switch call.Op {
+	case OpGetScript:
+		return p.provider.GetScript(ctx, call.Name)
+	case OpGetReference:
+		return p.provider.GetReference(ctx, call.Name)
+	case OpGetAsset:
+		return p.provider.GetAsset(ctx, call.Name)
+	case OpListScripts:
+		return p.provider.ListScripts(ctx)
+	case OpListReferences:
+		return p.provider.ListReferences(ctx)
+	case OpListAssets:
+		return p.provider.ListAssets(ctx)
+	}
+	return nil, fmt.Errorf("unknown provider op: %s", call.Op)
+}
+
+// GetScript 获取脚本
+func (p *MiddlewareProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	result, err := p.handler(ctx, ProviderCall{Op: OpGetScript, Name: name})
+	script, _ := result.(Script)
+	return script, err
+}
+
+// GetReference 获取参考文档
+func (p *MiddlewareProvider) GetReference(ctx context.Context, name string) (string, error) {
+	result, err := p.handler(ctx, ProviderCall{Op: OpGetReference, Name: name})
+	ref, _ := result.(string)
+	return ref, err
+}
+
+// GetAsset 获取资源文件
+func (p *MiddlewareProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	result, err := p.handler(ctx, ProviderCall{Op: OpGetAsset, Name: name})
+	asset, _ := result.(*Asset)
+	return asset, err
+}
+
+// ListScripts 列出脚本
+func (p *MiddlewareProvider) ListScripts(ctx context.Context) ([]string, error) {
+	result, err := p.handler(ctx, ProviderCall{Op: OpListScripts})
+	names, _ := result.([]string)
+	return names, err
+}
+
+// ListReferences 列出参考文档
+func (p *MiddlewareProvider) ListReferences(ctx context.Context) ([]string, error) {
+	result, err := p.handler(ctx, ProviderCall{Op: OpListReferences})
+	names, _ := result.([]string)
+	return names, err
+}
+
+// ListAssets 列出资源文件
+func (p *MiddlewareProvider) ListAssets(ctx context.Context) ([]string, error) {
+	result, err := p.handler(ctx, ProviderCall{Op: OpListAssets})
+	names, _ := result.([]string)
+	return names, err
+}
+
+// Ensure MiddlewareProvider implements ResourceProvider
+var _ ResourceProvider = (*MiddlewareProvider)(nil)
@@ -3,6 +3,17 @@ package resources
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrScriptNotFound 脚本不存在
+	ErrScriptNotFound = errors.New("script not found")
+	// ErrReferenceNotFound 参考文档不存在
+	ErrReferenceNotFound = errors.New("reference not found")
+	// ErrAssetNotFound 资源文件不存在
+	ErrAssetNotFound = errors.New("asset not found")
 )
 
 // ResourceProvider 统一资源提供者接口
@@ -23,93 +34,145 @@ type ResourceProvider interface {
 	ListAssets(ctx context.Context) ([]string, error)
 }
 
+// Clearable 带有可清空缓存的资源提供者，如 CachingProvider
+type Clearable interface {
+	ClearCache()
+}
+
 // InlineProvider 内联资源提供者
-// 从内存中的 Scripts、References、Assets 切片提供资源
+// 从内存中的脚本、参考文档、资源文件切片提供资源，可并发读写
 type InlineProvider struct {
-	Scripts    []Script
-	References []*Reference
-	Assets     []*Asset
+	mu         sync.RWMutex
+	scripts    []Script
+	references []*Reference
+	assets     []*Asset
 }
 
 // NewInlineProvider 创建一个新的内联资源提供者
 func NewInlineProvider() *InlineProvider {
 	return &InlineProvider{
-		Scripts:    make([]Script, 0),
-		References: make([]*Reference, 0),
-		Assets:     make([]*Asset, 0),
+		scripts:    make([]Script, 0),
+		references: make([]*Reference, 0),
+		assets:     make([]*Asset, 0),
 	}
 }
 
 // GetScript 从内存中获取脚本
 func (p *InlineProvider) GetScript(ctx context.Context, name string) (Script, error) {
-	for _, script := range p.Scripts {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, script := range p.scripts {
 		if script.GetName() == name {
 			return script, nil
 		}
 	}
-	return nil, errors.New("script not found: " + name)
+	return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
 }
 
 // GetReference 从内存中获取参考文档
 func (p *InlineProvider) GetReference(ctx context.Context, name string) (string, error) {
-	for _, ref := range p.References {
+	p.mu.RLock()
+	var found *Reference
+	for _, ref := range p.references {
 		if ref.Name == name {
-			return ref.Body, nil
+			found = ref
+			break
 		}
 	}
-	return "", errors.New("reference not found: " + name)
+	p.mu.RUnlock()
+
+	if found == nil {
+		return "", fmt.Errorf("%w: %s", ErrReferenceNotFound, name)
+	}
+	// 在锁外加载，避免耗时的 loader 阻塞其他读写
+	return found.Load(ctx)
 }
 
 // GetAsset 从内存中获取资源文件
 func (p *InlineProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
-	for _, asset := range p.Assets {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, asset := range p.assets {
 		if asset.Name == name {
 			return asset, nil
 		}
 	}
-	return nil, errors.New("asset not found: " + name)
+	return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
 }
 
-// ListScripts 列出所有脚本名称
+// ListScripts 列出所有脚本名称（调用时刻的快照）
 func (p *InlineProvider) ListScripts(ctx context.Context) ([]string, error) {
-	names := make([]string, len(p.Scripts))
-	for i, script := range p.Scripts {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, len(p.scripts))
+	for i, script := range p.scripts {
 		names[i] = script.GetName()
 	}
 	return names, nil
 }
 
-// ListReferences 列出所有参考文档名称
+// ListReferences 列出所有参考文档名称（调用时刻的快照）
 func (p *InlineProvider) ListReferences(ctx context.Context) ([]string, error) {
-	names := make([]string, len(p.References))
-	for i, ref := range p.References {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, len(p.references))
+	for i, ref := range p.references {
 		names[i] = ref.Name
 	}
 	return names, nil
 }
 
-// ListAssets 列出所有资源文件名称
+// ListAssets 列出所有资源文件名称（调用时刻的快照）
 func (p *InlineProvider) ListAssets(ctx context.Context) ([]string, error) {
-	names := make([]string, len(p.Assets))
-	for i, asset := range p.Assets {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, len(p.assets))
+	for i, asset := range p.assets {
 		names[i] = asset.Name
 	}
 	return names, nil
 }
 
+// Scripts 返回所有脚本的副本
+func (p *InlineProvider) Scripts() []Script {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]Script(nil), p.scripts...)
+}
+
+// References 返回所有参考文档的副本
+func (p *InlineProvider) References() []*Reference {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*Reference(nil), p.references...)
+}
+
+// Assets 返回所有资源文件的副本
+func (p *InlineProvider) Assets() []*Asset {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*Asset(nil), p.assets...)
+}
+
 // AddScript 添加脚本到提供者
 func (p *InlineProvider) AddScript(script Script) {
-	p.Scripts = append(p.Scripts, script)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts = append(p.scripts, script)
 }
 
 // AddReference 添加参考文档到提供者
 func (p *InlineProvider) AddReference(ref *Reference) {
-	p.References = append(p.References, ref)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.references = append(p.references, ref)
 }
 
 // AddAsset 添加资源文件到提供者
 func (p *InlineProvider) AddAsset(asset *Asset) {
-	p.Assets = append(p.Assets, asset)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.assets = append(p.assets, asset)
 }
 
 // Ensure InlineProvider implements ResourceProvider
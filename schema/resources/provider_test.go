@@ -2,7 +2,13 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestInlineProvider(t *testing.T) {
@@ -101,6 +107,57 @@ func TestInlineProvider_NotFound(t *testing.T) {
 	}
 }
 
+func TestInlineProvider_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	provider := NewInlineProvider()
+
+	const writers, perWriter = 4, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				name := fmt.Sprintf("script_%d_%d", w, i)
+				provider.AddScript(NewEasyScript(name, func(ctx context.Context, input map[string]interface{}) (string, error) {
+					return name, nil
+				}))
+			}
+		}(w)
+	}
+
+	// 并发读取，快照在返回后不再变化
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			names, _ := provider.ListScripts(ctx)
+			snapshot := append([]string(nil), names...)
+			for _, name := range names {
+				if _, err := provider.GetScript(ctx, name); err != nil {
+					t.Errorf("Expected listed script %s to be found: %v", name, err)
+				}
+			}
+			for j := range names {
+				if names[j] != snapshot[j] {
+					t.Errorf("Expected stable snapshot, %s changed to %s", snapshot[j], names[j])
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-done
+
+	names, _ := provider.ListScripts(ctx)
+	if len(names) != writers*perWriter {
+		t.Errorf("Expected %d scripts, got %d", writers*perWriter, len(names))
+	}
+	if len(provider.Scripts()) != writers*perWriter {
+		t.Errorf("Expected Scripts() to return %d scripts, got %d", writers*perWriter, len(provider.Scripts()))
+	}
+}
+
 func TestCompositeProvider(t *testing.T) {
 	ctx := context.Background()
 
@@ -148,6 +205,50 @@ func TestCompositeProvider(t *testing.T) {
 	}
 }
 
+// failingListProvider List 方法总是失败的提供者
+type failingListProvider struct {
+	*InlineProvider
+}
+
+func (p *failingListProvider) ListScripts(ctx context.Context) ([]string, error) {
+	return nil, errors.New("list scripts failed")
+}
+
+func (p *failingListProvider) ListReferences(ctx context.Context) ([]string, error) {
+	return nil, errors.New("list references failed")
+}
+
+func TestCompositeProvider_ListWithErrors(t *testing.T) {
+	ctx := context.Background()
+	healthy := NewInlineProvider()
+	healthy.AddScript(NewEasyScript("ok", func(ctx context.Context, input map[string]interface{}) (string, error) {
+		return "ok", nil
+	}))
+	healthy.AddAsset(&Asset{Name: "logo.png"})
+
+	composite := NewCompositeProvider(healthy, &failingListProvider{NewInlineProvider()})
+
+	names, errs := composite.ListScriptsWithErrors(ctx)
+	if len(names) != 1 || names[0] != "ok" {
+		t.Errorf("Expected [ok], got %v", names)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "provider 1") {
+		t.Errorf("Expected one error attributed to provider 1, got %v", errs)
+	}
+
+	// 原有 ListScripts 仍然静默跳过错误
+	if names, err := composite.ListScripts(ctx); err != nil || len(names) != 1 {
+		t.Errorf("Expected silent partial list, got %v (%v)", names, err)
+	}
+
+	if _, errs := composite.ListReferencesWithErrors(ctx); len(errs) != 1 {
+		t.Errorf("Expected one reference error, got %v", errs)
+	}
+	if names, errs := composite.ListAssetsWithErrors(ctx); len(errs) != 0 || len(names) != 1 {
+		t.Errorf("Expected assets [logo.png] without errors, got %v (%v)", names, errs)
+	}
+}
+
 func TestCompositeProvider_Priority(t *testing.T) {
 	ctx := context.Background()
 
@@ -182,6 +283,92 @@ func TestCompositeProvider_Priority(t *testing.T) {
 	}
 }
 
+// slowProvider 查找时等待 ctx 结束后才返回未找到
+type slowProvider struct {
+	*InlineProvider
+}
+
+func (p *slowProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
+}
+
+func (p *slowProvider) GetReference(ctx context.Context, name string) (string, error) {
+	<-ctx.Done()
+	return "", fmt.Errorf("%w: %s", ErrReferenceNotFound, name)
+}
+
+func (p *slowProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+}
+
+// countingProvider 记录查找次数
+type countingProvider struct {
+	*InlineProvider
+	calls int
+}
+
+func (p *countingProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	p.calls++
+	return p.InlineProvider.GetScript(ctx, name)
+}
+
+func (p *countingProvider) GetReference(ctx context.Context, name string) (string, error) {
+	p.calls++
+	return p.InlineProvider.GetReference(ctx, name)
+}
+
+func (p *countingProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	p.calls++
+	return p.InlineProvider.GetAsset(ctx, name)
+}
+
+func TestCompositeProvider_Cancellation(t *testing.T) {
+	second := &countingProvider{InlineProvider: NewInlineProvider()}
+	second.AddScript(NewEasyScript("script", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return input, nil
+	}))
+	second.AddReference(&Reference{Name: "ref", Body: "body"})
+	second.AddAsset(&Asset{Name: "asset"})
+	composite := NewCompositeProvider(&slowProvider{NewInlineProvider()}, second)
+
+	// 第一个提供者查找期间 ctx 被取消，不再询问第二个提供者
+	lookups := map[string]func(ctx context.Context) error{
+		"script": func(ctx context.Context) error {
+			_, err := composite.GetScript(ctx, "script")
+			return err
+		},
+		"reference": func(ctx context.Context) error {
+			_, err := composite.GetReference(ctx, "ref")
+			return err
+		},
+		"asset": func(ctx context.Context) error {
+			_, err := composite.GetAsset(ctx, "asset")
+			return err
+		},
+	}
+	for kind, lookup := range lookups {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if err := lookup(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled for %s, got %v", kind, err)
+		}
+		if second.calls != 0 {
+			t.Errorf("Expected second provider not to be consulted for %s, got %d calls", kind, second.calls)
+		}
+	}
+
+	// 未取消时行为不变
+	composite = NewCompositeProvider(NewInlineProvider(), second)
+	if _, err := composite.GetScript(context.Background(), "script"); err != nil {
+		t.Errorf("Expected script from second provider, got %v", err)
+	}
+	if second.calls != 1 {
+		t.Errorf("Expected second provider to be consulted once, got %d", second.calls)
+	}
+}
+
 func TestCachingProvider(t *testing.T) {
 	ctx := context.Background()
 
@@ -283,3 +470,199 @@ func TestLazyLoadingProvider(t *testing.T) {
 		t.Errorf("Expected still 1 load, got %d", loadCount)
 	}
 }
+
+func TestFallbackProvider(t *testing.T) {
+	ctx := context.Background()
+
+	inner := NewInlineProvider()
+	inner.AddScript(NewEasyScript("known", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"from": "inner"}, nil
+	}))
+	inner.AddReference(&Reference{Name: "guide", Body: "Guide content"})
+
+	unknown := NewEasyScript("unknown_tool", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		requested, _ := RequestedScriptName(ctx)
+		return map[string]interface{}{"from": "fallback", "requested": requested}, nil
+	})
+
+	provider := NewFallbackProvider(inner, unknown)
+
+	// 已存在的脚本由内部提供者返回
+	script, err := provider.GetScript(ctx, "known")
+	if err != nil {
+		t.Fatalf("Failed to get known script: %v", err)
+	}
+	result, err := script.Run(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("Failed to run known script: %v", err)
+	}
+	if result != `{"from":"inner"}` {
+		t.Errorf("Expected inner result, got %s", result)
+	}
+
+	// 缺失的脚本由默认脚本处理
+	script, err = provider.GetScript(ctx, "missing")
+	if err != nil {
+		t.Fatalf("Expected fallback script, got error: %v", err)
+	}
+	if script.GetName() != "missing" {
+		t.Errorf("Expected fallback script name 'missing', got '%s'", script.GetName())
+	}
+	result, err = script.Run(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("Failed to run fallback script: %v", err)
+	}
+	if result != `{"from":"fallback","requested":"missing"}` {
+		t.Errorf("Unexpected fallback result: %s", result)
+	}
+
+	// 参考文档和资源文件不做兜底
+	if _, err := provider.GetReference(ctx, "missing"); err == nil {
+		t.Error("Expected error for missing reference")
+	}
+	if _, err := provider.GetAsset(ctx, "missing"); err == nil {
+		t.Error("Expected error for missing asset")
+	}
+
+	// ListScripts 不包含兜底脚本
+	names, err := provider.ListScripts(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list scripts: %v", err)
+	}
+	if len(names) != 1 || names[0] != "known" {
+		t.Errorf("Expected ['known'], got %v", names)
+	}
+}
+
+func TestFallbackProvider_InnerErrors(t *testing.T) {
+	ctx := context.Background()
+	loadErr := errors.New("git fetch failed")
+	inner := NewLazyLoadingProvider(func(ctx context.Context) (ResourceProvider, error) {
+		return nil, loadErr
+	})
+	defaultScript := NewEasyScript("catch_all", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return input, nil
+	})
+
+	// 非"未找到"的错误原样返回，不使用默认脚本
+	provider := NewFallbackProvider(inner, defaultScript)
+	if script, err := provider.GetScript(ctx, "known"); !errors.Is(err, loadErr) {
+		t.Errorf("Expected inner error, got %v (%v)", script, err)
+	}
+}
+
+func TestWithProviderMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	inner := NewInlineProvider()
+	inner.AddScript(NewEasyScript("script1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}))
+	inner.AddReference(&Reference{Name: "ref1", Body: "Reference content"})
+	inner.AddAsset(&Asset{Name: "asset1", Bytes: []byte("data")})
+
+	counts := make(map[ProviderOp]int)
+	var order []string
+	counting := func(next ProviderHandler) ProviderHandler {
+		return func(ctx context.Context, call ProviderCall) (interface{}, error) {
+			counts[call.Op]++
+			order = append(order, "outer")
+			return next(ctx, call)
+		}
+	}
+	tracing := func(next ProviderHandler) ProviderHandler {
+		return func(ctx context.Context, call ProviderCall) (interface{}, error) {
+			order = append(order, "inner")
+			return next(ctx, call)
+		}
+	}
+
+	// 底层使用 CachingProvider，验证可以组合
+	provider := WithProviderMiddleware(NewCachingProvider(inner), counting, tracing)
+
+	if _, err := provider.GetScript(ctx, "script1"); err != nil {
+		t.Fatalf("Failed to get script: %v", err)
+	}
+	if ref, err := provider.GetReference(ctx, "ref1"); err != nil || ref != "Reference content" {
+		t.Fatalf("Failed to get reference: %v, %s", err, ref)
+	}
+	if asset, err := provider.GetAsset(ctx, "asset1"); err != nil || string(asset.Bytes) != "data" {
+		t.Fatalf("Failed to get asset: %v", err)
+	}
+	if names, err := provider.ListScripts(ctx); err != nil || len(names) != 1 {
+		t.Fatalf("Failed to list scripts: %v, %v", err, names)
+	}
+
+	// 错误原样传递
+	_, err := provider.GetReference(ctx, "missing")
+	if err == nil || err.Error() != "reference not found: missing" {
+		t.Errorf("Expected unchanged not-found error, got %v", err)
+	}
+
+	if counts[OpGetScript] != 1 || counts[OpGetReference] != 2 || counts[OpGetAsset] != 1 || counts[OpListScripts] != 1 {
+		t.Errorf("Unexpected call counts: %v", counts)
+	}
+
+	// 第一个中间件位于最外层
+	if len(order) < 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Expected outer middleware first, got %v", order)
+	}
+}
+
+func TestRecordingAndReplayProvider(t *testing.T) {
+	ctx := context.Background()
+	inline := NewInlineProvider()
+	inline.AddReference(&Reference{Name: "guide", Body: "# Guide"})
+	inline.AddAsset(&Asset{Name: "logo", Bytes: []byte{1, 2, 3}, Ext: PNG})
+	inline.AddScript(NewEasyScript("echo", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return input, nil
+	}))
+
+	recorder := NewRecordingProvider(inline)
+	recorder.GetReference(ctx, "guide")
+	recorder.GetAsset(ctx, "logo")
+	recorder.GetReference(ctx, "missing")
+	script, err := recorder.GetScript(ctx, "echo")
+	if err != nil {
+		t.Fatalf("GetScript failed: %v", err)
+	}
+	live, _ := script.Run(ctx, `{"a":1}`)
+
+	// 记录可以序列化后回放
+	data, err := json.Marshal(recorder.Transcript())
+	if err != nil {
+		t.Fatalf("Marshal transcript failed: %v", err)
+	}
+	var transcript Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("Unmarshal transcript failed: %v", err)
+	}
+	replay := NewReplayProvider(&transcript)
+
+	if body, err := replay.GetReference(ctx, "guide"); err != nil || body != "# Guide" {
+		t.Errorf("Expected replayed reference, got %q (%v)", body, err)
+	}
+	asset, err := replay.GetAsset(ctx, "logo")
+	if err != nil || string(asset.Bytes) != "\x01\x02\x03" || asset.Ext != PNG {
+		t.Errorf("Expected replayed asset, got %v (%v)", asset, err)
+	}
+	replayed, err := replay.GetScript(ctx, "echo")
+	if err != nil {
+		t.Fatalf("GetScript failed: %v", err)
+	}
+	if result, err := replayed.Run(ctx, `{"a":1}`); err != nil || result != live {
+		t.Errorf("Expected replayed result %s, got %s (%v)", live, result, err)
+	}
+
+	// 记录中的解析失败按原样回放
+	if _, err := replay.GetReference(ctx, "missing"); err == nil || errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected recorded not-found error, got %v", err)
+	}
+	// 未记录的资源和调用返回 ErrNotRecorded
+	if _, err := replay.GetReference(ctx, "other"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded, got %v", err)
+	}
+	if _, err := replayed.Run(ctx, `{"a":2}`); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded for unrecorded args, got %v", err)
+	}
+}
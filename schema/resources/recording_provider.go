@@ -0,0 +1,266 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrNotRecorded 回放时请求的资源或脚本调用不在记录中
+var ErrNotRecorded = errors.New("not recorded in transcript")
+
+// Transcript 资源访问记录，可序列化为 JSON
+// 脚本无法序列化，只记录名称、使用说明和每次调用的参数与结果；参考文档和资源文件记录内容
+type Transcript struct {
+	Scripts    map[string]*RecordedScript `json:"scripts,omitempty"`
+	References map[string]string          `json:"references,omitempty"`
+	Assets     map[string]*Asset          `json:"assets,omitempty"`
+	// Errors 解析失败的记录，键为 "<kind>/<name>"（kind 为 script、reference 或 asset）
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// RecordedScript 记录的脚本
+type RecordedScript struct {
+	Name  string                   `json:"name"`
+	Usage string                   `json:"usage"`
+	Calls map[string]*RecordedCall `json:"calls,omitempty"` // args -> 结果
+}
+
+// RecordedCall 记录的脚本调用结果
+type RecordedCall struct {
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// newTranscript 创建空的记录
+func newTranscript() *Transcript {
+	return &Transcript{
+		Scripts:    make(map[string]*RecordedScript),
+		References: make(map[string]string),
+		Assets:     make(map[string]*Asset),
+		Errors:     make(map[string]string),
+	}
+}
+
+// transcriptKey 生成 Errors 的键
+func transcriptKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// RecordingProvider 记录资源访问的提供者装饰器
+// 记录 GetScript / GetReference / GetAsset 的结果，以及通过它获取的脚本的每次调用
+type RecordingProvider struct {
+	inner ResourceProvider
+
+	mu         sync.Mutex
+	transcript *Transcript
+}
+
+// NewRecordingProvider 创建一个新的记录提供者
+func NewRecordingProvider(inner ResourceProvider) *RecordingProvider {
+	return &RecordingProvider{
+		inner:      inner,
+		transcript: newTranscript(),
+	}
+}
+
+// Transcript 返回当前记录的独立副本
+func (p *RecordingProvider) Transcript() *Transcript {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 通过 JSON 往返做深拷贝，同时保证记录确实可序列化
+	data, _ := json.Marshal(p.transcript)
+	copied := newTranscript()
+	json.Unmarshal(data, copied)
+	return copied
+}
+
+// GetScript 获取脚本并记录，返回的脚本在执行时记录参数和结果
+// 原脚本实现 BinaryScript 时，返回的脚本同样实现 BinaryScript；RunBytes 的调用不记录
+func (p *RecordingProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	script, err := p.inner.GetScript(ctx, name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.transcript.Errors[transcriptKey("script", name)] = err.Error()
+		return nil, err
+	}
+	if _, ok := p.transcript.Scripts[name]; !ok {
+		p.transcript.Scripts[name] = &RecordedScript{
+			Name:  name,
+			Usage: script.GetUsage(),
+			Calls: make(map[string]*RecordedCall),
+		}
+	}
+	return withBinary(&recordingScript{Script: script, provider: p}, script), nil
+}
+
+// GetReference 获取参考文档并记录
+func (p *RecordingProvider) GetReference(ctx context.Context, name string) (string, error) {
+	body, err := p.inner.GetReference(ctx, name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.transcript.Errors[transcriptKey("reference", name)] = err.Error()
+		return "", err
+	}
+	p.transcript.References[name] = body
+	return body, nil
+}
+
+// GetAsset 获取资源文件并记录
+func (p *RecordingProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	asset, err := p.inner.GetAsset(ctx, name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.transcript.Errors[transcriptKey("asset", name)] = err.Error()
+		return nil, err
+	}
+	p.transcript.Assets[name] = asset
+	return asset, nil
+}
+
+// ListScripts 委托给内部提供者，不记录
+func (p *RecordingProvider) ListScripts(ctx context.Context) ([]string, error) {
+	return p.inner.ListScripts(ctx)
+}
+
+// ListReferences 委托给内部提供者，不记录
+func (p *RecordingProvider) ListReferences(ctx context.Context) ([]string, error) {
+	return p.inner.ListReferences(ctx)
+}
+
+// ListAssets 委托给内部提供者，不记录
+func (p *RecordingProvider) ListAssets(ctx context.Context) ([]string, error) {
+	return p.inner.ListAssets(ctx)
+}
+
+// recordingScript 记录每次调用的脚本包装
+type recordingScript struct {
+	Script
+	provider *RecordingProvider
+}
+
+// Run 执行脚本并记录参数和结果
+func (s *recordingScript) Run(ctx context.Context, args string) (string, error) {
+	result, err := s.Script.Run(ctx, args)
+
+	call := &RecordedCall{Result: result}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	s.provider.mu.Lock()
+	s.provider.transcript.Scripts[s.GetName()].Calls[args] = call
+	s.provider.mu.Unlock()
+
+	return result, err
+}
+
+// ReplayProvider 按记录回放资源访问的提供者，不需要内部提供者
+// 记录中没有的资源或脚本调用返回可通过 errors.Is 判断的 ErrNotRecorded
+type ReplayProvider struct {
+	transcript *Transcript
+}
+
+// NewReplayProvider 创建一个新的回放提供者
+func NewReplayProvider(transcript *Transcript) *ReplayProvider {
+	if transcript == nil {
+		transcript = newTranscript()
+	}
+	return &ReplayProvider{transcript: transcript}
+}
+
+// GetScript 返回按记录回放调用结果的脚本
+func (p *ReplayProvider) GetScript(ctx context.Context, name string) (Script, error) {
+	if script, ok := p.transcript.Scripts[name]; ok {
+		return &replayScript{recorded: script}, nil
+	}
+	return nil, p.missing("script", name)
+}
+
+// GetReference 返回记录的参考文档
+func (p *ReplayProvider) GetReference(ctx context.Context, name string) (string, error) {
+	if body, ok := p.transcript.References[name]; ok {
+		return body, nil
+	}
+	return "", p.missing("reference", name)
+}
+
+// GetAsset 返回记录的资源文件
+func (p *ReplayProvider) GetAsset(ctx context.Context, name string) (*Asset, error) {
+	if asset, ok := p.transcript.Assets[name]; ok {
+		return asset, nil
+	}
+	return nil, p.missing("asset", name)
+}
+
+// ListScripts 列出记录中的脚本名称（已排序）
+func (p *ReplayProvider) ListScripts(ctx context.Context) ([]string, error) {
+	return sortedKeys(p.transcript.Scripts), nil
+}
+
+// ListReferences 列出记录中的参考文档名称（已排序）
+func (p *ReplayProvider) ListReferences(ctx context.Context) ([]string, error) {
+	return sortedKeys(p.transcript.References), nil
+}
+
+// ListAssets 列出记录中的资源文件名称（已排序）
+func (p *ReplayProvider) ListAssets(ctx context.Context) ([]string, error) {
+	return sortedKeys(p.transcript.Assets), nil
+}
+
+// missing 返回记录的解析错误；没有记录时返回 ErrNotRecorded
+func (p *ReplayProvider) missing(kind, name string) error {
+	if msg, ok := p.transcript.Errors[transcriptKey(kind, name)]; ok {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%w: %s %s", ErrNotRecorded, kind, name)
+}
+
+// replayScript 按记录回放调用结果的脚本
+type replayScript struct {
+	recorded *RecordedScript
+}
+
+func (s *replayScript) Run(ctx context.Context, args string) (string, error) {
+	call, ok := s.recorded.Calls[args]
+	if !ok {
+		return "", fmt.Errorf("%w: script %s with args %s", ErrNotRecorded, s.recorded.Name, args)
+	}
+	if call.Error != "" {
+		return call.Result, errors.New(call.Error)
+	}
+	return call.Result, nil
+}
+
+func (s *replayScript) GetName() string {
+	return s.recorded.Name
+}
+
+func (s *replayScript) GetUsage() string {
+	return s.recorded.Usage
+}
+
+// sortedKeys 返回 map 的键（已排序）
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Ensure RecordingProvider and ReplayProvider implement ResourceProvider
+var (
+	_ ResourceProvider = (*RecordingProvider)(nil)
+	_ ResourceProvider = (*ReplayProvider)(nil)
+)
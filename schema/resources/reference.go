@@ -1,8 +1,67 @@
 package resources
 
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// 参考文档格式
+const (
+	ReferenceFormatMarkdown = "markdown"
+	ReferenceFormatHTML     = "html"
+	ReferenceFormatText     = "text"
+)
+
 type Reference struct {
-	Name string `json:"name"`
-	Body string `json:"body"`
+	Name   string `json:"name"`
+	Body   string `json:"body"`
+	Format string `json:"format,omitempty"` // 内容格式，为空时视为 markdown
+
+	// lazy 延迟加载的内容，由 NewLazyReference 设置；为 nil 时使用 Body
+	lazy *lazyBody
+}
+
+// ReferenceLoader 生成参考文档内容的函数
+type ReferenceLoader func(ctx context.Context) (string, error)
+
+// lazyBody 加载成功后缓存的参考文档内容
+type lazyBody struct {
+	mu     sync.Mutex
+	loader ReferenceLoader
+	loaded bool
+	body   string
+}
+
+// NewLazyReference 创建一个延迟加载的参考文档，内容在第一次通过 Load 读取时由 loader 生成并缓存
+// 只缓存成功的结果（并发安全，同一时刻最多一个 loader 在执行）；失败或 ctx 取消时不缓存，下次 Load 重试。
+// 复制得到的 Reference 共享同一份缓存。
+// Body 保持为空，String 和 Summary 不会触发加载
+func NewLazyReference(name string, loader ReferenceLoader) *Reference {
+	return &Reference{Name: name, lazy: &lazyBody{loader: loader}}
+}
+
+// IsLazy 判断参考文档是否由 NewLazyReference 创建
+func (r *Reference) IsLazy() bool {
+	return r.lazy != nil
+}
+
+// Load 返回参考文档内容；延迟加载的参考文档在第一次成功加载前每次调用都执行 loader，普通参考文档直接返回 Body
+func (r *Reference) Load(ctx context.Context) (string, error) {
+	if r.lazy == nil {
+		return r.Body, nil
+	}
+	r.lazy.mu.Lock()
+	defer r.lazy.mu.Unlock()
+	if r.lazy.loaded {
+		return r.lazy.body, nil
+	}
+	body, err := r.lazy.loader(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load reference %s: %w", r.Name, err)
+	}
+	r.lazy.body, r.lazy.loaded = body, true
+	return body, nil
 }
 
 // String returns the reference content
@@ -14,3 +73,11 @@ func (r *Reference) String() string {
 func (r *Reference) Summary() string {
 	return r.Body
 }
+
+// GetFormat returns the content format, defaulting to markdown when unset
+func (r *Reference) GetFormat() string {
+	if r.Format == "" {
+		return ReferenceFormatMarkdown
+	}
+	return r.Format
+}
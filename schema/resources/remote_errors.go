@@ -0,0 +1,41 @@
+package resources
+
+import "fmt"
+
+// RemoteTransportError 远程调用的传输层错误（网络错误、超时等）
+type RemoteTransportError struct {
+	Op  string // 失败的操作，如 "read response body"，为空时为 "execute request"
+	Err error
+}
+
+func (e *RemoteTransportError) Error() string {
+	op := e.Op
+	if op == "" {
+		op = "execute request"
+	}
+	return fmt.Sprintf("failed to %s: %v", op, e.Err)
+}
+
+// Unwrap 返回底层的网络错误，便于使用 errors.Is 判断 context.DeadlineExceeded 等
+func (e *RemoteTransportError) Unwrap() error {
+	return e.Err
+}
+
+// RemoteStatusError 远程服务返回了非 200 状态码
+type RemoteStatusError struct {
+	Code int
+	Body string
+}
+
+func (e *RemoteStatusError) Error() string {
+	return fmt.Sprintf("remote script returned error: status=%d, body=%s", e.Code, e.Body)
+}
+
+// RemoteScriptError 远程脚本执行成功返回，但响应中携带了逻辑错误
+type RemoteScriptError struct {
+	Message string
+}
+
+func (e *RemoteScriptError) Error() string {
+	return e.Message
+}
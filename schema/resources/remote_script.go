@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,6 +29,30 @@ type RemoteScript struct {
 	Name   string
 	Usage  string
 	Client RemoteScriptClient
+
+	// schemaClient / schemaPath 由 WithSchemaEndpoint 设置
+	schemaClient *HTTPRemoteScriptClient
+	schemaPath   string
+
+	// schemaMu 保护以下懒加载的 schema 缓存
+	schemaMu      sync.Mutex
+	schemaFetched bool
+	schema        *ScriptSchema
+	schemaErr     error
+}
+
+// ScriptSchema 脚本的输入输出 JSON Schema
+type ScriptSchema struct {
+	Input  json.RawMessage `json:"input"`
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// ErrNoSchema 脚本没有可用的 schema
+var ErrNoSchema = errors.New("schema endpoint not configured")
+
+// SchemaProvider 可以提供输入输出 JSON Schema 的脚本
+type SchemaProvider interface {
+	Schema(ctx context.Context) (*ScriptSchema, error)
 }
 
 // Run 执行远程脚本
@@ -42,9 +68,60 @@ func (s *RemoteScript) GetName() string {
 	return s.Name
 }
 
+// schemaUsageTimeout GetUsage 拉取 schema 的超时时间
+const schemaUsageTimeout = 5 * time.Second
+
 // GetUsage 获取脚本使用说明
+// 设置了 schema 端点时首次调用会拉取 schema（最多等待 schemaUsageTimeout）并附加到说明中，拉取失败时返回原始说明
 func (s *RemoteScript) GetUsage() string {
-	return s.Usage
+	s.schemaMu.Lock()
+	configured := s.schemaClient != nil
+	s.schemaMu.Unlock()
+	if !configured {
+		return s.Usage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), schemaUsageTimeout)
+	defer cancel()
+	schema, err := s.Schema(ctx)
+	if err != nil || len(schema.Input) == 0 {
+		return s.Usage
+	}
+	return fmt.Sprintf("%s\nInput schema: %s", s.Usage, schema.Input)
+}
+
+// WithSchemaEndpoint 设置 schema 端点，首次访问 schema 时请求 GET <BaseURL>/<path>/<name>/schema
+// 结果在脚本生命周期内缓存；ctx 取消或超时、远程服务不可用（见 IsRemoteUnavailable）等暂时性失败不缓存，下次访问会重试
+func (s *RemoteScript) WithSchemaEndpoint(client *HTTPRemoteScriptClient, path string) *RemoteScript {
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	s.schemaClient = client
+	s.schemaPath = path
+	s.schemaFetched = false
+	s.schema = nil
+	s.schemaErr = nil
+	return s
+}
+
+// Schema 返回远程服务声明的输入输出 schema，未设置 schema 端点时返回 ErrNoSchema
+func (s *RemoteScript) Schema(ctx context.Context) (*ScriptSchema, error) {
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+
+	if s.schemaClient == nil {
+		return nil, fmt.Errorf("%w for remote script: %s", ErrNoSchema, s.Name)
+	}
+	if s.schemaFetched {
+		return s.schema, s.schemaErr
+	}
+
+	schema, err := s.schemaClient.fetchSchema(ctx, s.schemaPath, s.Name)
+	if err != nil && (ctx.Err() != nil || IsRemoteUnavailable(err)) {
+		return nil, err
+	}
+	s.schemaFetched = true
+	s.schema, s.schemaErr = schema, err
+	return schema, err
 }
 
 // NewRemoteScript 创建一个新的远程脚本
@@ -62,14 +139,46 @@ func (s *RemoteScript) WithUsage(usage string) *RemoteScript {
 	return s
 }
 
-// Ensure RemoteScript implements Script
-var _ Script = (*RemoteScript)(nil)
+// HealthChecker 可以检查后端是否可用的脚本或远程脚本客户端
+type HealthChecker interface {
+	// HealthCheck 后端可用时返回 nil，应当足够轻量以便频繁调用
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheck 检查远程后端是否可用
+// Client 实现了 HealthChecker 时委托给它，否则视为可用
+func (s *RemoteScript) HealthCheck(ctx context.Context) error {
+	if s.Client == nil {
+		return errors.New("remote script client not configured")
+	}
+	if checker, ok := s.Client.(HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// Ensure RemoteScript implements Script, SchemaProvider and HealthChecker
+var (
+	_ Script         = (*RemoteScript)(nil)
+	_ SchemaProvider = (*RemoteScript)(nil)
+	_ HealthChecker  = (*RemoteScript)(nil)
+)
 
 // HTTPRemoteScriptClient 基于 HTTP 的远程脚本客户端
 type HTTPRemoteScriptClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Headers    map[string]string
+
+	// ContentType 调用请求的 Content-Type，为空时为 application/json；按原样发送（可带 charset）
+	ContentType string
+	// Accept 请求的 Accept 头，为空时不发送
+	Accept string
+
+	// RequestTransform 自定义请求体，为空时发送 ScriptCallRequest
+	RequestTransform func(scriptName, args string) ([]byte, error)
+	// ResponseTransform 在标准 ScriptCallResponse 解析之前转换响应体，为空时不转换
+	ResponseTransform func(raw []byte) (string, error)
 }
 
 // HTTPClientOption HTTP 客户端配置选项
@@ -107,6 +216,21 @@ func WithHeader(key, value string) HTTPClientOption {
 	}
 }
 
+// WithContentType 设置调用请求的 Content-Type（如 "application/json; charset=utf-8"）
+// 在自定义请求头之前设置，WithHeader 设置的 Content-Type 优先
+func WithContentType(ct string) HTTPClientOption {
+	return func(c *HTTPRemoteScriptClient) {
+		c.ContentType = ct
+	}
+}
+
+// WithAccept 设置请求的 Accept 头，WithHeader 设置的 Accept 优先
+func WithAccept(accept string) HTTPClientOption {
+	return func(c *HTTPRemoteScriptClient) {
+		c.Accept = accept
+	}
+}
+
 // WithHTTPClient 设置自定义 HTTP 客户端
 func WithHTTPClient(httpClient *http.Client) HTTPClientOption {
 	return func(c *HTTPRemoteScriptClient) {
@@ -114,6 +238,21 @@ func WithHTTPClient(httpClient *http.Client) HTTPClientOption {
 	}
 }
 
+// WithRequestTransform 设置请求体转换函数，用于适配非标准的远程服务
+func WithRequestTransform(fn func(scriptName, args string) ([]byte, error)) HTTPClientOption {
+	return func(c *HTTPRemoteScriptClient) {
+		c.RequestTransform = fn
+	}
+}
+
+// WithResponseTransform 设置响应体转换函数（如拆解 {"data":...} 信封）
+// 转换结果继续按 ScriptCallResponse 解析；fn 返回的错误作为 *RemoteScriptError 返回
+func WithResponseTransform(fn func(raw []byte) (string, error)) HTTPClientOption {
+	return func(c *HTTPRemoteScriptClient) {
+		c.ResponseTransform = fn
+	}
+}
+
 // ScriptCallRequest HTTP 脚本调用请求
 type ScriptCallRequest struct {
 	ScriptName string `json:"script_name"`
@@ -127,15 +266,12 @@ type ScriptCallResponse struct {
 }
 
 // Call 通过 HTTP 调用远程脚本
+// 传输失败返回 *RemoteTransportError，非 200 状态码返回 *RemoteStatusError，
+// 响应中的逻辑错误返回 *RemoteScriptError
 func (c *HTTPRemoteScriptClient) Call(ctx context.Context, scriptName string, args string) (string, error) {
-	reqBody := ScriptCallRequest{
-		ScriptName: scriptName,
-		Args:       args,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
+	jsonData, err := c.requestBody(scriptName, args)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
 	url := fmt.Sprintf("%s/%s", c.BaseURL, scriptName)
@@ -144,30 +280,44 @@ func (c *HTTPRemoteScriptClient) Call(ctx context.Context, scriptName string, ar
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	for key, value := range c.Headers {
-		req.Header.Set(key, value)
+	contentType := c.ContentType
+	if contentType == "" {
+		contentType = "application/json"
 	}
+	req.Header.Set("Content-Type", contentType)
+	c.setHeaders(req)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
+		return "", &RemoteTransportError{Err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", &RemoteTransportError{Op: "read response body", Err: err}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("remote script returned error: status=%d, body=%s", resp.StatusCode, string(body))
+		return "", &RemoteStatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	if c.ResponseTransform != nil {
+		transformed, err := c.ResponseTransform(body)
+		if err != nil {
+			var scriptErr *RemoteScriptError
+			if errors.As(err, &scriptErr) {
+				return "", err
+			}
+			return "", &RemoteScriptError{Message: err.Error()}
+		}
+		body = []byte(transformed)
 	}
 
 	// 尝试解析为 ScriptCallResponse
 	var callResp ScriptCallResponse
 	if err := json.Unmarshal(body, &callResp); err == nil && callResp.Error != "" {
-		return "", errors.New(callResp.Error)
+		return "", &RemoteScriptError{Message: callResp.Error}
 	}
 
 	// 如果解析失败或没有 error 字段，直接返回 body 作为结果
@@ -178,6 +328,71 @@ func (c *HTTPRemoteScriptClient) Call(ctx context.Context, scriptName string, ar
 	return string(body), nil
 }
 
+// requestBody 生成请求体，设置了 RequestTransform 时使用自定义格式
+func (c *HTTPRemoteScriptClient) requestBody(scriptName string, args string) ([]byte, error) {
+	if c.RequestTransform != nil {
+		data, err := c.RequestTransform(scriptName, args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform request: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := json.Marshal(ScriptCallRequest{
+		ScriptName: scriptName,
+		Args:       args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return data, nil
+}
+
+// setHeaders 设置 Accept 和自定义请求头，自定义请求头覆盖同名的默认值
+func (c *HTTPRemoteScriptClient) setHeaders(req *http.Request) {
+	if c.Accept != "" {
+		req.Header.Set("Accept", c.Accept)
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// fetchSchema 请求 GET <BaseURL>/<path>/<scriptName>/schema 并解析为 ScriptSchema
+func (c *HTTPRemoteScriptClient) fetchSchema(ctx context.Context, path string, scriptName string) (*ScriptSchema, error) {
+	parts := []string{strings.TrimRight(c.BaseURL, "/")}
+	if path = strings.Trim(path, "/"); path != "" {
+		parts = append(parts, path)
+	}
+	url := strings.Join(append(parts, scriptName, "schema"), "/")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &RemoteTransportError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &RemoteTransportError{Op: "read response body", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &RemoteStatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	var schema ScriptSchema
+	if err := json.Unmarshal(body, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &schema, nil
+}
+
 // Ensure HTTPRemoteScriptClient implements RemoteScriptClient
 var _ RemoteScriptClient = (*HTTPRemoteScriptClient)(nil)
 
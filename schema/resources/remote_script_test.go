@@ -3,9 +3,12 @@ package resources
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -310,3 +313,305 @@ func TestRemoteScriptIntegration(t *testing.T) {
 		t.Errorf("Expected '{\"message\":\"hello\"}', got '%s'", result)
 	}
 }
+
+func TestHTTPRemoteScriptClient_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// 500 -> RemoteStatusError
+	statusServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer statusServer.Close()
+
+	_, err := NewHTTPRemoteScriptClient(statusServer.URL).Call(ctx, "test", `{}`)
+	var statusErr *RemoteStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected RemoteStatusError, got %T: %v", err, err)
+	}
+	if statusErr.Code != http.StatusInternalServerError || statusErr.Body != "boom" {
+		t.Errorf("Unexpected status error: %+v", statusErr)
+	}
+
+	// 超时 -> RemoteTransportError
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowServer.Close()
+
+	_, err = NewHTTPRemoteScriptClient(slowServer.URL, WithTimeout(10*time.Millisecond)).Call(ctx, "test", `{}`)
+	var transportErr *RemoteTransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("Expected RemoteTransportError, got %T: %v", err, err)
+	}
+	if errors.As(err, &statusErr) {
+		t.Error("Transport error should not be a RemoteStatusError")
+	}
+
+	// 逻辑错误 -> RemoteScriptError
+	logicServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ScriptCallResponse{Error: "invalid input"})
+	}))
+	defer logicServer.Close()
+
+	_, err = NewHTTPRemoteScriptClient(logicServer.URL).Call(ctx, "test", `{}`)
+	var scriptErr *RemoteScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("Expected RemoteScriptError, got %T: %v", err, err)
+	}
+	if scriptErr.Message != "invalid input" {
+		t.Errorf("Expected message 'invalid input', got '%s'", scriptErr.Message)
+	}
+	if errors.As(err, &transportErr) {
+		t.Error("Script error should not be a RemoteTransportError")
+	}
+}
+
+func TestHTTPRemoteScriptClient_ReadBodyError(t *testing.T) {
+	// 响应体比 Content-Length 短，读取失败
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPRemoteScriptClient(server.URL).Call(context.Background(), "test", `{}`)
+	var transportErr *RemoteTransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("Expected RemoteTransportError, got %T: %v", err, err)
+	}
+	// 上下文只出现一次
+	message := err.Error()
+	if strings.Count(message, "failed to") != 1 || !strings.HasPrefix(message, "failed to read response body: ") {
+		t.Errorf("Expected single read response body context, got %q", message)
+	}
+}
+
+func TestHTTPRemoteScriptClient_Transforms(t *testing.T) {
+	// 旧服务：请求体为 {"name":...,"params":...}，响应为 {"data":...,"meta":...}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name   string          `json:"name"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Name == "fail" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": nil, "meta": map[string]string{"error": "quota exceeded"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"script": req.Name, "params": req.Params},
+			"meta": map[string]string{"version": "1"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewHTTPRemoteScriptClient(server.URL,
+		WithRequestTransform(func(scriptName, args string) ([]byte, error) {
+			return json.Marshal(map[string]interface{}{"name": scriptName, "params": json.RawMessage(args)})
+		}),
+		WithResponseTransform(func(raw []byte) (string, error) {
+			var envelope struct {
+				Data json.RawMessage   `json:"data"`
+				Meta map[string]string `json:"meta"`
+			}
+			if err := json.Unmarshal(raw, &envelope); err != nil {
+				return "", err
+			}
+			if msg := envelope.Meta["error"]; msg != "" {
+				return "", errors.New(msg)
+			}
+			return string(envelope.Data), nil
+		}),
+	)
+
+	result, err := client.Call(context.Background(), "echo", `{"x":1}`)
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if result != `{"params":{"x":1},"script":"echo"}` {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// 转换函数返回的错误作为逻辑错误返回
+	_, err = client.Call(context.Background(), "fail", `{}`)
+	var scriptErr *RemoteScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Message != "quota exceeded" {
+		t.Errorf("Expected RemoteScriptError 'quota exceeded', got %v", err)
+	}
+}
+
+func TestRemoteScript_WithSchemaEndpoint(t *testing.T) {
+	var schemaRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/scripts/calc/schema" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&schemaRequests, 1)
+		w.Write([]byte(`{"input":{"type":"object","properties":{"a":{"type":"number"}}},"output":{"type":"number"}}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPRemoteScriptClient(server.URL)
+	script := NewRemoteScript("calc", client).WithSchemaEndpoint(client, "/api/scripts/")
+
+	// 懒加载：创建时不请求
+	if atomic.LoadInt32(&schemaRequests) != 0 {
+		t.Error("Expected schema to be fetched lazily")
+	}
+
+	schema, err := script.Schema(context.Background())
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	if string(schema.Input) != `{"type":"object","properties":{"a":{"type":"number"}}}` {
+		t.Errorf("Unexpected input schema: %s", schema.Input)
+	}
+	if string(schema.Output) != `{"type":"number"}` {
+		t.Errorf("Unexpected output schema: %s", schema.Output)
+	}
+	if usage := script.GetUsage(); !strings.Contains(usage, `"a":{"type":"number"}`) {
+		t.Errorf("Expected usage to advertise schema, got %s", usage)
+	}
+	if n := atomic.LoadInt32(&schemaRequests); n != 1 {
+		t.Errorf("Expected schema to be fetched once, got %d", n)
+	}
+
+	// 拉取失败时退化为原始说明
+	missing := NewRemoteScript("missing", client).WithSchemaEndpoint(client, "api/scripts")
+	if usage := missing.GetUsage(); usage != "Remote script: missing" {
+		t.Errorf("Expected generic usage on failure, got %s", usage)
+	}
+
+	// 取消的 context 导致的失败不缓存
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	retry := NewRemoteScript("calc", client).WithSchemaEndpoint(client, "api/scripts")
+	if _, err := retry.Schema(ctx); err == nil {
+		t.Error("Expected error for canceled context")
+	}
+	if _, err := retry.Schema(context.Background()); err != nil {
+		t.Errorf("Expected retry after canceled context to succeed, got %v", err)
+	}
+}
+
+func TestRemoteScript_SchemaTransientErrorNotCached(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"input":{"type":"object"}}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPRemoteScriptClient(server.URL)
+	script := NewRemoteScript("calc", client).WithSchemaEndpoint(client, "api/scripts")
+
+	// 5xx 属于暂时性失败，不缓存
+	if usage := script.GetUsage(); usage != "Remote script: calc" {
+		t.Errorf("Expected generic usage while schema endpoint is down, got %s", usage)
+	}
+	fail.Store(false)
+	if usage := script.GetUsage(); !strings.Contains(usage, `{"type":"object"}`) {
+		t.Errorf("Expected schema after endpoint recovered, got %s", usage)
+	}
+}
+
+func TestResilientScript(t *testing.T) {
+	ctx := context.Background()
+
+	local := NewEasyScript("convert", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"source": "local"}, nil
+	})
+
+	// 远程服务不可用：降级到本地脚本
+	var mode atomic.Value
+	mode.Store("down")
+	client := NewMockRemoteScriptClient()
+	client.Register("convert", func(ctx context.Context, args string) (string, error) {
+		switch mode.Load() {
+		case "down":
+			return "", &RemoteTransportError{Err: context.DeadlineExceeded}
+		case "unavailable":
+			return "", &RemoteStatusError{Code: http.StatusServiceUnavailable}
+		case "logical":
+			return "", &RemoteScriptError{Message: "invalid unit"}
+		}
+		return `{"source":"remote"}`, nil
+	})
+	script := NewResilientScript(NewRemoteScript("convert", client), local, nil)
+	if script.GetName() != "convert" {
+		t.Errorf("Expected name from primary, got %s", script.GetName())
+	}
+
+	result, err := script.Run(ctx, `{}`)
+	if err != nil || result != `{"source":"local"}` {
+		t.Errorf("Expected local fallback result, got %s (%v)", result, err)
+	}
+	mode.Store("unavailable")
+	if result, err := script.Run(ctx, `{}`); err != nil || result != `{"source":"local"}` {
+		t.Errorf("Expected local fallback for 503, got %s (%v)", result, err)
+	}
+
+	// 逻辑错误不降级
+	mode.Store("logical")
+	var scriptErr *RemoteScriptError
+	if _, err := script.Run(ctx, `{}`); !errors.As(err, &scriptErr) {
+		t.Errorf("Expected RemoteScriptError without fallback, got %v", err)
+	}
+
+	// 远程可用时使用远程结果
+	mode.Store("up")
+	if result, _ := script.Run(ctx, `{}`); result != `{"source":"remote"}` {
+		t.Errorf("Expected remote result, got %s", result)
+	}
+
+	// 调用方 ctx 已结束时不降级
+	mode.Store("down")
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := script.Run(cancelled, `{}`); !IsRemoteUnavailable(err) {
+		t.Errorf("Expected primary error for cancelled context, got %v", err)
+	}
+}
+
+func TestHTTPRemoteScriptClient_ContentType(t *testing.T) {
+	var contentType, accept atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType.Store(r.Header.Get("Content-Type"))
+		accept.Store(r.Header.Get("Accept"))
+		json.NewEncoder(w).Encode(ScriptCallResponse{Result: "ok"})
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// 默认不变
+	if _, err := NewHTTPRemoteScriptClient(server.URL).Call(ctx, "s", `{}`); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if contentType.Load() != "application/json" || accept.Load() != "" {
+		t.Errorf("Expected default application/json without Accept, got %v / %v", contentType.Load(), accept.Load())
+	}
+
+	// 带 charset 的 Content-Type 原样发送
+	client := NewHTTPRemoteScriptClient(server.URL, WithContentType("application/json; charset=utf-8"), WithAccept("application/json"))
+	if _, err := client.Call(ctx, "s", `{}`); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if contentType.Load() != "application/json; charset=utf-8" || accept.Load() != "application/json" {
+		t.Errorf("Expected configured headers, got %v / %v", contentType.Load(), accept.Load())
+	}
+
+	// WithHeader 优先
+	client = NewHTTPRemoteScriptClient(server.URL, WithContentType("application/json; charset=utf-8"), WithHeader("Content-Type", "text/plain"))
+	client.Call(ctx, "s", `{}`)
+	if contentType.Load() != "text/plain" {
+		t.Errorf("Expected WithHeader to override, got %v", contentType.Load())
+	}
+}
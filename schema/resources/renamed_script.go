@@ -0,0 +1,34 @@
+package resources
+
+import "context"
+
+// RenameScript 返回以 name 为名称的脚本，执行和使用说明委托给原脚本
+// 原脚本实现 BinaryScript 时，返回的脚本同样实现 BinaryScript
+func RenameScript(script Script, name string) Script {
+	if binary, ok := script.(BinaryScript); ok {
+		return &renamedBinaryScript{renamedScript: renamedScript{Script: script, name: name}, binary: binary}
+	}
+	return &renamedScript{Script: script, name: name}
+}
+
+// renamedScript 重命名后的脚本
+type renamedScript struct {
+	Script
+	name string
+}
+
+// GetName 返回新名称
+func (s *renamedScript) GetName() string {
+	return s.name
+}
+
+// renamedBinaryScript 重命名后的二进制脚本
+type renamedBinaryScript struct {
+	renamedScript
+	binary BinaryScript
+}
+
+// RunBytes 委托给原脚本
+func (s *renamedBinaryScript) RunBytes(ctx context.Context, args string) ([]byte, string, error) {
+	return s.binary.RunBytes(ctx, args)
+}
@@ -0,0 +1,69 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// NewResilientScript 返回优先执行 primary、在 primary 不可用时降级到 fallback 的脚本
+// primary 返回错误且 shouldFallback(err) 为 true 时执行 fallback 并原样返回其结果；否则返回 primary 的错误。
+// shouldFallback 为 nil 时使用 IsRemoteUnavailable，只在传输错误、超时和 5xx 时降级，逻辑错误不降级。
+// 调用方的 ctx 已取消或超时时不降级（fallback 同样无法在已结束的 ctx 中执行）。
+// 名称和使用说明取自 primary。primary 实现 BinaryScript 时，返回的脚本同样实现 BinaryScript
+func NewResilientScript(primary Script, fallback Script, shouldFallback func(error) bool) Script {
+	if shouldFallback == nil {
+		shouldFallback = IsRemoteUnavailable
+	}
+	script := &resilientScript{Script: primary, fallback: fallback, shouldFallback: shouldFallback}
+	if binary, ok := primary.(BinaryScript); ok {
+		return &resilientBinaryScript{resilientScript: script, binary: binary}
+	}
+	return script
+}
+
+// IsRemoteUnavailable 判断错误是否表示远程服务不可用：*RemoteTransportError（网络错误、超时）
+// 或 5xx 的 *RemoteStatusError。*RemoteScriptError 和 4xx 属于逻辑错误，返回 false
+func IsRemoteUnavailable(err error) bool {
+	var transportErr *RemoteTransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var statusErr *RemoteStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// resilientScript 带降级的脚本
+type resilientScript struct {
+	Script
+	fallback       Script
+	shouldFallback func(error) bool
+}
+
+// Run 执行 primary，失败且满足条件时执行 fallback
+func (s *resilientScript) Run(ctx context.Context, args string) (string, error) {
+	result, err := s.Script.Run(ctx, args)
+	if err == nil || ctx.Err() != nil || !s.shouldFallback(err) {
+		return result, err
+	}
+	return s.fallback.Run(ctx, args)
+}
+
+// resilientBinaryScript primary 为二进制脚本时的降级脚本
+type resilientBinaryScript struct {
+	*resilientScript
+	binary BinaryScript
+}
+
+// RunBytes 执行 primary 的 RunBytes，失败且满足条件时执行 fallback
+// fallback 不是 BinaryScript 时将其 Run 的结果作为 application/json 返回
+func (s *resilientBinaryScript) RunBytes(ctx context.Context, args string) ([]byte, string, error) {
+	data, contentType, err := s.binary.RunBytes(ctx, args)
+	if err == nil || ctx.Err() != nil || !s.shouldFallback(err) {
+		return data, contentType, err
+	}
+	return runBytes(ctx, s.fallback, args)
+}
@@ -0,0 +1,139 @@
+package resources
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultResultCacheSize ResultCachingScript 默认缓存的最大条目数
+const DefaultResultCacheSize = 1024
+
+// ResultCachingScript 缓存脚本执行结果的装饰器
+// 以完整的 args 字符串为键，TTL 内相同参数的调用直接返回缓存结果；错误不缓存。
+// 超过最大条目数时淘汰最久未使用的条目
+type ResultCachingScript struct {
+	inner      Script
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // 最近使用的在前
+}
+
+// resultCacheEntry 缓存条目
+type resultCacheEntry struct {
+	args      string
+	result    string
+	expiresAt time.Time
+}
+
+// NewResultCachingScript 创建一个缓存执行结果的脚本
+func NewResultCachingScript(inner Script, ttl time.Duration) *ResultCachingScript {
+	return &ResultCachingScript{
+		inner:      inner,
+		ttl:        ttl,
+		maxEntries: DefaultResultCacheSize,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// WithMaxEntries 设置最大缓存条目数，n <= 0 时使用 DefaultResultCacheSize
+func (s *ResultCachingScript) WithMaxEntries(n int) *ResultCachingScript {
+	if n <= 0 {
+		n = DefaultResultCacheSize
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxEntries = n
+	for s.lru.Len() > s.maxEntries {
+		s.removeElement(s.lru.Back())
+	}
+	return s
+}
+
+// Run 返回缓存的结果，未命中或已过期时执行内部脚本
+func (s *ResultCachingScript) Run(ctx context.Context, args string) (string, error) {
+	if result, ok := s.get(args); ok {
+		return result, nil
+	}
+
+	result, err := s.inner.Run(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	s.put(args, result)
+	return result, nil
+}
+
+// RunBytes 执行内部脚本并返回原始字节，结果不缓存
+// 内部脚本实现 BinaryScript 时委托给其 RunBytes，否则将 Run 的结果作为 application/json 返回
+func (s *ResultCachingScript) RunBytes(ctx context.Context, args string) ([]byte, string, error) {
+	return runBytes(ctx, s.inner, args)
+}
+
+// GetName 获取脚本名称
+func (s *ResultCachingScript) GetName() string {
+	return s.inner.GetName()
+}
+
+// GetUsage 获取脚本使用说明
+func (s *ResultCachingScript) GetUsage() string {
+	return s.inner.GetUsage()
+}
+
+// ClearCache 清除所有缓存的结果
+func (s *ResultCachingScript) ClearCache() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]*list.Element)
+	s.lru.Init()
+}
+
+func (s *ResultCachingScript) get(args string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[args]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*resultCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		s.removeElement(elem)
+		return "", false
+	}
+	s.lru.MoveToFront(elem)
+	return entry.result, true
+}
+
+func (s *ResultCachingScript) put(args, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := time.Now().Add(s.ttl)
+	if elem, ok := s.entries[args]; ok {
+		entry := elem.Value.(*resultCacheEntry)
+		entry.result = result
+		entry.expiresAt = expiresAt
+		s.lru.MoveToFront(elem)
+		return
+	}
+
+	s.entries[args] = s.lru.PushFront(&resultCacheEntry{args: args, result: result, expiresAt: expiresAt})
+	for s.lru.Len() > s.maxEntries {
+		s.removeElement(s.lru.Back())
+	}
+}
+
+func (s *ResultCachingScript) removeElement(elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.entries, elem.Value.(*resultCacheEntry).args)
+}
+
+// Ensure ResultCachingScript implements Script and BinaryScript
+var _ Script = (*ResultCachingScript)(nil)
+var _ BinaryScript = (*ResultCachingScript)(nil)
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/alois132/skill/util"
 )
@@ -23,15 +25,146 @@ type EasyScript[I, O any] struct {
 	Name  string `json:"name"`
 	Usage string `json:"usage"`
 	Fn    ScriptFunc[I, O]
+
+	// Coerce 开启后，参数中以字符串编码的数字和布尔值会按 I 的字段类型转换后再反序列化
+	Coerce bool `json:"-"`
+	// numericKeys I 为 map[string]interface{} 时必须为数字的参数，由 WithNumericKeys 设置
+	numericKeys []string
+
+	// pool 复用输入实例，由 WithInputPool 开启
+	pool *sync.Pool
+
+	// example 示例参数，由 WithExample 设置，附加到自动生成的使用说明中
+	example string
+	// exampleErr 最近一次 WithExample 的校验错误，由 Validate 报告
+	exampleErr error
+	// usageGenerated 表示 Usage 由 GetUsage 自动生成（而非显式设置）
+	usageGenerated bool
+}
+
+// DecodedScript 可直接消费共享参数的脚本
+// 同一份参数被多个脚本使用时（如 AutoExecute），调用方只创建一个 DecodedArgs，
+// 输入为 JSON 对象的脚本共享同一次解析的结果，各自得到独立的副本
+type DecodedScript interface {
+	Script
+	// RunDecoded 与 Run 语义相同，参数由 args 提供
+	RunDecoded(ctx context.Context, args *DecodedArgs) (result string, err error)
+}
+
+// ValidatableScript 可以报告自身配置问题的脚本，Skill.Validate 会检查实现此接口的内联脚本
+type ValidatableScript interface {
+	Script
+	// Validate 返回脚本的配置错误，无问题时返回 nil
+	Validate() error
+}
+
+// DecodedArgs 在多个脚本间共享的参数，JSON 对象形式只在首次需要时解析一次
+type DecodedArgs struct {
+	raw []byte
+
+	once sync.Once
+	obj  map[string]interface{}
+	err  error
+}
+
+// NewDecodedArgs 创建共享参数
+func NewDecodedArgs(args string) *DecodedArgs {
+	return &DecodedArgs{raw: []byte(args)}
+}
+
+// Raw 返回原始 JSON 参数，调用方不得修改
+func (a *DecodedArgs) Raw() []byte {
+	return a.raw
+}
+
+// Object 返回参数解析出的 JSON 对象的独立深拷贝，修改它不会影响其他脚本
+// 解析只进行一次；结果与 json.Unmarshal 到 map[string]interface{} 一致（"null" 得到 nil）
+func (a *DecodedArgs) Object() (map[string]interface{}, error) {
+	a.once.Do(func() {
+		a.err = json.Unmarshal(a.raw, &a.obj)
+	})
+	if a.err != nil {
+		return nil, a.err
+	}
+	if a.obj == nil {
+		return nil, nil
+	}
+	return copyJSONValue(a.obj).(map[string]interface{}), nil
+}
+
+// copyJSONValue 深拷贝 json.Unmarshal 得到的动态值
+func copyJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = copyJSONValue(item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = copyJSONValue(item)
+		}
+		return items
+	default:
+		return v
+	}
 }
 
 func (s *EasyScript[I, O]) Run(ctx context.Context, args string) (result string, err error) {
-	input := util.NewInstance[I]()
-	err = json.Unmarshal([]byte(args), &input)
-	if err != nil {
-		return "", err
+	if s.Coerce {
+		args, err = coerceArgs[I](args, s.numericKeys)
+		if err != nil {
+			return "", err
+		}
+	}
+	return s.run(ctx, []byte(args))
+}
+
+// RunDecoded 使用共享参数执行脚本
+// 输入类型为 map[string]interface{} 时直接使用共享解析结果的副本，不再反序列化；
+// 其他输入类型从原始 JSON 反序列化；开启 Coerce 时回退到 Run
+func (s *EasyScript[I, O]) RunDecoded(ctx context.Context, args *DecodedArgs) (result string, err error) {
+	if s.Coerce {
+		return s.Run(ctx, string(args.Raw()))
+	}
+	var input I
+	if _, ok := any(input).(map[string]interface{}); ok && s.pool == nil {
+		obj, err := args.Object()
+		if err != nil {
+			return "", err
+		}
+		return s.call(ctx, any(obj).(I))
 	}
+	return s.run(ctx, args.Raw())
+}
 
+// run 将 data 反序列化为新的（或池中复用的）输入实例并执行 Fn
+func (s *EasyScript[I, O]) run(ctx context.Context, data []byte) (result string, err error) {
+	var input I
+	if s.pool != nil {
+		p := s.pool.Get().(*I)
+		defer func() {
+			resetInput(p)
+			s.pool.Put(p)
+		}()
+		if err = json.Unmarshal(data, p); err != nil {
+			return "", err
+		}
+		input = *p
+	} else {
+		input = util.NewInstance[I]()
+		err = json.Unmarshal(data, &input)
+		if err != nil {
+			return "", err
+		}
+	}
+	return s.call(ctx, input)
+}
+
+// call 执行 Fn 并序列化结果
+func (s *EasyScript[I, O]) call(ctx context.Context, input I) (result string, err error) {
 	output, err := s.Fn(ctx, input)
 	if err != nil {
 		return "", err
@@ -61,9 +194,26 @@ func (s *EasyScript[I, O]) GetUsage() string {
 
 	// 生成使用说明
 	s.Usage = fmt.Sprintf("Input: %s, Output: %s", inType.String(), outType.String())
+	if s.example != "" {
+		s.Usage += ", Example: " + s.example
+	}
+	s.usageGenerated = true
 	return s.Usage
 }
 
+// Schema 返回由输入输出类型反射生成的 JSON Schema
+func (s *EasyScript[I, O]) Schema(ctx context.Context) (*ScriptSchema, error) {
+	input, err := json.Marshal(util.JSONSchema(util.TypeOf[I]()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input schema: %w", err)
+	}
+	output, err := json.Marshal(util.JSONSchema(util.TypeOf[O]()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output schema: %w", err)
+	}
+	return &ScriptSchema{Input: input, Output: output}, nil
+}
+
 // NewEasyScript creates a new EasyScript with the given name and function
 func NewEasyScript[I, O any](name string, fn ScriptFunc[I, O]) *EasyScript[I, O] {
 	return &EasyScript[I, O]{
@@ -72,12 +222,125 @@ func NewEasyScript[I, O any](name string, fn ScriptFunc[I, O]) *EasyScript[I, O]
 	}
 }
 
+// NewCoercingScript creates a new EasyScript that coerces string-encoded numbers and bools before running
+// e.g. {"a":"10"} is accepted for a float64 field; values that cannot be coerced return an error
+func NewCoercingScript[I, O any](name string, fn ScriptFunc[I, O]) *EasyScript[I, O] {
+	return NewEasyScript(name, fn).WithCoercion()
+}
+
+// WithCoercion enables coercion of string-encoded numbers and bools in the script's arguments
+func (s *EasyScript[I, O]) WithCoercion() *EasyScript[I, O] {
+	s.Coerce = true
+	return s
+}
+
+// WithNumericKeys enables coercion and requires the given top-level arguments to be numbers
+// (or strings that coerce to numbers), for map[string]interface{} inputs whose types coercion cannot infer;
+// e.g. with WithNumericKeys("a"), {"a":"ten"} returns an error instead of reaching the script as a string
+func (s *EasyScript[I, O]) WithNumericKeys(keys ...string) *EasyScript[I, O] {
+	s.Coerce = true
+	s.numericKeys = append(s.numericKeys, keys...)
+	return s
+}
+
+// coerceArgs coerces a JSON object's values according to I; non-object args are returned unchanged
+// For map[string]interface{} inputs numericKeys must hold numbers, see util.CoerceNumbers
+func coerceArgs[I any](args string, numericKeys []string) (string, error) {
+	// UseNumber 保留未编码为字符串的数字的原始精度
+	decoder := json.NewDecoder(strings.NewReader(args))
+	decoder.UseNumber()
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil || raw == nil || decoder.More() {
+		return args, nil
+	}
+	var err error
+	t := util.TypeOf[I]()
+	if t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Interface {
+		err = util.CoerceNumbers(raw, numericKeys...)
+	} else {
+		err = util.CoerceTo(raw, t)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid args: %w", err)
+	}
+	coerced, err := json.Marshal(raw)
+	if err != nil {
+		return "", err
+	}
+	return string(coerced), nil
+}
+
+// WithInputPool reuses input instances across runs via a sync.Pool to reduce allocations for hot scripts
+// Instances are reset to zero (maps cleared, slices truncated, pointees zeroed) before reuse,
+// so Fn must not retain map, slice or pointer inputs after it returns
+func (s *EasyScript[I, O]) WithInputPool() *EasyScript[I, O] {
+	s.pool = &sync.Pool{
+		New: func() any {
+			input := util.NewInstance[I]()
+			return &input
+		},
+	}
+	return s
+}
+
+// resetInput resets a pooled input to its zero state while keeping allocated maps/slices/pointees
+func resetInput[I any](p *I) {
+	v := reflect.ValueOf(p).Elem()
+	switch v.Kind() {
+	case reflect.Map:
+		if !v.IsNil() {
+			v.Clear()
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			v.SetLen(0)
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		}
+	default:
+		var zero I
+		*p = zero
+	}
+}
+
 // WithUsage sets the usage description for the script
 func (s *EasyScript[I, O]) WithUsage(usage string) *EasyScript[I, O] {
 	s.Usage = usage
+	s.usageGenerated = false
+	return s
+}
+
+// WithExample sets a sample args JSON that is appended to the generated usage as "Example: <args>"
+// The example must be valid JSON that decodes into the input type; an invalid example is not used and its
+// error is reported by Validate. An explicitly set usage still takes precedence
+func (s *EasyScript[I, O]) WithExample(exampleArgs string) *EasyScript[I, O] {
+	input := util.NewInstance[I]()
+	if err := json.Unmarshal([]byte(exampleArgs), &input); err != nil {
+		s.exampleErr = fmt.Errorf("invalid example args for script %s: %w", s.Name, err)
+		return s
+	}
+
+	s.example = exampleArgs
+	s.exampleErr = nil
+	if s.usageGenerated {
+		// 重新生成以包含示例
+		s.Usage = ""
+		s.usageGenerated = false
+	}
 	return s
 }
 
+// Validate 返回 WithExample 记录的示例参数错误，无问题时返回 nil
+func (s *EasyScript[I, O]) Validate() error {
+	return s.exampleErr
+}
+
+// Ensure EasyScript implements DecodedScript
+var _ DecodedScript = (*EasyScript[any, any])(nil)
+var _ ValidatableScript = (*EasyScript[any, any])(nil)
+
 // TypeInfo returns information about the input and output types
 func TypeInfo[I, O any]() (string, string) {
 	inType := util.TypeOf[I]()
@@ -0,0 +1,446 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoercingScript(t *testing.T) {
+	ctx := context.Background()
+
+	type AddInput struct {
+		A float64 `json:"a"`
+		B float64 `json:"b"`
+	}
+	add := func(ctx context.Context, input AddInput) (map[string]interface{}, error) {
+		return map[string]interface{}{"result": input.A + input.B}, nil
+	}
+
+	// 字符串编码的数字被转换
+	result, err := NewCoercingScript("add", add).Run(ctx, `{"a":"10","b":"5"}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result != `{"result":15}` {
+		t.Errorf("Expected {\"result\":15}, got %s", result)
+	}
+
+	// 无法转换的值返回明确的错误
+	_, err = NewCoercingScript("add", add).Run(ctx, `{"a":"ten","b":5}`)
+	if err == nil || !strings.Contains(err.Error(), `cannot coerce "ten"`) {
+		t.Errorf("Expected coercion error, got %v", err)
+	}
+
+	// 未开启时保持严格
+	if _, err := NewEasyScript("add", add).Run(ctx, `{"a":"10","b":"5"}`); err == nil {
+		t.Error("Expected strict script to fail on string numbers")
+	}
+}
+
+func TestCoercingScript_LargeIntegers(t *testing.T) {
+	type Input struct {
+		ID    int64 `json:"id"`
+		Other int64 `json:"other"`
+	}
+	echo := NewCoercingScript("echo", func(ctx context.Context, input Input) (Input, error) {
+		return input, nil
+	})
+
+	// 超过 2^53 的整数（字符串编码或原始数字）都不丢失精度
+	result, err := echo.Run(context.Background(), `{"id":"9007199254740993","other":9007199254740995}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result != `{"id":9007199254740993,"other":9007199254740995}` {
+		t.Errorf("Expected exact integers, got %s", result)
+	}
+}
+
+func TestDecodedArgs(t *testing.T) {
+	args := NewDecodedArgs(`{"n":1,"nested":{"items":[1,2]}}`)
+
+	first, err := args.Object()
+	if err != nil {
+		t.Fatalf("Object failed: %v", err)
+	}
+	first["n"] = 2
+	first["nested"].(map[string]interface{})["items"].([]interface{})[0] = "changed"
+
+	// 只解析一次：之后原始参数的变化不会被再次解析
+	args.raw = []byte("not json")
+	second, err := args.Object()
+	if err != nil {
+		t.Fatalf("Expected cached decode, got %v", err)
+	}
+	// 每次得到独立的深拷贝
+	if second["n"] != 1.0 || second["nested"].(map[string]interface{})["items"].([]interface{})[0] != 1.0 {
+		t.Errorf("Expected independent copy, got %v", second)
+	}
+
+	if obj, err := NewDecodedArgs("null").Object(); err != nil || obj != nil {
+		t.Errorf("Expected nil object for null, got %v (err %v)", obj, err)
+	}
+	if _, err := NewDecodedArgs("{").Object(); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestCoercingScript_MapInput(t *testing.T) {
+	script := NewCoercingScript("add", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		a, _ := input["a"].(float64)
+		b, _ := input["b"].(float64)
+		return map[string]interface{}{"result": a + b}, nil
+	})
+
+	result, err := script.Run(context.Background(), `{"a":"10","b":"5"}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result != `{"result":15}` {
+		t.Errorf("Expected {\"result\":15}, got %s", result)
+	}
+}
+
+func TestCoercingScript_NumericKeys(t *testing.T) {
+	ctx := context.Background()
+	script := NewCoercingScript("add", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		a, _ := input["a"].(float64)
+		b, _ := input["b"].(float64)
+		return map[string]interface{}{"result": a + b}, nil
+	}).WithNumericKeys("a", "b")
+
+	// 标记为数字的参数无法转换时返回错误，而不是以 0 参与计算
+	_, err := script.Run(ctx, `{"a":"ten","b":5}`)
+	if err == nil || !strings.Contains(err.Error(), `field a: cannot coerce "ten" to number`) {
+		t.Errorf("Expected coercion error, got %v", err)
+	}
+
+	result, err := script.Run(ctx, `{"a":"10","b":5}`)
+	if err != nil || result != `{"result":15}` {
+		t.Errorf("Expected {\"result\":15}, got %s (%v)", result, err)
+	}
+}
+
+func TestEasyScript_WithInputPool(t *testing.T) {
+	ctx := context.Background()
+
+	type TimeInput struct {
+		Format   string `json:"format"`
+		Timezone string `json:"timezone"`
+		Layout   string `json:"layout"`
+	}
+	structScript := NewEasyScript("echo", func(ctx context.Context, input TimeInput) (TimeInput, error) {
+		return input, nil
+	}).WithInputPool()
+
+	// 结构体：上一次的字段不会残留
+	if _, err := structScript.Run(ctx, `{"format":"custom","timezone":"UTC","layout":"2006"}`); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	result, err := structScript.Run(ctx, `{"format":"iso"}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result != `{"format":"iso","timezone":"","layout":""}` {
+		t.Errorf("Unexpected result for struct input: %s", result)
+	}
+
+	// map：旧键被清空
+	mapScript := NewEasyScript("keys", func(ctx context.Context, input map[string]interface{}) (int, error) {
+		return len(input), nil
+	}).WithInputPool()
+	mapScript.Run(ctx, `{"a":1,"b":2,"c":3}`)
+	if result, _ := mapScript.Run(ctx, `{"d":4}`); result != "1" {
+		t.Errorf("Expected 1 key after reuse, got %s", result)
+	}
+
+	// slice：长度被重置
+	sliceScript := NewEasyScript("count", func(ctx context.Context, input []int) (int, error) {
+		return len(input), nil
+	}).WithInputPool()
+	sliceScript.Run(ctx, `[1,2,3]`)
+	if result, _ := sliceScript.Run(ctx, `[4]`); result != "1" {
+		t.Errorf("Expected 1 element after reuse, got %s", result)
+	}
+
+	// 指针：被指向的值被清零
+	ptrScript := NewEasyScript("ptr", func(ctx context.Context, input *TimeInput) (TimeInput, error) {
+		return *input, nil
+	}).WithInputPool()
+	ptrScript.Run(ctx, `{"format":"custom","timezone":"UTC"}`)
+	if result, _ := ptrScript.Run(ctx, `{"layout":"15:04"}`); result != `{"format":"","timezone":"","layout":"15:04"}` {
+		t.Errorf("Unexpected result for pointer input: %s", result)
+	}
+}
+
+func benchmarkEasyScript(b *testing.B, pooled bool) {
+	script := NewEasyScript("sum", func(ctx context.Context, input map[string]interface{}) (int, error) {
+		return len(input), nil
+	})
+	if pooled {
+		script.WithInputPool()
+	}
+	ctx := context.Background()
+	args := `{"format":"iso","timezone":"Asia/Shanghai","layout":"2006-01-02"}`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := script.Run(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEasyScript_Run(b *testing.B) {
+	benchmarkEasyScript(b, false)
+}
+
+func BenchmarkEasyScript_RunPooled(b *testing.B) {
+	benchmarkEasyScript(b, true)
+}
+
+func TestEasyScript_WithExample(t *testing.T) {
+	type input struct {
+		City string `json:"city"`
+	}
+	newScript := func() *EasyScript[input, map[string]interface{}] {
+		return NewEasyScript("weather", func(ctx context.Context, in input) (map[string]interface{}, error) {
+			return map[string]interface{}{"city": in.City}, nil
+		})
+	}
+
+	script := newScript().WithExample(`{"city":"Paris"}`)
+	if err := script.Validate(); err != nil {
+		t.Fatalf("WithExample failed: %v", err)
+	}
+	if usage := script.GetUsage(); !strings.HasSuffix(usage, `Example: {"city":"Paris"}`) || !strings.HasPrefix(usage, "Input: ") {
+		t.Errorf("Expected generated usage with example, got %s", usage)
+	}
+
+	// 在生成说明之后设置示例也会生效
+	script = newScript()
+	script.GetUsage()
+	script.WithExample(`{"city":"Rome"}`)
+	if usage := script.GetUsage(); !strings.Contains(usage, `Example: {"city":"Rome"}`) {
+		t.Errorf("Expected regenerated usage with example, got %s", usage)
+	}
+
+	// 显式设置的说明完全覆盖
+	script = newScript().WithUsage("Get weather").WithExample(`{"city":"Paris"}`)
+	if usage := script.GetUsage(); usage != "Get weather" {
+		t.Errorf("Expected explicit usage, got %s", usage)
+	}
+
+	// 无效的示例不会使用，错误由 Validate 报告
+	script = newScript().WithExample(`{city:}`)
+	if err := script.Validate(); err == nil {
+		t.Error("Expected error for invalid example JSON")
+	}
+	if usage := script.GetUsage(); strings.Contains(usage, "Example:") {
+		t.Errorf("Expected invalid example to be left out of usage, got %s", usage)
+	}
+	if err := newScript().WithExample(`{"city":1}`).Validate(); err == nil {
+		t.Error("Expected error for example not matching the input type")
+	}
+
+	// 之后设置有效示例时清除错误
+	if err := newScript().WithExample(`{city:}`).WithExample(`{"city":"Oslo"}`).Validate(); err != nil {
+		t.Errorf("Expected valid example to clear the error, got %v", err)
+	}
+}
+
+func TestResultCachingScript(t *testing.T) {
+	ctx := context.Background()
+	var calls int32
+	counting := NewEasyScript("count", func(ctx context.Context, input map[string]interface{}) (int32, error) {
+		if input["fail"] == true {
+			return 0, errors.New("failed")
+		}
+		return atomic.AddInt32(&calls, 1), nil
+	})
+
+	script := NewResultCachingScript(counting, time.Minute)
+
+	first, err := script.Run(ctx, `{"x":1}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	second, err := script.Run(ctx, `{"x":1}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if first != second || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected cached result, got %s and %s after %d calls", first, second, calls)
+	}
+
+	// 参数字符串不同即视为不同的键
+	if _, err := script.Run(ctx, `{"x": 1}`); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected different args to miss the cache, got %d calls", calls)
+	}
+
+	// 错误不缓存
+	script.Run(ctx, `{"fail":true}`)
+	if _, err := script.Run(ctx, `{"fail":true}`); err == nil {
+		t.Error("Expected error to be returned again")
+	}
+
+	// 容量上限
+	script.WithMaxEntries(1)
+	script.Run(ctx, `{"x":2}`)
+	before := atomic.LoadInt32(&calls)
+	script.Run(ctx, `{"x":1}`)
+	if atomic.LoadInt32(&calls) != before+1 {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+
+	// TTL 过期
+	short := NewResultCachingScript(counting, 10*time.Millisecond)
+	short.Run(ctx, `{"y":1}`)
+	time.Sleep(20 * time.Millisecond)
+	before = atomic.LoadInt32(&calls)
+	short.Run(ctx, `{"y":1}`)
+	if atomic.LoadInt32(&calls) != before+1 {
+		t.Error("Expected expired entry to be re-run")
+	}
+}
+
+func TestWrapScript(t *testing.T) {
+	ctx := context.Background()
+
+	// 前两次调用失败的脚本
+	var attempts int32
+	inner := NewEasyScript("flaky", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return nil, errors.New("temporary failure")
+		}
+		return map[string]interface{}{"ok": true}, nil
+	}).WithUsage("flaky script")
+
+	var order []string
+	var calls int32
+	counting := func(next ScriptRunFunc) ScriptRunFunc {
+		return func(ctx context.Context, args string) (string, error) {
+			order = append(order, "count")
+			atomic.AddInt32(&calls, 1)
+			return next(ctx, args)
+		}
+	}
+	retrying := func(next ScriptRunFunc) ScriptRunFunc {
+		return func(ctx context.Context, args string) (result string, err error) {
+			order = append(order, "retry")
+			for i := 0; i < 3; i++ {
+				if result, err = next(ctx, args); err == nil {
+					return result, nil
+				}
+			}
+			return result, err
+		}
+	}
+
+	script := WrapScript(inner, counting, retrying)
+	if script.GetName() != "flaky" || script.GetUsage() != "flaky script" {
+		t.Errorf("Expected name/usage from inner script, got %s / %s", script.GetName(), script.GetUsage())
+	}
+
+	result, err := script.Run(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result != `{"ok":true}` {
+		t.Errorf(`Expected {"ok":true}, got %s`, result)
+	}
+	// 计数中间件在最外层，只计一次；重试中间件在内层，执行了 3 次
+	if calls != 1 {
+		t.Errorf("Expected 1 outer call, got %d", calls)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if strings.Join(order, ",") != "count,retry" {
+		t.Errorf("Expected order count,retry, got %v", order)
+	}
+}
+
+func TestWrappers_ForwardBinaryScript(t *testing.T) {
+	ctx := context.Background()
+	png := []byte{0x89, 'P', 'N', 'G'}
+	var requested string
+	image := NewBytesScript("render", "image/png", func(ctx context.Context, args string) ([]byte, error) {
+		requested, _ = RequestedScriptName(ctx)
+		return png, nil
+	})
+	text := NewEasyScript("text", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return input, nil
+	})
+	inline := NewInlineProvider()
+	inline.AddScript(image)
+
+	recorded, err := NewRecordingProvider(inline).GetScript(ctx, "render")
+	if err != nil {
+		t.Fatalf("GetScript failed: %v", err)
+	}
+	fallback, err := NewFallbackProvider(nil, image).GetScript(ctx, "chart")
+	if err != nil {
+		t.Fatalf("GetScript failed: %v", err)
+	}
+
+	wrappers := map[string]Script{
+		"wrapped":   WrapScript(image),
+		"recording": recorded,
+		"resilient": NewResilientScript(image, text, nil),
+		"fallback":  fallback,
+		"cached":    NewResultCachingScript(image, time.Minute),
+	}
+	for name, script := range wrappers {
+		binary, ok := script.(BinaryScript)
+		if !ok {
+			t.Errorf("%s: Expected wrapper to implement BinaryScript", name)
+			continue
+		}
+		data, contentType, err := binary.RunBytes(ctx, `{}`)
+		if err != nil || !bytes.Equal(data, png) || contentType != "image/png" {
+			t.Errorf("%s: Expected png bytes, got %v %q (%v)", name, data, contentType, err)
+		}
+	}
+	// 默认脚本的 RunBytes 同样能获取被请求的名称
+	fallback.(BinaryScript).RunBytes(ctx, `{}`)
+	if requested != "chart" {
+		t.Errorf("Expected requested name chart, got %q", requested)
+	}
+
+	// 原脚本不是 BinaryScript 时包装也不是
+	if _, ok := WrapScript(text).(BinaryScript); ok {
+		t.Error("Expected wrapped text script not to implement BinaryScript")
+	}
+	if _, ok := NewResilientScript(text, image, nil).(BinaryScript); ok {
+		t.Error("Expected resilient text script not to implement BinaryScript")
+	}
+}
+
+func TestResilientScript_RunBytesFallback(t *testing.T) {
+	ctx := context.Background()
+	primary := NewBytesScript("render", "image/png", func(ctx context.Context, args string) ([]byte, error) {
+		return nil, errors.New("renderer down")
+	})
+	backup := NewEasyScript("backup", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"fallback": true}, nil
+	})
+
+	script := NewResilientScript(primary, backup, func(error) bool { return true })
+	data, contentType, err := script.(BinaryScript).RunBytes(ctx, `{}`)
+	if err != nil {
+		t.Fatalf("RunBytes failed: %v", err)
+	}
+	if string(data) != `{"fallback":true}` || contentType != "application/json" {
+		t.Errorf("Expected JSON fallback result, got %s %q", data, contentType)
+	}
+}
@@ -0,0 +1,28 @@
+package resources
+
+import "context"
+
+// ScriptRunFunc 脚本的执行函数
+type ScriptRunFunc func(ctx context.Context, args string) (string, error)
+
+// WrapScript 返回以 wrappers 包装 Run 的脚本，名称和使用说明委托给原脚本
+// 第一个 wrapper 位于最外层，最先执行；可用于在构建 Skill 时为单个脚本添加重试、日志等行为。
+// 原脚本实现 BinaryScript 时，返回的脚本同样实现 BinaryScript，RunBytes 直接委托给原脚本（不经过 wrappers）
+func WrapScript(inner Script, wrappers ...func(ScriptRunFunc) ScriptRunFunc) Script {
+	run := ScriptRunFunc(inner.Run)
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		run = wrappers[i](run)
+	}
+	return withBinary(&wrappedScript{Script: inner, run: run}, inner)
+}
+
+// wrappedScript 包装后的脚本
+type wrappedScript struct {
+	Script
+	run ScriptRunFunc
+}
+
+// Run 执行包装后的调用链
+func (s *wrappedScript) Run(ctx context.Context, args string) (string, error) {
+	return s.run(ctx, args)
+}
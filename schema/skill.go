@@ -2,9 +2,17 @@ package schema
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/alois132/skill/constant"
 	"github.com/alois132/skill/schema/resources"
 	"github.com/alois132/skill/util"
 )
@@ -18,33 +26,325 @@ type Skill struct {
 
 	// Provider 资源提供者接口，用于动态获取资源
 	// 如果设置了 Provider，会优先使用 Provider 获取资源
+	//
+	// Deprecated: 直接读写该字段与 UseScript 等并发调用存在数据竞争，请使用 SetProvider / GetProvider
 	Provider resources.ResourceProvider `json:"-"`
 
+	// providerMu 保护 Provider 的并发读写
+	providerMu sync.RWMutex `json:"-"`
+
+	// RecoverPanics 开启后脚本中的 panic 会被恢复并作为 *ScriptPanicError 返回，而不是使进程崩溃
+	// 运行时配置，不参与序列化；通过 core.WithPanicRecovery 或 core.WithScriptPanicRecovery 设置
+	RecoverPanics bool `json:"-"`
+
+	// metadataMu 保护 SetDescription 对 Metadata 的修改，GetName、GetDescription、Glance 和 Clone 在其保护下读取
+	metadataMu sync.RWMutex `json:"-"`
+
 	// 内部缓存字段（不参与序列化）
 	parsedTags []util.XMLTag `json:"-"`
 	parsed     bool          `json:"-"`
 }
 
 type SkillMetadata struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	// Name 同时是 Skill 在 Store 和缓存中的键，构造后不应修改；
+	// 已存储的 Skill 改名请使用 core.RenameSkill 或 SkillManager.RenameSkill，它们写入改名后的副本并同步更新键
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels,omitempty"` // 标签，用于分类和筛选
+
+	// RequiredInputs 脚本期望的输入字段（支持点号分隔的嵌套路径），由 CheckInputs 检查
+	RequiredInputs []string `json:"required_inputs,omitempty"`
+
+	// BodyFormat Body 的标记语法版本（BodyFormatV1 / BodyFormatV2），为空时按 BodyFormatV1 解析
+	BodyFormat string `json:"body_format,omitempty"`
+
+	// AutoExecuteDefaults AutoExecute 时各脚本的默认参数（脚本名 -> args JSON）
+	// 未配置默认参数的脚本使用调用方传入的 args
+	AutoExecuteDefaults map[string]string `json:"auto_execute_defaults,omitempty"`
+
+	// Deleted / DeletedAt 软删除标记，由开启软删除的 SkillStore 在 Delete 时设置
+	Deleted   bool       `json:"deleted,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// UniqueNames 开启后 Validate 将 Scripts、References、Assets 中的重复名称报告为 ErrDuplicateName
+	// CaseInsensitiveNames 开启后比较名称时忽略大小写（同时影响 DuplicateNames）
+	// 两者是构造时的校验选项，不参与序列化，不会出现在 Glance 或 Store 中
+	UniqueNames          bool `json:"-"`
+	CaseInsensitiveNames bool `json:"-"`
+
+	// Examples 脚本调用示例，用于文档和 RunExamples 冒烟测试
+	Examples []Example `json:"examples,omitempty"`
+}
+
+// Clone 深拷贝元数据
+func (metadata *SkillMetadata) Clone() *SkillMetadata {
+	if metadata == nil {
+		return nil
+	}
+	copied := *metadata
+	copied.Labels = copyStringMap(metadata.Labels)
+	copied.AutoExecuteDefaults = copyStringMap(metadata.AutoExecuteDefaults)
+	if metadata.RequiredInputs != nil {
+		copied.RequiredInputs = append([]string(nil), metadata.RequiredInputs...)
+	}
+	if metadata.Examples != nil {
+		copied.Examples = append([]Example(nil), metadata.Examples...)
+	}
+	return &copied
+}
+
+// copyStringMap 拷贝 map，nil 保持为 nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+const (
+	// BodyFormatV1 原始语法：<tag>name</tag>
+	BodyFormatV1 = "v1"
+	// BodyFormatV2 支持属性的语法：<tag key="value">name</tag>
+	BodyFormatV2 = "v2"
+)
+
+// CheckInputs 检查 args 中是否包含 Metadata.RequiredInputs 声明的所有字段
+// 字段支持点号分隔的嵌套路径（如 "options.timezone"），缺失或为 null 的字段按声明顺序返回；
+// args 不是合法的 JSON 对象时返回错误
+func (skill *Skill) CheckInputs(args string) (missing []string, err error) {
+	var input map[string]interface{}
+	if err := json.Unmarshal([]byte(args), &input); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	if skill.Metadata == nil {
+		return nil, nil
+	}
+
+	for _, field := range skill.Metadata.RequiredInputs {
+		if lookupPath(input, field) == nil {
+			missing = append(missing, field)
+		}
+	}
+	return missing, nil
+}
+
+// lookupPath 按点号分隔的路径查找嵌套字段，不存在时返回 nil
+func lookupPath(input map[string]interface{}, path string) interface{} {
+	var current interface{} = input
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// SetProvider 并发安全地设置资源提供者，传入 nil 表示仅使用内联资源
+func (skill *Skill) SetProvider(p resources.ResourceProvider) {
+	skill.providerMu.Lock()
+	defer skill.providerMu.Unlock()
+	skill.Provider = p
+}
+
+// GetProvider 并发安全地获取资源提供者，未设置时返回 nil
+func (skill *Skill) GetProvider() resources.ResourceProvider {
+	skill.providerMu.RLock()
+	defer skill.providerMu.RUnlock()
+	return skill.Provider
+}
+
+// GetName 返回 Skill 名称，Metadata 为 nil 时返回空字符串
+func (skill *Skill) GetName() string {
+	skill.metadataMu.RLock()
+	defer skill.metadataMu.RUnlock()
+	if skill.Metadata == nil {
+		return ""
+	}
+	return skill.Metadata.Name
+}
+
+// GetDescription 返回 Skill 描述，Metadata 为 nil 时返回空字符串
+func (skill *Skill) GetDescription() string {
+	skill.metadataMu.RLock()
+	defer skill.metadataMu.RUnlock()
+	if skill.Metadata == nil {
+		return ""
+	}
+	return skill.Metadata.Description
 }
 
+// SetDescription 更新 Skill 描述，Metadata 为 nil 时自动创建
+// 与 GetDescription、Glance、Clone 并发调用是安全的；直接读取 Metadata.Description 的代码不受保护
+func (skill *Skill) SetDescription(desc string) {
+	skill.metadataMu.Lock()
+	defer skill.metadataMu.Unlock()
+	if skill.Metadata == nil {
+		skill.Metadata = &SkillMetadata{}
+	}
+	skill.Metadata.Description = desc
+}
+
+// Glance 返回 Metadata 的 JSON，Metadata 为 nil 时返回 "{}"
 func (skill *Skill) Glance() (metadata string) {
+	skill.metadataMu.RLock()
+	defer skill.metadataMu.RUnlock()
+	if skill.Metadata == nil {
+		return "{}"
+	}
 	m, _ := json.Marshal(skill.Metadata)
 	return string(m)
 }
 
+// ScriptSummary GlanceRich 中的脚本摘要
+type ScriptSummary struct {
+	Name  string `json:"name"`
+	Usage string `json:"usage,omitempty"` // 无法解析的脚本为空
+}
+
+// RichGlance GlanceRich 返回的内容
+type RichGlance struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Scripts     []ScriptSummary `json:"scripts,omitempty"`
+	References  []string        `json:"references,omitempty"`
+}
+
+// GlanceRich 返回介于 Glance 和 Inspect 之间的 JSON 摘要：名称、描述、Body 中引用的脚本（含使用说明）和参考文档名称，不含 Body
+// 脚本和参考文档按在 Body 中首次出现的顺序去重列出；使用说明经 Provider 解析，无法解析的脚本只列出名称
+func (skill *Skill) GlanceRich(ctx context.Context) (string, error) {
+	glance := RichGlance{
+		Name:        skill.GetName(),
+		Description: skill.GetDescription(),
+		References:  util.UniqueStrings(skill.GetReferenceNames()),
+	}
+	for _, name := range util.UniqueStrings(skill.GetScriptNames()) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		summary := ScriptSummary{Name: name}
+		if script, err := skill.GetScript(ctx, name); err == nil {
+			summary.Usage = script.GetUsage()
+		}
+		glance.Scripts = append(glance.Scripts, summary)
+	}
+
+	data, err := json.Marshal(glance)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal glance: %w", err)
+	}
+	return string(data), nil
+}
+
 func (skill *Skill) Inspect() (body string) {
 	return skill.Body
 }
 
+// TruncatedMarker 截断 body 时追加的标记
+const TruncatedMarker = "…[truncated]"
+
+// InspectTruncated 返回最多 maxChars 个字符（rune）的 body，超出部分以 TruncatedMarker 替代
+// 按 rune 截断，不会产生非法 UTF-8；maxChars <= 0 表示不截断
+func (skill *Skill) InspectTruncated(maxChars int) (body string, truncated bool) {
+	if maxChars <= 0 {
+		return skill.Body, false
+	}
+	count := 0
+	for i := range skill.Body {
+		if count == maxChars {
+			return skill.Body[:i] + TruncatedMarker, true
+		}
+		count++
+	}
+	return skill.Body, false
+}
+
 func (skill *Skill) UseScript(ctx context.Context, name string, args string) (result string, err error) {
+	script, err := skill.resolveScript(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return skill.runScript(ctx, script, args)
+}
+
+// UseScriptAt 执行 Body 中第 index 个（从 0 开始，按出现顺序）<script> 标记对应的脚本
+// index 越界或 Body 中没有脚本标记时返回错误
+func (skill *Skill) UseScriptAt(ctx context.Context, index int, args string) (string, error) {
+	names := skill.GetScriptNames()
+	if len(names) == 0 {
+		return "", errors.New("no script tags in skill body")
+	}
+	if index < 0 || index >= len(names) {
+		return "", fmt.Errorf("script index out of range: %d (body has %d scripts)", index, len(names))
+	}
+	return skill.UseScript(ctx, names[index], args)
+}
+
+// UseScriptBytes 执行脚本并返回原始字节和内容类型
+// 如果脚本实现了 resources.BinaryScript，直接返回其字节；否则将字符串结果作为 application/json 返回
+func (skill *Skill) UseScriptBytes(ctx context.Context, name string, args string) (data []byte, contentType string, err error) {
+	script, err := skill.resolveScript(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if binary, ok := script.(resources.BinaryScript); ok {
+		return skill.runScriptBytes(ctx, script.GetName(), binary, args)
+	}
+
+	result, err := skill.runScript(ctx, script, args)
+	if err != nil {
+		return nil, "", err
+	}
+	return []byte(result), "application/json", nil
+}
+
+// ResolvableScripts 检查 Body 中每个 <script> 标记是否能解析到可执行的脚本（Provider 或内联）
+// 只做解析不执行，按 Body 中的出现顺序返回（重复的名称只出现一次）
+func (skill *Skill) ResolvableScripts(ctx context.Context) (resolvable []string, missing []string, err error) {
+	seen := make(map[string]struct{})
+	for _, name := range skill.GetScriptNames() {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		if err := ctx.Err(); err != nil {
+			return resolvable, missing, err
+		}
+		if _, err := skill.resolveScript(ctx, name); err != nil {
+			missing = append(missing, name)
+		} else {
+			resolvable = append(resolvable, name)
+		}
+	}
+	return resolvable, missing, nil
+}
+
+// GetScript 按 UseScript 的查找顺序（Provider 优先，然后内联）获取脚本，不执行
+func (skill *Skill) GetScript(ctx context.Context, name string) (resources.Script, error) {
+	return skill.resolveScript(ctx, name)
+}
+
+// resolveScript 按 UseScript 的查找顺序解析脚本，不执行
+func (skill *Skill) resolveScript(ctx context.Context, name string) (resources.Script, error) {
+	// 0. context 中的覆盖提供者优先
+	if override, ok := resources.ProviderOverrideFromContext(ctx); ok {
+		if script, err := override.GetScript(ctx, name); err == nil {
+			return script, nil
+		}
+	}
+
 	// 1. 首先尝试从 Provider 获取脚本（如果设置了 Provider）
-	if skill.Provider != nil {
-		script, err := skill.Provider.GetScript(ctx, name)
+	if provider := skill.GetProvider(); provider != nil {
+		script, err := provider.GetScript(ctx, name)
 		if err == nil {
-			return script.Run(ctx, args)
+			return script, nil
 		}
 		// 如果 Provider 返回错误，继续尝试内联脚本
 	}
@@ -52,35 +352,132 @@ func (skill *Skill) UseScript(ctx context.Context, name string, args string) (re
 	// 2. 遍历内联 scripts 查找匹配名称的脚本
 	for _, script := range skill.Scripts {
 		if script.GetName() == name {
-			return script.Run(ctx, args)
+			return script, nil
 		}
 	}
-	return "", errors.New("script not found: " + name)
+	return nil, fmt.Errorf("%w: %s", resources.ErrScriptNotFound, name)
 }
 
 func (skill *Skill) ReadReference(name string) (string, error) {
+	return skill.ReadReferenceContext(context.Background(), name)
+}
+
+// ReadReferenceContext 与 ReadReference 相同，但使用给定的 context
+// context 中通过 resources.WithProviderOverride 设置的提供者优先于 Skill 的 Provider 和内联参考文档
+func (skill *Skill) ReadReferenceContext(ctx context.Context, name string) (string, error) {
+	// 0. context 中的覆盖提供者优先
+	if override, ok := resources.ProviderOverrideFromContext(ctx); ok {
+		if body, err := override.GetReference(ctx, name); err == nil {
+			return body, nil
+		}
+	}
+
 	// 1. 首先尝试从 Provider 获取参考文档（如果设置了 Provider）
-	if skill.Provider != nil {
-		body, err := skill.Provider.GetReference(context.Background(), name)
+	if provider := skill.GetProvider(); provider != nil {
+		body, err := provider.GetReference(ctx, name)
 		if err == nil {
 			return body, nil
 		}
 		// 如果 Provider 返回错误，继续尝试内联参考文档
 	}
 
-	// 2. 遍历内联 references 查找匹配名称的参考文献
+	// 2. 遍历内联 references 查找匹配名称的参考文献，延迟加载的参考文档在此时生成内容
 	for _, ref := range skill.References {
 		if ref.Name == name {
-			return ref.Body, nil
+			return ref.Load(ctx)
+		}
+	}
+	return "", fmt.Errorf("%w: %s", resources.ErrReferenceNotFound, name)
+}
+
+// GetAsset 按 context 覆盖提供者、Provider、内联资源文件的顺序查找资源文件
+func (skill *Skill) GetAsset(ctx context.Context, name string) (*resources.Asset, error) {
+	// 0. context 中的覆盖提供者优先
+	if override, ok := resources.ProviderOverrideFromContext(ctx); ok {
+		if asset, err := override.GetAsset(ctx, name); err == nil {
+			return asset, nil
+		}
+	}
+
+	// 1. 其次从 Provider 获取
+	if provider := skill.GetProvider(); provider != nil {
+		if asset, err := provider.GetAsset(ctx, name); err == nil {
+			return asset, nil
+		}
+	}
+
+	// 2. 最后查找内联资源文件
+	for _, asset := range skill.Assets {
+		if asset.Name == name {
+			return asset, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", resources.ErrAssetNotFound, name)
+}
+
+// AvailableScripts 返回所有可解析的脚本名称：内联脚本与 Provider.ListScripts 的并集
+// 去重、排序和错误处理与 AvailableReferences 相同
+func (skill *Skill) AvailableScripts(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(skill.Scripts))
+	for _, script := range skill.Scripts {
+		names = append(names, script.GetName())
+	}
+	return skill.withProviderNames(ctx, names, "scripts", resources.ResourceProvider.ListScripts)
+}
+
+// AvailableReferences 返回所有可解析的参考文档名称：内联参考文档与 Provider.ListReferences 的并集
+// 与 GetReferenceNames 不同，不依赖 Body 中的标记；结果去重并按名称排序。
+// Provider 列举失败时仍返回内联名称，同时返回包装后的错误
+func (skill *Skill) AvailableReferences(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(skill.References))
+	for _, ref := range skill.References {
+		names = append(names, ref.Name)
+	}
+	return skill.withProviderNames(ctx, names, "references", resources.ResourceProvider.ListReferences)
+}
+
+// AvailableAssets 返回所有可解析的资源文件名称：内联资源文件与 Provider.ListAssets 的并集
+// 去重、排序和错误处理与 AvailableReferences 相同
+func (skill *Skill) AvailableAssets(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(skill.Assets))
+	for _, asset := range skill.Assets {
+		names = append(names, asset.Name)
+	}
+	return skill.withProviderNames(ctx, names, "assets", resources.ResourceProvider.ListAssets)
+}
+
+// withProviderNames 将 Provider 列出的名称并入 names，去重后排序
+func (skill *Skill) withProviderNames(ctx context.Context, names []string, kind string, list func(resources.ResourceProvider, context.Context) ([]string, error)) ([]string, error) {
+	var err error
+	if provider := skill.GetProvider(); provider != nil {
+		providerNames, listErr := list(provider, ctx)
+		if listErr != nil {
+			err = fmt.Errorf("failed to list provider %s: %w", kind, listErr)
+		}
+		names = append(names, providerNames...)
+	}
+
+	seen := make(map[string]struct{}, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
 		}
+		seen[name] = struct{}{}
+		result = append(result, name)
 	}
-	return "", errors.New("reference not found: " + name)
+	sort.Strings(result)
+	return result, err
 }
 
 // ParseXMLTags 解析 Body 中的 XML 标记并缓存
-// 支持格式：\u003cscript\u003ename\u003c/script\u003e, \u003creference\u003ename\u003c/reference\u003e, \u003casset\u003ename\u003c/asset\u003e
+// 按 Metadata.BodyFormat 选择解析规则：v1（默认）支持 \u003cscript\u003ename\u003c/script\u003e 等格式，
+// v2 额外支持属性；未知的 BodyFormat 返回错误
 func (skill *Skill) ParseXMLTags() error {
-	tags := util.ParseXMLTags(skill.Body)
+	tags, err := parseBody(skill.bodyFormat(), skill.Body)
+	if err != nil {
+		return err
+	}
 	skill.parsedTags = tags
 	skill.parsed = true
 	return nil
@@ -96,20 +493,162 @@ func (skill *Skill) GetParsedTags() []util.XMLTag {
 
 // GetScriptNames 获取 Body 中引用的所有脚本名称
 func (skill *Skill) GetScriptNames() []string {
-	return util.ExtractScriptNames(skill.Body)
+	if skill.bodyFormat() == BodyFormatV1 {
+		return util.ExtractScriptNames(skill.Body)
+	}
+	return skill.tagContents(util.TagScript)
 }
 
 // GetReferenceNames 获取 Body 中引用的所有参考文献名称
 func (skill *Skill) GetReferenceNames() []string {
-	return util.ExtractReferenceNames(skill.Body)
+	if skill.bodyFormat() == BodyFormatV1 {
+		return util.ExtractReferenceNames(skill.Body)
+	}
+	return skill.tagContents(util.TagReference)
 }
 
 // GetAssetNames 获取 Body 中引用的所有资产名称
 func (skill *Skill) GetAssetNames() []string {
-	return util.ExtractAssetNames(skill.Body)
+	if skill.bodyFormat() == BodyFormatV1 {
+		return util.ExtractAssetNames(skill.Body)
+	}
+	return skill.tagContents(util.TagAsset)
 }
 
 // HasXMLTags 检查 Body 中是否包含 XML 标记
 func (skill *Skill) HasXMLTags() bool {
-	return util.HasXMLTags(skill.Body)
+	if skill.bodyFormat() == BodyFormatV1 {
+		return util.HasXMLTags(skill.Body)
+	}
+	tags, _ := parseBody(skill.bodyFormat(), skill.Body)
+	return len(tags) > 0
+}
+
+// bodyFormat 返回 Body 的语法版本，未设置时为 BodyFormatV1
+func (skill *Skill) bodyFormat() string {
+	if skill.Metadata == nil || skill.Metadata.BodyFormat == "" {
+		return BodyFormatV1
+	}
+	return skill.Metadata.BodyFormat
+}
+
+// tagContents 按 Body 的语法版本解析并返回指定类型标记的内容，未知版本返回 nil
+func (skill *Skill) tagContents(kind util.TagKind) []string {
+	tags, _ := parseBody(skill.bodyFormat(), skill.Body)
+	var names []string
+	for _, tag := range tags {
+		if tag.Kind() == kind {
+			names = append(names, tag.Content)
+		}
+	}
+	return names
+}
+
+// parseBody 按语法版本解析 Body 中的标记
+func parseBody(format string, body string) ([]util.XMLTag, error) {
+	switch format {
+	case BodyFormatV1:
+		return util.ParseXMLTags(body), nil
+	case BodyFormatV2:
+		return util.ParseXMLTagsV2(body), nil
+	default:
+		return nil, fmt.Errorf("unknown body format: %s", format)
+	}
+}
+
+// AddScriptTag 在 Body 末尾追加一个 <script>name</script> 标记
+func (skill *Skill) AddScriptTag(name string) {
+	skill.appendBody(fmt.Sprintf(constant.ScriptSelfContained, name))
+}
+
+// AddReferenceTag 在 Body 末尾追加一个 <reference>name</reference> 标记
+func (skill *Skill) AddReferenceTag(name string) {
+	skill.appendBody(fmt.Sprintf(constant.ReferenceSelfContained, name))
+}
+
+// AddAssetTag 在 Body 末尾追加一个 <asset>name</asset> 标记
+func (skill *Skill) AddAssetTag(name string) {
+	skill.appendBody(fmt.Sprintf(constant.AssetSelfContained, name))
+}
+
+// appendBody 追加内容到 Body 并使解析缓存失效
+func (skill *Skill) appendBody(text string) {
+	skill.Body += text
+	skill.resetParsedTags()
+}
+
+// RenameTag 将 Body 中的 <kind>oldName</kind> 改写为 <kind>newName</kind>，并同步重命名同名的内联资源
+// kind 为 script、reference 或 asset，只匹配同类标记；带属性的标记（如 <script args='...'>、V2 属性）保留其属性。
+// 返回是否有改动
+func (skill *Skill) RenameTag(kind, oldName, newName string) (bool, error) {
+	if util.ParseTagKind(kind) == util.TagUnknown {
+		return false, errors.New("unknown tag kind: " + kind)
+	}
+	if oldName == newName {
+		return false, nil
+	}
+
+	changed := false
+	if body := util.RenameXMLTags(skill.Body, util.TagKind(kind), oldName, newName); body != skill.Body {
+		skill.Body = body
+		skill.resetParsedTags()
+		changed = true
+	}
+
+	switch util.TagKind(kind) {
+	case util.TagScript:
+		for i, script := range skill.Scripts {
+			if script.GetName() == oldName {
+				skill.Scripts[i] = resources.RenameScript(script, newName)
+				changed = true
+			}
+		}
+	case util.TagReference:
+		// 替换为副本，避免修改与其他 Skill 共享的资源
+		for i, ref := range skill.References {
+			if ref.Name == oldName {
+				renamed := *ref
+				renamed.Name = newName
+				skill.References[i] = &renamed
+				changed = true
+			}
+		}
+	case util.TagAsset:
+		for i, asset := range skill.Assets {
+			if asset.Name == oldName {
+				renamed := *asset
+				renamed.Name = newName
+				skill.Assets[i] = &renamed
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// resetParsedTags 清空解析缓存，下次访问时重新解析
+func (skill *Skill) resetParsedTags() {
+	skill.parsedTags = nil
+	skill.parsed = false
+}
+
+// ContentHash 计算 Skill 持久化内容的 SHA-256 摘要（十六进制）
+// 覆盖元数据、Body、参考文档和资源文件；内联脚本不会被持久化，与 Provider 一样不参与计算
+// 相同内容的 Skill 在不同进程中得到相同的摘要
+func (skill *Skill) ContentHash() string {
+	content := struct {
+		Metadata   *SkillMetadata         `json:"metadata"`
+		Body       string                 `json:"body"`
+		References []*resources.Reference `json:"references"`
+		Assets     []*resources.Asset     `json:"assets"`
+	}{
+		Metadata:   skill.Metadata,
+		Body:       skill.Body,
+		References: skill.References,
+		Assets:     skill.Assets,
+	}
+
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
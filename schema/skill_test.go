@@ -2,7 +2,13 @@ package schema
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/alois132/skill/schema/resources"
 )
@@ -335,3 +341,560 @@ func TestSkill_GlanceAndInspect(t *testing.T) {
 		t.Errorf("Expected 'Test body content', got '%s'", inspect)
 	}
 }
+
+func TestSkill_AddTags(t *testing.T) {
+	skill := &Skill{Body: "第一步："}
+
+	// 先解析一次以填充缓存
+	if len(skill.GetParsedTags()) != 0 {
+		t.Fatalf("Expected no parsed tags, got %v", skill.GetParsedTags())
+	}
+
+	skill.AddScriptTag("init")
+	skill.AddReferenceTag("guide")
+	skill.AddAssetTag("logo.png")
+
+	if skill.Body != "第一步：<script>init</script><reference>guide</reference><asset>logo.png</asset>" {
+		t.Errorf("Unexpected body: %s", skill.Body)
+	}
+
+	tags := skill.GetParsedTags()
+	if len(tags) != 3 {
+		t.Fatalf("Expected 3 parsed tags after adding, got %d", len(tags))
+	}
+	if names := skill.GetScriptNames(); len(names) != 1 || names[0] != "init" {
+		t.Errorf("Expected [init], got %v", names)
+	}
+}
+
+func TestSkill_ContentHash(t *testing.T) {
+	newSkill := func(body string) *Skill {
+		return &Skill{
+			Metadata: &SkillMetadata{Name: "hash_skill", Description: "Hash test"},
+			Body:     body,
+			References: []*resources.Reference{
+				{Name: "guide", Body: "Guide content"},
+			},
+		}
+	}
+
+	a := newSkill("Same body")
+	b := newSkill("Same body")
+	if a.ContentHash() != b.ContentHash() {
+		t.Error("Expected identical skills to have the same hash")
+	}
+
+	c := newSkill("Different body")
+	if a.ContentHash() == c.ContentHash() {
+		t.Error("Expected different bodies to have different hashes")
+	}
+
+	// 内联脚本不会被持久化，不影响摘要
+	b.Scripts = []resources.Script{resources.NewBytesScript("render", "image/png", nil)}
+	if a.ContentHash() != b.ContentHash() {
+		t.Error("Expected inline scripts not to affect the hash")
+	}
+}
+
+func TestSkill_UseScriptBytes(t *testing.T) {
+	ctx := context.Background()
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe}
+
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "binary_skill"},
+		Scripts: []resources.Script{
+			resources.NewBytesScript("render", "image/png", func(ctx context.Context, args string) ([]byte, error) {
+				return png, nil
+			}),
+			resources.NewEasyScript("json_script", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"ok": true}, nil
+			}),
+		},
+	}
+
+	// 二进制脚本
+	data, contentType, err := skill.UseScriptBytes(ctx, "render", `{}`)
+	if err != nil {
+		t.Fatalf("UseScriptBytes failed: %v", err)
+	}
+	if contentType != "image/png" {
+		t.Errorf("Expected content type 'image/png', got '%s'", contentType)
+	}
+	if string(data) != string(png) {
+		t.Errorf("Binary data corrupted: %v", data)
+	}
+
+	// 普通脚本回退为 application/json
+	data, contentType, err = skill.UseScriptBytes(ctx, "json_script", `{}`)
+	if err != nil {
+		t.Fatalf("UseScriptBytes failed: %v", err)
+	}
+	if contentType != "application/json" || string(data) != `{"ok":true}` {
+		t.Errorf("Unexpected fallback result: %s (%s)", data, contentType)
+	}
+
+	// 字符串接口仍然可用
+	if _, err := skill.UseScript(ctx, "render", `{}`); err != nil {
+		t.Errorf("UseScript on binary script failed: %v", err)
+	}
+}
+
+func TestSkill_ResolvableScripts(t *testing.T) {
+	ctx := context.Background()
+
+	provider := resources.NewInlineProvider()
+	provider.AddScript(resources.NewEasyScript("remote", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}))
+
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "resolve_skill"},
+		Body:     "<script>inline</script><script>dangling</script><script>remote</script>",
+		Scripts: []resources.Script{
+			resources.NewEasyScript("inline", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			}),
+		},
+	}
+
+	// 没有 Provider 时只能解析内联脚本
+	resolvable, missing, err := skill.ResolvableScripts(ctx)
+	if err != nil {
+		t.Fatalf("ResolvableScripts failed: %v", err)
+	}
+	if len(resolvable) != 1 || resolvable[0] != "inline" {
+		t.Errorf("Expected [inline] resolvable, got %v", resolvable)
+	}
+	if len(missing) != 2 || missing[0] != "dangling" || missing[1] != "remote" {
+		t.Errorf("Expected [dangling remote] missing, got %v", missing)
+	}
+
+	// 设置 Provider 后，Provider 中的脚本也可以解析
+	skill.SetProvider(provider)
+	resolvable, missing, err = skill.ResolvableScripts(ctx)
+	if err != nil {
+		t.Fatalf("ResolvableScripts failed: %v", err)
+	}
+	if len(resolvable) != 2 || resolvable[1] != "remote" {
+		t.Errorf("Expected [inline remote] resolvable, got %v", resolvable)
+	}
+	if len(missing) != 1 || missing[0] != "dangling" {
+		t.Errorf("Expected [dangling] missing, got %v", missing)
+	}
+}
+
+func TestSkill_InspectTruncated(t *testing.T) {
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "long_skill"},
+		Body:     strings.Repeat("时间", 50),
+	}
+
+	// 默认不截断
+	body, truncated := skill.InspectTruncated(0)
+	if truncated || body != skill.Body {
+		t.Error("Expected no truncation when maxChars <= 0")
+	}
+
+	body, truncated = skill.InspectTruncated(15)
+	if !truncated {
+		t.Error("Expected truncated to be true")
+	}
+	if !utf8.ValidString(body) {
+		t.Error("Expected valid UTF-8 after truncation")
+	}
+	if !strings.HasSuffix(body, TruncatedMarker) {
+		t.Errorf("Expected truncation marker, got %s", body)
+	}
+	if n := utf8.RuneCountInString(strings.TrimSuffix(body, TruncatedMarker)); n != 15 {
+		t.Errorf("Expected 15 runes before marker, got %d", n)
+	}
+
+	// 未超出上限
+	body, truncated = skill.InspectTruncated(100)
+	if truncated || body != skill.Body {
+		t.Error("Expected no truncation when body fits")
+	}
+}
+
+func TestSkill_SetProviderConcurrent(t *testing.T) {
+	ctx := context.Background()
+	newProvider := func(result string) resources.ResourceProvider {
+		p := resources.NewInlineProvider()
+		p.AddScript(resources.NewEasyScript("echo", func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return result, nil
+		}))
+		return p
+	}
+
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "swap"},
+		Scripts: []resources.Script{
+			resources.NewEasyScript("echo", func(ctx context.Context, input map[string]interface{}) (string, error) {
+				return "inline", nil
+			}),
+		},
+	}
+	providers := []resources.ResourceProvider{newProvider("a"), newProvider("b"), nil}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				skill.SetProvider(providers[j%len(providers)])
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				result, err := skill.UseScript(ctx, "echo", `{}`)
+				if err != nil {
+					t.Errorf("UseScript failed: %v", err)
+					return
+				}
+				if result != `"a"` && result != `"b"` && result != `"inline"` {
+					t.Errorf("Unexpected result: %s", result)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// nil Provider 回退到内联脚本
+	skill.SetProvider(nil)
+	result, err := skill.UseScript(ctx, "echo", `{}`)
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if result != `"inline"` {
+		t.Errorf("Expected inline result, got %s", result)
+	}
+
+	// Provider 不参与序列化
+	withProvider := &Skill{Metadata: &SkillMetadata{Name: "swap"}}
+	withProvider.SetProvider(providers[0])
+	data, err := json.Marshal(withProvider)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "Provider") || strings.Contains(string(data), "provider") {
+		t.Errorf("Expected provider to be excluded from JSON, got %s", data)
+	}
+}
+
+func TestSkill_SnapshotRestore(t *testing.T) {
+	skill := &Skill{
+		Metadata:   &SkillMetadata{Name: "editable", Description: "original", Labels: map[string]string{"team": "a"}},
+		Body:       "Read <reference>guide</reference>",
+		References: []*resources.Reference{{Name: "guide", Body: "# Guide"}},
+	}
+	if names := skill.GetReferenceNames(); len(names) != 1 {
+		t.Fatalf("Expected 1 reference name, got %v", names)
+	}
+	skill.ParseXMLTags()
+
+	snap := skill.Snapshot()
+
+	// 修改 Skill
+	skill.Body = "Use <script>new_script</script>"
+	skill.ParseXMLTags()
+	skill.References[0].Body = "# Changed"
+	skill.References = append(skill.References, &resources.Reference{Name: "extra"})
+	skill.Metadata.Description = "changed"
+	skill.Metadata.Labels["team"] = "b"
+
+	skill.Restore(snap)
+
+	if skill.Body != "Read <reference>guide</reference>" {
+		t.Errorf("Expected original body, got %s", skill.Body)
+	}
+	if len(skill.References) != 1 || skill.References[0].Body != "# Guide" {
+		t.Errorf("Expected original references, got %+v", skill.References)
+	}
+	if skill.Metadata.Description != "original" || skill.Metadata.Labels["team"] != "a" {
+		t.Errorf("Expected original metadata, got %+v", skill.Metadata)
+	}
+	// 解析缓存已重置
+	tags := skill.GetParsedTags()
+	if len(tags) != 1 || tags[0].TagName != "reference" {
+		t.Errorf("Expected parse cache to reflect restored body, got %+v", tags)
+	}
+
+	// 快照可重复使用，不受恢复后修改的影响
+	skill.References[0].Body = "# Changed again"
+	skill.Restore(snap)
+	if skill.References[0].Body != "# Guide" {
+		t.Errorf("Expected snapshot to be independent, got %s", skill.References[0].Body)
+	}
+}
+
+func TestSkill_ProviderOverride(t *testing.T) {
+	echo := func(source string) resources.Script {
+		return resources.NewEasyScript("echo", func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return source, nil
+		})
+	}
+
+	skill := &Skill{
+		Metadata:   &SkillMetadata{Name: "override_skill"},
+		Body:       "<script>echo</script> <reference>guide</reference>",
+		Scripts:    []resources.Script{echo("inline")},
+		References: []*resources.Reference{{Name: "guide", Body: "inline guide"}},
+	}
+
+	override := resources.NewInlineProvider()
+	override.AddScript(echo("override"))
+	override.AddReference(&resources.Reference{Name: "guide", Body: "override guide"})
+	ctx := resources.WithProviderOverride(context.Background(), override)
+
+	result, err := skill.UseScript(ctx, "echo", "{}")
+	if err != nil {
+		t.Fatalf("UseScript failed: %v", err)
+	}
+	if result != `"override"` {
+		t.Errorf("Expected override script, got %s", result)
+	}
+	body, err := skill.ReadReferenceContext(ctx, "guide")
+	if err != nil || body != "override guide" {
+		t.Errorf("Expected override reference, got %q (%v)", body, err)
+	}
+
+	// 未设置覆盖时行为不变，Skill 本身未被修改
+	result, _ = skill.UseScript(context.Background(), "echo", "{}")
+	if result != `"inline"` {
+		t.Errorf("Expected inline script without override, got %s", result)
+	}
+	body, _ = skill.ReadReference("guide")
+	if body != "inline guide" {
+		t.Errorf("Expected inline reference without override, got %s", body)
+	}
+	if skill.GetProvider() != nil {
+		t.Error("Expected skill provider to remain unset")
+	}
+
+	// 覆盖提供者中不存在的资源回退到正常查找
+	empty := resources.WithProviderOverride(context.Background(), resources.NewInlineProvider())
+	if result, _ := skill.UseScript(empty, "echo", "{}"); result != `"inline"` {
+		t.Errorf("Expected fallback to inline script, got %s", result)
+	}
+}
+
+func TestSkill_BodyFormat(t *testing.T) {
+	body := `<script timeout="5s">run</script> <reference>guide</reference>`
+
+	// v1（默认）不识别带属性的标记，行为与之前一致
+	v1 := &Skill{Metadata: &SkillMetadata{Name: "v1"}, Body: body}
+	if err := v1.ParseXMLTags(); err != nil {
+		t.Fatalf("ParseXMLTags failed: %v", err)
+	}
+	tags := v1.GetParsedTags()
+	if len(tags) != 1 || tags[0].TagName != "reference" || tags[0].Attrs != nil {
+		t.Errorf("Expected only the plain reference tag for v1, got %+v", tags)
+	}
+	if names := v1.GetScriptNames(); names != nil {
+		t.Errorf("Expected no script names for v1, got %v", names)
+	}
+
+	v2 := &Skill{Metadata: &SkillMetadata{Name: "v2", BodyFormat: BodyFormatV2}, Body: body}
+	if err := v2.ParseXMLTags(); err != nil {
+		t.Fatalf("ParseXMLTags failed: %v", err)
+	}
+	tags = v2.GetParsedTags()
+	if len(tags) != 2 || tags[0].Content != "run" || tags[0].Attrs["timeout"] != "5s" {
+		t.Errorf("Expected script tag with attributes for v2, got %+v", tags)
+	}
+	if names := v2.GetScriptNames(); len(names) != 1 || names[0] != "run" {
+		t.Errorf("Expected script names [run], got %v", names)
+	}
+
+	unknown := &Skill{Metadata: &SkillMetadata{Name: "v9", BodyFormat: "v9"}, Body: body}
+	if err := unknown.ParseXMLTags(); err == nil {
+		t.Error("Expected error for unknown body format")
+	}
+}
+
+// failingListProvider 列举资源总是失败的提供者
+type failingListProvider struct {
+	*resources.InlineProvider
+}
+
+func (p *failingListProvider) ListReferences(ctx context.Context) ([]string, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func TestSkill_AvailableReferences(t *testing.T) {
+	ctx := context.Background()
+	provider := resources.NewInlineProvider()
+	provider.AddReference(&resources.Reference{Name: "remote_guide", Body: "# Remote"})
+	provider.AddReference(&resources.Reference{Name: "tagged", Body: "# Tagged"})
+	provider.AddAsset(&resources.Asset{Name: "remote.png"})
+
+	skill := &Skill{
+		Metadata:   &SkillMetadata{Name: "catalog"},
+		Body:       "See <reference>tagged</reference>",
+		References: []*resources.Reference{{Name: "untagged", Body: "# Inline"}, {Name: "tagged", Body: "# Tagged"}},
+		Assets:     []*resources.Asset{{Name: "inline.png"}},
+	}
+	skill.SetProvider(provider)
+
+	refs, err := skill.AvailableReferences(ctx)
+	if err != nil {
+		t.Fatalf("AvailableReferences failed: %v", err)
+	}
+	if !reflect.DeepEqual(refs, []string{"remote_guide", "tagged", "untagged"}) {
+		t.Errorf("Expected sorted union of references, got %v", refs)
+	}
+	assets, err := skill.AvailableAssets(ctx)
+	if err != nil || !reflect.DeepEqual(assets, []string{"inline.png", "remote.png"}) {
+		t.Errorf("Expected sorted union of assets, got %v (%v)", assets, err)
+	}
+
+	// Provider 出错时仍返回内联名称
+	skill.SetProvider(&failingListProvider{resources.NewInlineProvider()})
+	refs, err = skill.AvailableReferences(ctx)
+	if err == nil {
+		t.Error("Expected provider error to be reported")
+	}
+	if !reflect.DeepEqual(refs, []string{"tagged", "untagged"}) {
+		t.Errorf("Expected inline references on provider error, got %v", refs)
+	}
+}
+
+func TestSkill_Explain(t *testing.T) {
+	ran := false
+	provider := resources.NewInlineProvider()
+	provider.AddReference(&resources.Reference{Name: "remote_guide", Body: "# Remote"})
+	skill := &Skill{
+		Metadata: &SkillMetadata{Name: "explain_skill"},
+		Body:     "<script>run</script> <script>ghost</script> <reference>remote_guide</reference>",
+		Scripts: []resources.Script{resources.NewEasyScript("run", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			ran = true
+			return nil, nil
+		})},
+		References: []*resources.Reference{{Name: "notes", Body: "never referenced"}},
+	}
+	skill.SetProvider(provider)
+
+	report := skill.Explain(context.Background())
+	for _, expected := range []string{
+		`script "run": resolved (inline)`,
+		`script "ghost": DANGLING`,
+		`reference "remote_guide": resolved (provider)`,
+		`reference "notes": UNUSED`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, report)
+		}
+	}
+	if ran {
+		t.Error("Expected Explain not to execute scripts")
+	}
+}
+
+func TestSkill_ReadLazyReference(t *testing.T) {
+	ctx := context.Background()
+	var calls int32
+	var mu sync.Mutex
+	lazy := resources.NewLazyReference("rendered", func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return "# Rendered", nil
+	})
+	loadErr := errors.New("render failed")
+	broken := resources.NewLazyReference("broken", func(ctx context.Context) (string, error) {
+		return "", loadErr
+	})
+
+	skill := &Skill{
+		Metadata:   &SkillMetadata{Name: "docs"},
+		References: []*resources.Reference{{Name: "eager", Body: "# Eager"}, lazy, broken},
+	}
+
+	// 读取其他参考文档不会触发加载
+	if body, err := skill.ReadReference("eager"); err != nil || body != "# Eager" {
+		t.Fatalf("Expected eager body, got %q (%v)", body, err)
+	}
+	if calls != 0 {
+		t.Fatalf("Expected loader not to run before first read, got %d calls", calls)
+	}
+
+	// 并发读取时 loader 只执行一次
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if body, err := skill.ReadReferenceContext(ctx, "rendered"); err != nil || body != "# Rendered" {
+				t.Errorf("Expected rendered body, got %q (%v)", body, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Expected loader to run once, got %d calls", calls)
+	}
+
+	// loader 的错误带有参考文档名称并可被 errors.Is 识别
+	_, err := skill.ReadReference("broken")
+	if !errors.Is(err, loadErr) || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected wrapped loader error, got %v", err)
+	}
+
+	// 失败（包括 ctx 取消）不被缓存，之后的读取会重试
+	attempts := 0
+	flaky := resources.NewLazyReference("flaky", func(ctx context.Context) (string, error) {
+		attempts++
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "# Flaky", nil
+	})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := flaky.Load(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if body, err := flaky.Load(ctx); err != nil || body != "# Flaky" {
+		t.Errorf("Expected retry to succeed, got %q (%v)", body, err)
+	}
+	flaky.Load(ctx)
+	if attempts != 2 {
+		t.Errorf("Expected success to be cached after 2 attempts, got %d", attempts)
+	}
+}
+
+func TestSkill_NilMetadata(t *testing.T) {
+	ctx := context.Background()
+	skill := &Skill{Body: "Run <script>missing</script>"}
+
+	if got := skill.Glance(); got != "{}" {
+		t.Errorf("Expected {} for nil metadata, got %s", got)
+	}
+	if got := skill.Inspect(); got != skill.Body {
+		t.Errorf("Expected body, got %s", got)
+	}
+	if skill.GetName() != "" || skill.GetDescription() != "" {
+		t.Errorf("Expected empty name and description, got %q %q", skill.GetName(), skill.GetDescription())
+	}
+
+	out, err := skill.Execute(ctx, `{}`)
+	if err == nil {
+		t.Error("Expected error for missing script")
+	}
+	if !strings.HasPrefix(out, "Skill: \n") {
+		t.Errorf("Expected empty skill name in report, got %q", out)
+	}
+
+	// 零值 Skill 同样安全
+	zero := &Skill{}
+	if got := zero.Glance(); got != "{}" {
+		t.Errorf("Expected {} for zero skill, got %s", got)
+	}
+	if _, err := zero.Execute(ctx, `{}`); err != nil {
+		t.Errorf("Expected no error for zero skill, got %v", err)
+	}
+	zero.Explain(ctx)
+}
@@ -0,0 +1,102 @@
+package schema
+
+import "github.com/alois132/skill/schema/resources"
+
+// SkillSnapshot Skill 某一时刻状态的独立副本，用于编辑失败时回滚
+// 包含元数据、Body、脚本、参考文档和资源文件，不包含 Provider
+type SkillSnapshot struct {
+	metadata   *SkillMetadata
+	body       string
+	scripts    []resources.Script
+	references []*resources.Reference
+	assets     []*resources.Asset
+}
+
+// Snapshot 创建 Skill 当前状态的快照
+// 元数据、参考文档和资源文件为深拷贝；脚本为接口，仅拷贝切片
+func (skill *Skill) Snapshot() *SkillSnapshot {
+	return &SkillSnapshot{
+		metadata:   copyMetadata(skill.Metadata),
+		body:       skill.Body,
+		scripts:    copyScripts(skill.Scripts),
+		references: copyReferences(skill.References),
+		assets:     copyAssets(skill.Assets),
+	}
+}
+
+// Restore 将 Skill 恢复到快照时的状态，并清空解析缓存
+// 快照本身不受后续修改影响，可以多次恢复
+func (skill *Skill) Restore(snap *SkillSnapshot) {
+	if snap == nil {
+		return
+	}
+	skill.Metadata = copyMetadata(snap.metadata)
+	skill.Body = snap.body
+	skill.Scripts = copyScripts(snap.scripts)
+	skill.References = copyReferences(snap.references)
+	skill.Assets = copyAssets(snap.assets)
+	skill.resetParsedTags()
+}
+
+// Clone 返回 Skill 的独立副本，修改副本不会影响原 Skill
+// 元数据、参考文档和资源文件为深拷贝；脚本为接口，仅拷贝切片；Provider 与原 Skill 共享
+func (skill *Skill) Clone() *Skill {
+	skill.metadataMu.RLock()
+	metadata := copyMetadata(skill.Metadata)
+	skill.metadataMu.RUnlock()
+
+	clone := &Skill{
+		Metadata:   metadata,
+		Body:       skill.Body,
+		Scripts:    copyScripts(skill.Scripts),
+		References: copyReferences(skill.References),
+		Assets:     copyAssets(skill.Assets),
+	}
+	clone.RecoverPanics = skill.RecoverPanics
+	clone.SetProvider(skill.GetProvider())
+	return clone
+}
+
+// copyMetadata 深拷贝元数据
+func copyMetadata(metadata *SkillMetadata) *SkillMetadata {
+	return metadata.Clone()
+}
+
+// copyScripts 拷贝脚本切片
+func copyScripts(scripts []resources.Script) []resources.Script {
+	if scripts == nil {
+		return nil
+	}
+	return append([]resources.Script(nil), scripts...)
+}
+
+// copyReferences 深拷贝参考文档
+func copyReferences(refs []*resources.Reference) []*resources.Reference {
+	if refs == nil {
+		return nil
+	}
+	copied := make([]*resources.Reference, len(refs))
+	for i, ref := range refs {
+		if ref != nil {
+			r := *ref
+			copied[i] = &r
+		}
+	}
+	return copied
+}
+
+// copyAssets 深拷贝资源文件
+func copyAssets(assets []*resources.Asset) []*resources.Asset {
+	if assets == nil {
+		return nil
+	}
+	copied := make([]*resources.Asset, len(assets))
+	for i, asset := range assets {
+		if asset != nil {
+			a := *asset
+			a.Bytes = append([]byte(nil), asset.Bytes...)
+			copied[i] = &a
+		}
+	}
+	return copied
+}
@@ -17,8 +17,9 @@ import (
 // 用户可以根据需要替换为真实的 etcd 客户端
 type EtcdStore struct {
 	// client *clientv3.Client  // 真实实现时需要
-	prefix string
-	config *StoreConfig
+	prefix     string // 包含命名空间的键前缀
+	basePrefix string // 不含命名空间的键前缀，用于自定义 KeyFunc
+	config     *StoreConfig
 }
 
 // EtcdClient 定义 etcd 客户端的接口（用于解耦）
@@ -54,18 +55,20 @@ func NewEtcdStore(client EtcdClient, opts ...StoreOption) (*EtcdStore, error) {
 		opt(config)
 	}
 
-	prefix := config.Prefix
-	if prefix == "" {
-		prefix = "/skills"
+	basePrefix := config.Prefix
+	if basePrefix == "" {
+		basePrefix = "/skills"
 	}
+	prefix := basePrefix
 	if config.Namespace != "" {
 		prefix = prefix + "/" + config.Namespace
 	}
 
 	return &EtcdStore{
 		// client: client,  // 真实实现时需要
-		prefix: prefix,
-		config: config,
+		prefix:     prefix,
+		basePrefix: basePrefix,
+		config:     config,
 	}, nil
 }
 
@@ -171,6 +174,9 @@ func (s *EtcdStore) Exists(ctx context.Context, name string) (bool, error) {
 
 // key 生成 etcd 存储键
 func (s *EtcdStore) key(name string) string {
+	if s.config.KeyFunc != nil {
+		return s.basePrefix + "/" + s.config.KeyFunc(s.config.Namespace, name)
+	}
 	return s.prefix + "/" + name
 }
 
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// SkillOp Skill 变更的操作类型
+type SkillOp string
+
+const (
+	SkillOpPut    SkillOp = "put"    // 创建或更新（包括 Patch 和 Restore）
+	SkillOpDelete SkillOp = "delete" // 删除（包括软删除和 Purge）
+)
+
+// SkillEvent Store 中 Skill 变更的事件
+type SkillEvent struct {
+	Namespace string  `json:"namespace,omitempty"` // 发布事件的 Store 的命名空间
+	Name      string  `json:"name"`
+	Op        SkillOp `json:"op"`
+	Origin    string  `json:"origin,omitempty"` // 发起写入的实例标识，由 WithEventOrigin 设置，订阅者可据此忽略自己引起的事件
+}
+
+// eventOriginKey context 中事件来源的键
+type eventOriginKey struct{}
+
+// WithEventOrigin 返回携带事件来源标识的 context，Store 使用该 context 写入时发布的事件带有此 Origin
+func WithEventOrigin(ctx context.Context, origin string) context.Context {
+	return context.WithValue(ctx, eventOriginKey{}, origin)
+}
+
+// EventOriginFromContext 返回 WithEventOrigin 设置的事件来源标识
+func EventOriginFromContext(ctx context.Context) (string, bool) {
+	origin, ok := ctx.Value(eventOriginKey{}).(string)
+	return origin, ok
+}
+
+// EventBus Skill 变更事件总线，用于在共享同一存储的多个实例之间协调缓存
+// Store 在写入成功后、持有写锁时调用 Publish，因此 Publish 不能阻塞；
+// 跨进程部署时可基于 Redis pub/sub 等实现：Publish 将事件异步发布到频道，Subscribe 返回转发频道消息的 channel。
+// Subscribe 同时返回取消订阅的函数：调用后总线不再向该 channel 发送事件并关闭它，可重复调用
+type EventBus interface {
+	Publish(evt SkillEvent)
+	Subscribe() (events <-chan SkillEvent, cancel func())
+}
+
+// WithEventBus 设置事件总线，Put、Patch、Delete、Restore、Purge 成功后发布事件（MemoryStore 和 FileStore 支持）
+func WithEventBus(bus EventBus) StoreOption {
+	return func(c *StoreConfig) {
+		c.EventBus = bus
+	}
+}
+
+// publish 未设置事件总线时为空操作，事件的 Origin 取自 ctx
+func (c *StoreConfig) publish(ctx context.Context, name string, op SkillOp) {
+	if c.EventBus != nil {
+		origin, _ := EventOriginFromContext(ctx)
+		c.EventBus.Publish(SkillEvent{Namespace: c.Namespace, Name: name, Op: op, Origin: origin})
+	}
+}
+
+// defaultEventBuffer InProcessBus 每个订阅者的默认缓冲大小
+const defaultEventBuffer = 64
+
+// InProcessBus 进程内事件总线，每个订阅者拥有独立的带缓冲 channel
+// Publish 从不阻塞：订阅者的缓冲已满时该订阅者会丢弃此事件并记录日志（或调用 WithDropHandler 设置的回调），
+// 丢弃总数可通过 Dropped 查询；订阅者应尽快消费
+type InProcessBus struct {
+	mu          sync.RWMutex
+	buffer      int
+	subscribers []chan SkillEvent
+	closed      bool
+
+	onDrop  func(evt SkillEvent)
+	dropped atomic.Uint64
+}
+
+// NewInProcessBus 创建进程内事件总线，buffer <= 0 时使用默认缓冲大小
+func NewInProcessBus(buffer int) *InProcessBus {
+	if buffer <= 0 {
+		buffer = defaultEventBuffer
+	}
+	return &InProcessBus{buffer: buffer}
+}
+
+// Publish 将事件非阻塞地发送给所有订阅者
+func (b *InProcessBus) Publish(evt SkillEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- evt:
+		default: // 缓冲已满，丢弃
+			b.dropped.Add(1)
+			if b.onDrop != nil {
+				b.onDrop(evt)
+			} else {
+				log.Printf("skill store: event buffer full, dropping %s event for %s", evt.Op, evt.Name)
+			}
+		}
+	}
+}
+
+// WithDropHandler 设置订阅者缓冲已满、事件被丢弃时的回调（替代默认的日志），回调不能阻塞
+func (b *InProcessBus) WithDropHandler(fn func(evt SkillEvent)) *InProcessBus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onDrop = fn
+	return b
+}
+
+// Dropped 返回因订阅者缓冲已满而丢弃的事件数
+func (b *InProcessBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Subscribe 返回接收之后发布的事件的 channel 和取消订阅的函数
+// 取消订阅或总线关闭后 channel 被关闭
+func (b *InProcessBus) Subscribe() (<-chan SkillEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan SkillEvent, b.buffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers = append(b.subscribers, ch)
+	return ch, func() { b.unsubscribe(ch) }
+}
+
+// unsubscribe 移除并关闭订阅者的 channel，已移除（或总线已关闭）时为空操作
+func (b *InProcessBus) unsubscribe(ch chan SkillEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, sub := range b.subscribers {
+		if sub == ch {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// Close 关闭总线和所有订阅者的 channel，之后的 Publish 为空操作
+func (b *InProcessBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, ch := range b.subscribers {
+		close(ch)
+	}
+	b.subscribers = nil
+}
+
+// Ensure InProcessBus implements EventBus
+var _ EventBus = (*InProcessBus)(nil)
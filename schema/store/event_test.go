@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alois132/skill/schema"
+)
+
+func TestInProcessBus_PublishDoesNotBlock(t *testing.T) {
+	var dropped []SkillEvent
+	bus := NewInProcessBus(1).WithDropHandler(func(evt SkillEvent) {
+		dropped = append(dropped, evt)
+	})
+	events, _ := bus.Subscribe()
+
+	// 缓冲已满时丢弃而不是阻塞写入，并报告丢弃
+	bus.Publish(SkillEvent{Name: "a", Op: SkillOpPut})
+	bus.Publish(SkillEvent{Name: "b", Op: SkillOpDelete})
+	if bus.Dropped() != 1 || len(dropped) != 1 || dropped[0].Name != "b" {
+		t.Errorf("Expected event b to be reported as dropped, got %d %+v", bus.Dropped(), dropped)
+	}
+
+	evt := <-events
+	if evt.Name != "a" || evt.Op != SkillOpPut {
+		t.Errorf("Expected put event for a, got %+v", evt)
+	}
+	bus.Close()
+	if _, ok := <-events; ok {
+		t.Error("Expected channel to be closed")
+	}
+}
+
+func TestInProcessBus_Unsubscribe(t *testing.T) {
+	var dropped int
+	bus := NewInProcessBus(1).WithDropHandler(func(evt SkillEvent) {
+		dropped++
+	})
+	events, cancel := bus.Subscribe()
+	cancel()
+	cancel()
+
+	// 取消订阅后 channel 被关闭，发布不再填充它或报告丢弃
+	if _, ok := <-events; ok {
+		t.Error("Expected channel to be closed after cancel")
+	}
+	bus.Publish(SkillEvent{Name: "a", Op: SkillOpPut})
+	bus.Publish(SkillEvent{Name: "b", Op: SkillOpPut})
+	if bus.Dropped() != 0 || dropped != 0 {
+		t.Errorf("Expected no drops after unsubscribe, got %d", bus.Dropped())
+	}
+	bus.Close()
+}
+
+func TestStores_PublishEvents(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(bus EventBus) SkillStore{
+		"memory": func(bus EventBus) SkillStore {
+			return NewMemoryStore(WithNamespace("prod"), WithEventBus(bus))
+		},
+		"file": func(bus EventBus) SkillStore {
+			s, err := NewFileStore(t.TempDir(), WithNamespace("prod"), WithEventBus(bus))
+			if err != nil {
+				t.Fatalf("Failed to create file store: %v", err)
+			}
+			return s
+		},
+	}
+
+	for kind, newStore := range stores {
+		bus := NewInProcessBus(8)
+		events, _ := bus.Subscribe()
+		s := newStore(bus)
+
+		if err := s.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "alpha"}}); err != nil {
+			t.Fatalf("Failed to put skill (%s): %v", kind, err)
+		}
+		if err := s.Delete(WithEventOrigin(ctx, "instance-1"), "alpha"); err != nil {
+			t.Fatalf("Failed to delete skill (%s): %v", kind, err)
+		}
+		// 失败的写入不发布事件
+		if err := s.Delete(ctx, "alpha"); err == nil {
+			t.Fatalf("Expected error deleting missing skill (%s)", kind)
+		}
+		bus.Close()
+
+		var got []SkillEvent
+		for evt := range events {
+			got = append(got, evt)
+		}
+		expected := []SkillEvent{
+			{Namespace: "prod", Name: "alpha", Op: SkillOpPut},
+			{Namespace: "prod", Name: "alpha", Op: SkillOpDelete, Origin: "instance-1"},
+		}
+		if len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+			t.Errorf("Expected events %+v (%s), got %+v", expected, kind, got)
+		}
+	}
+}
@@ -1,24 +1,35 @@
 package store
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/alois132/skill/schema"
 )
 
+// ErrCorrupt Skill 文件内容与校验和不匹配
+var ErrCorrupt = errors.New("skill file is corrupt")
+
 // FileStore 基于文件系统的 Skill 存储实现
-// 每个 Skill 存储为一个 JSON 文件
+// 每个 Skill 存储为一个文件，默认为 JSON，可通过 WithSerializer 更换格式
 type FileStore struct {
 	mu       sync.RWMutex
 	basePath string
 	config   *StoreConfig
+
+	readFile func(name string) ([]byte, error) // 读取文件，默认为 os.ReadFile
 }
 
 // FileStoreOption FileStore 特有的配置选项
@@ -40,6 +51,7 @@ func NewFileStore(basePath string, opts ...StoreOption) (*FileStore, error) {
 	return &FileStore{
 		basePath: basePath,
 		config:   config,
+		readFile: os.ReadFile,
 	}, nil
 }
 
@@ -49,55 +61,151 @@ func (s *FileStore) Get(ctx context.Context, name string) (*schema.Skill, error)
 	defer s.mu.RUnlock()
 
 	filePath := s.filePath(name)
-	data, err := os.ReadFile(filePath)
+	data, err := s.readFile(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, errors.New("skill not found: " + name)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
 		}
 		return nil, fmt.Errorf("failed to read skill file: %w", err)
 	}
 
-	var skill schema.Skill
-	if err := json.Unmarshal(data, &skill); err != nil {
+	if err := s.verify(filePath, data); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+
+	skill, err := s.serializer().Unmarshal(data)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal skill: %w", err)
 	}
 
-	return &skill, nil
+	return skill, nil
 }
 
 // List 列出所有可用的 Skill 元数据
+// 开启索引且索引有效时只读取索引文件，否则扫描目录中的所有 Skill 文件
 func (s *FileStore) List(ctx context.Context) ([]*schema.SkillMetadata, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if s.config.Index {
+		if index, ok := s.readFreshIndex(); ok {
+			return s.metadatas(index), nil
+		}
+	}
+
+	index, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	return s.metadatas(index), nil
+}
+
+// scan 读取目录中所有 Skill 文件，返回文件键（不含扩展名）到元数据的映射
+// 无法读取、校验失败或无法解析的文件会被跳过
+func (s *FileStore) scan() (map[string]*schema.SkillMetadata, error) {
 	entries, err := os.ReadDir(s.basePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	metadatas := make([]*schema.SkillMetadata, 0)
+	index := make(map[string]*schema.SkillMetadata)
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+		if !s.isSkillFile(entry) {
 			continue
 		}
-
-		filePath := filepath.Join(s.basePath, entry.Name())
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			continue // 跳过无法读取的文件
+		if metadata, ok := s.readMetadata(filepath.Join(s.basePath, entry.Name())); ok {
+			index[strings.TrimSuffix(entry.Name(), s.fileExt())] = metadata
 		}
+	}
 
-		var skill schema.Skill
-		if err := json.Unmarshal(data, &skill); err != nil {
-			continue // 跳过无法解析的文件
+	return index, nil
+}
+
+// readMetadata 读取并解析 Skill 文件的元数据
+// 无法读取、校验失败、无法解析或没有元数据的文件返回 false
+func (s *FileStore) readMetadata(filePath string) (*schema.SkillMetadata, bool) {
+	data, err := s.readFile(filePath)
+	if err != nil {
+		return nil, false // 跳过无法读取的文件
+	}
+
+	if err := s.verify(filePath, data); err != nil {
+		log.Printf("skill store: skipping corrupt file %s: %v", filePath, err)
+		return nil, false // 跳过校验失败的文件
+	}
+
+	metadata, err := s.serializer().UnmarshalMetadata(data)
+	if err != nil {
+		return nil, false // 跳过无法解析的文件
+	}
+	return metadata, metadata != nil
+}
+
+// ListFiltered 列出名称满足 filter 的 Skill 元数据，按文件键排序
+// 名称从文件名解析（考虑 NameFunc 和命名空间前缀），filter 在读取文件之前调用，
+// 未通过的文件不会被读取；不属于当前命名空间的文件被跳过
+func (s *FileStore) ListFiltered(ctx context.Context, filter func(name string) bool) ([]*schema.SkillMetadata, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	index := make(map[string]*schema.SkillMetadata)
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !s.isSkillFile(entry) {
+			continue
+		}
+		key := strings.TrimSuffix(entry.Name(), s.fileExt())
+		name, ok := s.skillName(key)
+		if !ok || !filter(name) {
+			continue
 		}
+		if metadata, ok := s.readMetadata(filepath.Join(s.basePath, entry.Name())); ok {
+			index[key] = metadata
+		}
+	}
+
+	return s.metadatas(index), nil
+}
 
-		if skill.Metadata != nil {
-			metadatas = append(metadatas, skill.Metadata)
+// skillName 从文件键解析 Skill 名称，ok 为 false 表示该文件不属于当前 Store
+// 优先使用 NameFunc；否则去掉命名空间前缀；设置了 KeyFunc 但没有 NameFunc 时直接使用键
+func (s *FileStore) skillName(key string) (string, bool) {
+	switch {
+	case s.config.NameFunc != nil:
+		return s.config.NameFunc(key)
+	case s.config.KeyFunc != nil:
+		return key, true
+	case s.config.Namespace != "":
+		prefix := s.config.Namespace + "_"
+		if !strings.HasPrefix(key, prefix) {
+			return "", false
 		}
+		return strings.TrimPrefix(key, prefix), true
+	default:
+		return key, true
 	}
+}
+
+// metadatas 按文件键排序返回元数据
+func (s *FileStore) metadatas(index map[string]*schema.SkillMetadata) []*schema.SkillMetadata {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	return metadatas, nil
+	metadatas := make([]*schema.SkillMetadata, 0, len(keys))
+	for _, key := range keys {
+		metadatas = append(metadatas, index[key])
+	}
+	return metadatas
 }
 
 // Put 保存 Skill 到文件系统
@@ -112,36 +220,325 @@ func (s *FileStore) Put(ctx context.Context, skill *schema.Skill) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.config.Index && s.filePath(skill.Metadata.Name) == s.indexPath() {
+		return errors.New("skill name is reserved for the index file: " + skill.Metadata.Name)
+	}
+
+	if err := s.write(skill); err != nil {
+		return err
+	}
+	s.config.publish(ctx, skill.Metadata.Name, SkillOpPut)
+	return nil
+}
+
+// Patch 在写锁内读取最新的 Skill 文件，调用 fn 修改后原子地写回
+func (s *FileStore) Patch(ctx context.Context, name string, fn func(skill *schema.Skill) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.filePath(name)
+	data, err := s.readFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return fmt.Errorf("failed to read skill file: %w", err)
+	}
+	if err := s.verify(filePath, data); err != nil {
+		return fmt.Errorf("%w: %s", err, name)
+	}
+
+	skill, err := s.serializer().Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal skill: %w", err)
+	}
+
+	if err := fn(skill); err != nil {
+		return err
+	}
+	if skill.Metadata == nil || skill.Metadata.Name != name {
+		return errors.New("patch cannot rename skill: " + name)
+	}
+
+	if err := s.write(skill); err != nil {
+		return err
+	}
+	s.config.publish(ctx, name, SkillOpPut)
+	return nil
+}
+
+// write 序列化 Skill 并原子地写入文件（先写临时文件再重命名），调用方需持有写锁
+// 数据文件和校验文件都先写入临时文件；替换前先删除旧的校验文件，
+// 因此任何时刻中断，校验文件要么与数据文件一致，要么不存在（verify 跳过校验）。
+// 未开启完整性校验时同样删除旧的校验文件，避免之后开启校验时误报 ErrCorrupt
+func (s *FileStore) write(skill *schema.Skill) error {
 	filePath := s.filePath(skill.Metadata.Name)
-	data, err := json.MarshalIndent(skill, "", "  ")
+	data, err := s.marshal(skill)
 	if err != nil {
 		return fmt.Errorf("failed to marshal skill: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	dataTmp, err := stageFile(filePath, data)
+	if err != nil {
+		return fmt.Errorf("failed to write skill file: %w", err)
+	}
+	var sumTmp string
+	if s.config.IntegrityCheck {
+		sum := sha256.Sum256(data)
+		if sumTmp, err = stageFile(checksumPath(filePath), []byte(hex.EncodeToString(sum[:]))); err != nil {
+			os.Remove(dataTmp)
+			return fmt.Errorf("failed to write checksum file: %w", err)
+		}
+	}
+	cleanup := func() {
+		os.Remove(dataTmp)
+		if sumTmp != "" {
+			os.Remove(sumTmp)
+		}
+	}
+
+	if err := os.Remove(checksumPath(filePath)); err != nil && !os.IsNotExist(err) {
+		cleanup()
+		return fmt.Errorf("failed to remove checksum file: %w", err)
+	}
+	if err := os.Rename(dataTmp, filePath); err != nil {
+		cleanup()
 		return fmt.Errorf("failed to write skill file: %w", err)
 	}
+	if sumTmp != "" {
+		if err := os.Rename(sumTmp, checksumPath(filePath)); err != nil {
+			os.Remove(sumTmp)
+			return fmt.Errorf("failed to write checksum file: %w", err)
+		}
+	}
+
+	if s.config.Index {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to stat skill file: %w", err)
+		}
+		key := strings.TrimSuffix(filepath.Base(filePath), s.fileExt())
+		if err := s.updateIndex(key, newIndexEntry(skill.Metadata, info)); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// writeFileAtomic 先写入同目录下的临时文件，再重命名覆盖目标文件
+func writeFileAtomic(filePath string, data []byte) error {
+	tmpPath, err := stageFile(filePath, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// stageFile 将 data 写入 filePath 同目录下的临时文件并返回其路径，由调用方重命名为目标文件
+func stageFile(filePath string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
+}
+
 // Delete 从文件系统中删除指定名称的 Skill
+// 开启软删除时将其改写为 <file>.deleted 墓碑文件，而不是删除
 func (s *FileStore) Delete(ctx context.Context, name string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	filePath := s.filePath(name)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return errors.New("skill not found: " + name)
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	if s.config.SoftDelete {
+		if err := s.writeTombstone(filePath); err != nil {
+			return err
+		}
 	}
 
+	if err := s.remove(filePath); err != nil {
+		return err
+	}
+	s.config.publish(ctx, name, SkillOpDelete)
+	return nil
+}
+
+// remove 删除 Skill 文件及其校验文件并更新索引，调用方需持有写锁
+func (s *FileStore) remove(filePath string) error {
 	if err := os.Remove(filePath); err != nil {
 		return fmt.Errorf("failed to delete skill file: %w", err)
 	}
+	// 校验文件可能不存在，忽略错误
+	os.Remove(checksumPath(filePath))
+
+	if s.config.Index {
+		key := strings.TrimSuffix(filepath.Base(filePath), s.fileExt())
+		if err := s.updateIndex(key, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTombstone 将 Skill 标记为已删除后写入墓碑文件，调用方需持有写锁
+func (s *FileStore) writeTombstone(filePath string) error {
+	data, err := s.readFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read skill file: %w", err)
+	}
+	skill, err := s.serializer().Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal skill: %w", err)
+	}
+	if skill.Metadata == nil {
+		skill.Metadata = &schema.SkillMetadata{}
+	}
 
+	now := time.Now()
+	skill.Metadata.Deleted = true
+	skill.Metadata.DeletedAt = &now
+	data, err = s.marshal(skill)
+	if err != nil {
+		return fmt.Errorf("failed to marshal skill: %w", err)
+	}
+	if err := writeFileAtomic(tombstonePath(filePath), data); err != nil {
+		return fmt.Errorf("failed to write tombstone file: %w", err)
+	}
 	return nil
 }
 
+// Restore 恢复被软删除的 Skill
+func (s *FileStore) Restore(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.filePath(name)
+	data, err := s.readFile(tombstonePath(filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.New("deleted skill not found: " + name)
+		}
+		return fmt.Errorf("failed to read tombstone file: %w", err)
+	}
+	if _, err := os.Stat(filePath); err == nil {
+		return errors.New("skill already exists: " + name)
+	}
+
+	skill, err := s.serializer().Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal skill: %w", err)
+	}
+	if skill.Metadata == nil || skill.Metadata.Name == "" {
+		return errors.New("tombstone has no skill name: " + name)
+	}
+	skill.Metadata.Deleted = false
+	skill.Metadata.DeletedAt = nil
+
+	if err := s.write(skill); err != nil {
+		return err
+	}
+	if err := os.Remove(tombstonePath(filePath)); err != nil {
+		return fmt.Errorf("failed to delete tombstone file: %w", err)
+	}
+	s.config.publish(ctx, name, SkillOpPut)
+	return nil
+}
+
+// ListDeleted 列出所有被软删除的 Skill 元数据，按文件键排序
+func (s *FileStore) ListDeleted(ctx context.Context) ([]*schema.SkillMetadata, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	index := make(map[string]*schema.SkillMetadata)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), s.fileExt()+tombstoneSuffix) {
+			continue
+		}
+		key := strings.TrimSuffix(entry.Name(), s.fileExt()+tombstoneSuffix)
+		if s.config.NameFunc != nil {
+			if _, ok := s.config.NameFunc(key); !ok {
+				continue // 不属于当前 Store 的文件
+			}
+		}
+
+		data, err := s.readFile(filepath.Join(s.basePath, entry.Name()))
+		if err != nil {
+			continue // 跳过无法读取的文件
+		}
+		metadata, err := s.serializer().UnmarshalMetadata(data)
+		if err != nil || metadata == nil {
+			continue // 跳过无法解析的文件
+		}
+		index[key] = metadata
+	}
+
+	return s.metadatas(index), nil
+}
+
+// Purge 彻底删除指定名称的 Skill 及其墓碑文件
+func (s *FileStore) Purge(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.filePath(name)
+	_, liveErr := os.Stat(filePath)
+	_, tombstoneErr := os.Stat(tombstonePath(filePath))
+	if os.IsNotExist(liveErr) && os.IsNotExist(tombstoneErr) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	if liveErr == nil {
+		if err := s.remove(filePath); err != nil {
+			return err
+		}
+	}
+	if tombstoneErr == nil {
+		if err := os.Remove(tombstonePath(filePath)); err != nil {
+			return fmt.Errorf("failed to delete tombstone file: %w", err)
+		}
+	}
+	s.config.publish(ctx, name, SkillOpDelete)
+	return nil
+}
+
+// tombstoneSuffix 墓碑文件后缀，不以 Skill 文件扩展名结尾，因此不会被 List 扫描
+const tombstoneSuffix = ".deleted"
+
+// tombstonePath 返回 Skill 文件对应的墓碑文件路径
+func tombstonePath(filePath string) string {
+	return filePath + tombstoneSuffix
+}
+
 // Exists 检查指定名称的 Skill 是否存在
 func (s *FileStore) Exists(ctx context.Context, name string) (bool, error) {
 	s.mu.RLock()
@@ -158,13 +555,244 @@ func (s *FileStore) Exists(ctx context.Context, name string) (bool, error) {
 	return true, nil
 }
 
+// verify 校验文件内容与校验文件是否一致
+// 未开启完整性校验或校验文件不存在（如开启前写入的文件）时跳过校验
+func (s *FileStore) verify(filePath string, data []byte) error {
+	if !s.config.IntegrityCheck {
+		return nil
+	}
+
+	expected, err := os.ReadFile(checksumPath(filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read checksum file: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != strings.TrimSpace(string(expected)) {
+		return ErrCorrupt
+	}
+	return nil
+}
+
+// checksumPath 返回 Skill 文件对应的校验文件路径
+func checksumPath(filePath string) string {
+	return filePath + ".sha256"
+}
+
+// marshal 使用配置的序列化格式序列化 Skill
+func (s *FileStore) marshal(skill *schema.Skill) ([]byte, error) {
+	return s.serializer().Marshal(skill)
+}
+
+// serializer 返回配置的序列化格式，默认为 JSONSerializer
+func (s *FileStore) serializer() Serializer {
+	if s.config.Serializer != nil {
+		return s.config.Serializer
+	}
+	return JSONSerializer{Canonical: s.config.CanonicalJSON}
+}
+
+// fileExt 返回 Skill 文件的扩展名，由序列化格式决定，默认为 ".json"
+func (s *FileStore) fileExt() string {
+	if ext, ok := s.serializer().(FileExtSerializer); ok {
+		return ext.FileExt()
+	}
+	return ".json"
+}
+
+// marshalCanonical 生成规范化 JSON
+// 先序列化再解码为通用结构，重新编码时所有对象的键都会按字典序排列
+func marshalCanonical(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber() // 保持数字的原始表示
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(generic, "", "  ")
+}
+
 // filePath 生成 Skill 文件的完整路径
 func (s *FileStore) filePath(name string) string {
-	key := name
-	if s.config.Namespace != "" {
+	var key string
+	switch {
+	case s.config.KeyFunc != nil:
+		key = s.config.KeyFunc(s.config.Namespace, name)
+	case s.config.Namespace != "":
 		key = s.config.Namespace + "_" + name
+	default:
+		key = name
+	}
+	return filepath.Join(s.basePath, key+s.fileExt())
+}
+
+// indexFileName 索引文件名
+const indexFileName = "_index.json"
+
+// indexEntry 索引条目，记录文件大小和修改时间用于判断条目是否过期
+type indexEntry struct {
+	Metadata *schema.SkillMetadata `json:"metadata"`
+	Size     int64                 `json:"size"`
+	ModTime  int64                 `json:"mod_time"` // UnixNano
+}
+
+// newIndexEntry 根据文件信息创建索引条目
+func newIndexEntry(metadata *schema.SkillMetadata, info os.FileInfo) *indexEntry {
+	return &indexEntry{Metadata: metadata, Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+}
+
+// matches 判断条目是否与文件的当前大小和修改时间一致
+func (e *indexEntry) matches(info os.FileInfo) bool {
+	return e != nil && e.Size == info.Size() && e.ModTime == info.ModTime().UnixNano()
+}
+
+// RebuildIndex 扫描目录重建索引文件，用于目录被直接修改之后
+func (s *FileStore) RebuildIndex(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, err := s.scanIndex()
+	if err != nil {
+		return err
+	}
+	return s.writeIndex(index)
+}
+
+// indexPath 返回索引文件路径
+func (s *FileStore) indexPath() string {
+	return filepath.Join(s.basePath, indexFileName)
+}
+
+// scanIndex 与 scan 相同，但同时记录每个文件的大小和修改时间
+func (s *FileStore) scanIndex() (map[string]*indexEntry, error) {
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	index := make(map[string]*indexEntry)
+	for _, entry := range entries {
+		if !s.isSkillFile(entry) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if metadata, ok := s.readMetadata(filepath.Join(s.basePath, entry.Name())); ok {
+			index[strings.TrimSuffix(entry.Name(), s.fileExt())] = newIndexEntry(metadata, info)
+		}
+	}
+
+	return index, nil
+}
+
+// readIndex 读取索引文件
+func (s *FileStore) readIndex() (map[string]*indexEntry, error) {
+	data, err := s.readFile(s.indexPath())
+	if err != nil {
+		return nil, err
+	}
+	var index map[string]*indexEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// readFreshIndex 读取索引，索引缺失、损坏或过期时返回 false
+func (s *FileStore) readFreshIndex() (map[string]*schema.SkillMetadata, bool) {
+	index, err := s.readIndex()
+	if err != nil || !s.indexMatches(index, "") {
+		return nil, false
+	}
+
+	metadatas := make(map[string]*schema.SkillMetadata, len(index))
+	for key, entry := range index {
+		metadatas[key] = entry.Metadata
+	}
+	return metadatas, true
+}
+
+// indexMatches 判断索引是否与目录一致，忽略键为 skip 的文件
+// 目录中的 Skill 文件与索引条目不一一对应，或有文件的大小、修改时间与条目记录不同时视为过期
+func (s *FileStore) indexMatches(index map[string]*indexEntry, skip string) bool {
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		return false
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if !s.isSkillFile(entry) {
+			continue
+		}
+		key := strings.TrimSuffix(entry.Name(), s.fileExt())
+		if key == skip {
+			continue
+		}
+		count++
+		info, err := entry.Info()
+		if err != nil || !index[key].matches(info) {
+			return false
+		}
+	}
+
+	if _, ok := index[skip]; ok && skip != "" {
+		count++
+	}
+	return len(index) == count
+}
+
+// updateIndex 将 key 的条目设为 entry（nil 表示删除）并原子地写回索引，调用方需持有写锁
+// 索引缺失、损坏或其他文件的条目已过期时先通过全量扫描重建
+func (s *FileStore) updateIndex(key string, entry *indexEntry) error {
+	index, err := s.readIndex()
+	if err != nil || !s.indexMatches(index, key) {
+		if index, err = s.scanIndex(); err != nil {
+			return err
+		}
+	}
+	if entry == nil {
+		delete(index, key)
+	} else {
+		index[key] = entry
+	}
+	return s.writeIndex(index)
+}
+
+// writeIndex 原子地写入索引文件
+func (s *FileStore) writeIndex(index map[string]*indexEntry) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := writeFileAtomic(s.indexPath(), data); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+	return nil
+}
+
+// isSkillFile 判断目录项是否为属于当前 Store 的 Skill 文件（排除索引文件，并按 NameFunc 过滤）
+func (s *FileStore) isSkillFile(entry os.DirEntry) bool {
+	if entry.IsDir() || !strings.HasSuffix(entry.Name(), s.fileExt()) || entry.Name() == indexFileName {
+		return false
+	}
+	if s.config.NameFunc != nil {
+		if _, ok := s.config.NameFunc(strings.TrimSuffix(entry.Name(), s.fileExt())); !ok {
+			return false // 不属于当前 Store 的文件
+		}
 	}
-	return filepath.Join(s.basePath, key+".json")
+	return true
 }
 
 // GetBasePath 获取存储的根目录路径
@@ -172,5 +800,7 @@ func (s *FileStore) GetBasePath() string {
 	return s.basePath
 }
 
-// Ensure FileStore implements SkillStore
+// Ensure FileStore implements SkillStore, PatchStore and SoftDeleteStore
 var _ SkillStore = (*FileStore)(nil)
+var _ PatchStore = (*FileStore)(nil)
+var _ SoftDeleteStore = (*FileStore)(nil)
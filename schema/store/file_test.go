@@ -2,11 +2,19 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
 )
 
 func TestFileStore_Get(t *testing.T) {
@@ -265,3 +273,546 @@ func TestFileStore_Persistence(t *testing.T) {
 		t.Errorf("Expected description 'This skill should persist', got '%s'", loaded.Metadata.Description)
 	}
 }
+
+func TestFileStore_PersistsBodyFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	store1, err := NewFileStore(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{
+			Name:       "v2_skill",
+			BodyFormat: schema.BodyFormatV2,
+		},
+		Body: `<script name="run"/>`,
+	}
+	if err := store1.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	store2, err := NewFileStore(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create second file store: %v", err)
+	}
+	loaded, err := store2.Get(ctx, "v2_skill")
+	if err != nil {
+		t.Fatalf("Failed to get skill from new store: %v", err)
+	}
+	if loaded.Metadata.BodyFormat != schema.BodyFormatV2 {
+		t.Errorf("Expected body format v2, got '%s'", loaded.Metadata.BodyFormat)
+	}
+}
+
+func TestFileStore_CanonicalJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewFileStore(tmpDir, WithCanonicalJSON())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{
+			Name:        "labeled_skill",
+			Description: "Skill with labels",
+			Labels: map[string]string{
+				"team":    "agent",
+				"env":     "prod",
+				"version": "v1",
+				"area":    "time",
+			},
+		},
+		Body: "Labeled body",
+	}
+
+	filePath := filepath.Join(tmpDir, "labeled_skill.json")
+
+	if err := store.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	first, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read skill file: %v", err)
+	}
+
+	if err := store.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill again: %v", err)
+	}
+	second, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read skill file: %v", err)
+	}
+
+	if string(first) != string(second) {
+		t.Errorf("Expected identical bytes, got:\n%s\n---\n%s", first, second)
+	}
+
+	// 与未开启规范化时的输出不同
+	plain, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	if err := plain.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	plainBytes, err := os.ReadFile(filepath.Join(plain.GetBasePath(), "labeled_skill.json"))
+	if err != nil {
+		t.Fatalf("Failed to read skill file: %v", err)
+	}
+	if string(first) == string(plainBytes) {
+		t.Errorf("Expected canonical output to differ from default output, got:\n%s", first)
+	}
+
+	// 结构体字段和标签键都应按字典序输出
+	content := string(first)
+	order := []string{`"body"`, `"description"`, `"labels"`, `"area"`, `"env"`, `"team"`, `"version"`, `"name"`}
+	last := -1
+	for _, key := range order {
+		idx := strings.Index(content, key)
+		if idx < 0 || idx < last {
+			t.Fatalf("Expected sorted keys, got:\n%s", content)
+		}
+		last = idx
+	}
+
+	// 应能正常读取
+	loaded, err := store.Get(ctx, "labeled_skill")
+	if err != nil {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+	if loaded.Metadata.Labels["team"] != "agent" {
+		t.Errorf("Expected label team=agent, got %v", loaded.Metadata.Labels)
+	}
+}
+
+func TestFileStore_ReferenceFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewFileStore(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "format_skill"},
+		References: []*resources.Reference{
+			{Name: "page", Body: "<h1>Title</h1>", Format: resources.ReferenceFormatHTML},
+			{Name: "guide", Body: "# Guide"},
+		},
+	}
+	if err := store.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	loaded, err := store.Get(ctx, "format_skill")
+	if err != nil {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+	if len(loaded.References) != 2 {
+		t.Fatalf("Expected 2 references, got %d", len(loaded.References))
+	}
+	if loaded.References[0].GetFormat() != resources.ReferenceFormatHTML {
+		t.Errorf("Expected html format, got '%s'", loaded.References[0].GetFormat())
+	}
+	// 未设置格式时视为 markdown
+	if loaded.References[1].GetFormat() != resources.ReferenceFormatMarkdown {
+		t.Errorf("Expected markdown format, got '%s'", loaded.References[1].GetFormat())
+	}
+}
+
+func TestFileStore_IntegrityCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewFileStore(tmpDir, WithIntegrityCheck())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	for _, name := range []string{"healthy", "broken"} {
+		skill := &schema.Skill{
+			Metadata: &schema.SkillMetadata{Name: name, Description: "Integrity test"},
+			Body:     "Body",
+		}
+		if err := store.Put(ctx, skill); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+	}
+
+	// 校验文件已写入
+	if _, err := os.Stat(filepath.Join(tmpDir, "healthy.json.sha256")); err != nil {
+		t.Fatalf("Expected checksum file: %v", err)
+	}
+
+	// 模拟部分写入导致的损坏
+	brokenPath := filepath.Join(tmpDir, "broken.json")
+	data, _ := os.ReadFile(brokenPath)
+	if err := os.WriteFile(brokenPath, data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+
+	if _, err := store.Get(ctx, "broken"); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt, got %v", err)
+	}
+	if _, err := store.Get(ctx, "healthy"); err != nil {
+		t.Errorf("Expected healthy skill to load, got %v", err)
+	}
+
+	// List 跳过损坏的文件
+	metadatas, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list skills: %v", err)
+	}
+	if len(metadatas) != 1 || metadatas[0].Name != "healthy" {
+		t.Errorf("Expected only healthy skill in list, got %v", metadatas)
+	}
+
+	// 删除时同时删除校验文件
+	if err := store.Delete(ctx, "healthy"); err != nil {
+		t.Fatalf("Failed to delete skill: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "healthy.json.sha256")); !os.IsNotExist(err) {
+		t.Error("Expected checksum file to be removed")
+	}
+}
+
+func TestFileStore_IntegrityToggle(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	checked, err := NewFileStore(tmpDir, WithIntegrityCheck())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	unchecked, err := NewFileStore(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	skill := &schema.Skill{Metadata: &schema.SkillMetadata{Name: "toggle"}, Body: "v1"}
+	if err := checked.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	// 未开启校验的写入删除旧的校验文件
+	skill.Body = "v2"
+	if err := unchecked.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "toggle.json.sha256")); !os.IsNotExist(err) {
+		t.Error("Expected stale checksum file to be removed")
+	}
+
+	// 再次开启校验时不会误报损坏
+	loaded, err := checked.Get(ctx, "toggle")
+	if err != nil {
+		t.Fatalf("Expected skill to load after integrity toggle, got %v", err)
+	}
+	if loaded.Body != "v2" {
+		t.Errorf("Expected body v2, got %s", loaded.Body)
+	}
+}
+
+func TestFileStore_Patch(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), WithIntegrityCheck())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	testConcurrentPatch(t, store)
+
+	// 原子写入不会遗留临时文件
+	entries, err := os.ReadDir(store.GetBasePath())
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Unexpected temp file left behind: %s", entry.Name())
+		}
+	}
+}
+
+func TestFileStore_SoftDelete(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), WithSoftDelete(), WithIndex(), WithIntegrityCheck())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	testSoftDelete(t, store)
+
+	// 重新打开后墓碑仍然存在
+	ctx := context.Background()
+	store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "persisted"}})
+	store.Delete(ctx, "persisted")
+	reopened, err := NewFileStore(store.GetBasePath(), WithSoftDelete())
+	if err != nil {
+		t.Fatalf("Failed to reopen file store: %v", err)
+	}
+	deleted, _ := reopened.ListDeleted(ctx)
+	if len(deleted) != 1 || deleted[0].Name != "persisted" {
+		t.Errorf("Expected persisted tombstone, got %+v", deleted)
+	}
+}
+
+func TestFileStore_ListFiltered(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	store, err := NewFileStore(tmpDir, WithNamespace("prod"))
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("skill_%02d", i)
+		if i < 3 {
+			name = fmt.Sprintf("admin_%02d", i)
+		}
+		if err := store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: name}}); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+	}
+	// 其他命名空间的文件不属于当前 Store
+	other, _ := NewFileStore(tmpDir, WithNamespace("dev"))
+	other.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "admin_99"}})
+
+	var reads []string
+	store.readFile = func(name string) ([]byte, error) {
+		reads = append(reads, filepath.Base(name))
+		return os.ReadFile(name)
+	}
+
+	metadatas, err := store.ListFiltered(ctx, func(name string) bool {
+		return strings.HasPrefix(name, "admin_")
+	})
+	if err != nil {
+		t.Fatalf("ListFiltered failed: %v", err)
+	}
+	if len(metadatas) != 3 || metadatas[0].Name != "admin_00" || metadatas[2].Name != "admin_02" {
+		t.Errorf("Expected admin_00..admin_02, got %+v", metadatas)
+	}
+	expectedReads := []string{"prod_admin_00.json", "prod_admin_01.json", "prod_admin_02.json"}
+	if !reflect.DeepEqual(reads, expectedReads) {
+		t.Errorf("Expected only matching files to be read, got %v", reads)
+	}
+}
+
+func TestFileStore_WithIndex(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir(), WithIndex())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	var reads []string
+	store.readFile = func(name string) ([]byte, error) {
+		reads = append(reads, filepath.Base(name))
+		return os.ReadFile(name)
+	}
+
+	for i := 0; i < 20; i++ {
+		skill := &schema.Skill{Metadata: &schema.SkillMetadata{Name: fmt.Sprintf("skill_%02d", i)}}
+		if err := store.Put(ctx, skill); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+	}
+
+	// List 只读取索引文件
+	reads = nil
+	metadatas, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(metadatas) != 20 || metadatas[0].Name != "skill_00" {
+		t.Errorf("Expected 20 sorted skills, got %d", len(metadatas))
+	}
+	if len(reads) != 1 || reads[0] != indexFileName {
+		t.Errorf("Expected only the index to be read, got %v", reads)
+	}
+
+	// Delete 更新索引
+	if err := store.Delete(ctx, "skill_00"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	reads = nil
+	metadatas, _ = store.List(ctx)
+	if len(metadatas) != 19 || len(reads) != 1 {
+		t.Errorf("Expected 19 skills from index, got %d skills and reads %v", len(metadatas), reads)
+	}
+
+	// 目录被直接修改后索引过期，回退到全量扫描
+	data, _ := json.Marshal(&schema.Skill{Metadata: &schema.SkillMetadata{Name: "manual"}})
+	if err := os.WriteFile(filepath.Join(store.GetBasePath(), "manual.json"), data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	reads = nil
+	metadatas, _ = store.List(ctx)
+	if len(metadatas) != 20 || len(reads) < 20 {
+		t.Errorf("Expected full scan of 20 files, got %d skills and %d reads", len(metadatas), len(reads))
+	}
+
+	// 重建索引后再次只读取索引
+	if err := store.RebuildIndex(ctx); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	reads = nil
+	metadatas, _ = store.List(ctx)
+	if len(metadatas) != 20 || len(reads) != 1 {
+		t.Errorf("Expected index read after rebuild, got %d skills and reads %v", len(metadatas), reads)
+	}
+
+	// 索引文件名被保留
+	if err := store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "_index"}}); err == nil {
+		t.Error("Expected error for reserved skill name")
+	}
+}
+
+func TestFileStore_WithIndexDetectsReplacedFile(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir(), WithIndex())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	for _, name := range []string{"alpha", "beta"} {
+		if err := store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: name, Description: "v1"}}); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+	}
+
+	// 文件数不变、修改时间早于索引的直接替换同样使索引过期
+	data, _ := json.Marshal(&schema.Skill{Metadata: &schema.SkillMetadata{Name: "alpha", Description: "replaced"}})
+	path := filepath.Join(store.GetBasePath(), "alpha.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	metadatas, err := store.List(ctx)
+	if err != nil || len(metadatas) != 2 || metadatas[0].Description != "replaced" {
+		t.Fatalf("Expected replaced description from scan, got %v (%v)", metadatas, err)
+	}
+
+	// 下一次写入时重建过期的索引，之后 List 再次只读取索引
+	if err := store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "gamma"}}); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+	var reads []string
+	store.readFile = func(name string) ([]byte, error) {
+		reads = append(reads, filepath.Base(name))
+		return os.ReadFile(name)
+	}
+	metadatas, _ = store.List(ctx)
+	if len(metadatas) != 3 || metadatas[0].Description != "replaced" || len(reads) != 1 {
+		t.Errorf("Expected rebuilt index with 3 skills, got %v and reads %v", metadatas, reads)
+	}
+}
+
+func TestFileStore_WithIndexConcurrentPut(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir(), WithIndex())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			skill := &schema.Skill{Metadata: &schema.SkillMetadata{Name: fmt.Sprintf("skill_%02d", i)}}
+			if err := store.Put(ctx, skill); err != nil {
+				t.Errorf("Failed to put skill: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	index, err := store.readIndex()
+	if err != nil {
+		t.Fatalf("readIndex failed: %v", err)
+	}
+	if len(index) != 20 {
+		t.Errorf("Expected 20 index entries, got %d", len(index))
+	}
+}
+
+func TestFileStore_WithSerializer(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	store, err := NewFileStore(tmpDir, WithSerializer(GobSerializer{}))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{
+			Name:        "gob_skill",
+			Description: "Stored with gob",
+			Labels:      map[string]string{"format": "gob"},
+		},
+		Body:       "Use <reference>guide</reference>",
+		References: []*resources.Reference{{Name: "guide", Body: "# Guide"}},
+		Assets:     []*resources.Asset{{Name: "logo", Bytes: []byte{1, 2, 3}, Ext: resources.PNG}},
+		// 函数脚本不会被序列化
+		Scripts: []resources.Script{resources.NewEasyScript("noop", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return input, nil
+		})},
+	}
+	if err := store.Put(ctx, skill); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "gob_skill.gob")); err != nil {
+		t.Errorf("Expected gob_skill.gob to exist: %v", err)
+	}
+
+	loaded, err := store.Get(ctx, "gob_skill")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Metadata, skill.Metadata) || loaded.Body != skill.Body {
+		t.Errorf("Expected metadata and body to round-trip, got %+v", loaded.Metadata)
+	}
+	if !reflect.DeepEqual(loaded.References, skill.References) || !reflect.DeepEqual(loaded.Assets, skill.Assets) {
+		t.Errorf("Expected references and assets to round-trip")
+	}
+	if len(loaded.Scripts) != 0 {
+		t.Errorf("Expected scripts to be dropped, got %d", len(loaded.Scripts))
+	}
+
+	// List 只解码元数据
+	metadatas, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(metadatas) != 1 || metadatas[0].Name != "gob_skill" {
+		t.Errorf("Expected [gob_skill], got %v", metadatas)
+	}
+	metadata, err := GobSerializer{}.UnmarshalMetadata(mustReadFile(t, filepath.Join(tmpDir, "gob_skill.gob")))
+	if err != nil || metadata.Description != "Stored with gob" {
+		t.Errorf("Expected metadata-only decode, got %v (%v)", metadata, err)
+	}
+
+	// 默认 JSON 格式保持不变
+	jsonDir := t.TempDir()
+	jsonStore, _ := NewFileStore(jsonDir)
+	jsonStore.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "plain"}, Body: "b"})
+	expected, _ := json.MarshalIndent(&schema.Skill{Metadata: &schema.SkillMetadata{Name: "plain"}, Body: "b"}, "", "  ")
+	if data := mustReadFile(t, filepath.Join(jsonDir, "plain.json")); string(data) != string(expected) {
+		t.Errorf("Expected default JSON output unchanged, got %s", data)
+	}
+}
+
+// mustReadFile 读取文件，失败时终止测试
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+)
+
+// LibraryProvider 从 Store 中一组"库" Skill 提供资源的提供者
+// 查找时按给定的名称顺序依次搜索各库 Skill，返回第一个匹配；List* 返回各库资源的并集（按库顺序去重）。
+// Store 中不存在的库被跳过；其他失败（库加载的 I/O 错误、资源加载错误、ctx 取消）立即返回，不会被当作"未找到"。
+// 库 Skill 在首次使用时从 Store 加载并缓存，加载失败不缓存，下次查找时重试；ClearCache 清空已加载的库
+type LibraryProvider struct {
+	store      SkillStore
+	skillNames []string
+
+	mu     sync.Mutex
+	loaded map[string]*schema.Skill
+}
+
+// NewLibraryProvider 创建一个从 store 中名为 skillNames 的 Skill 提供资源的提供者
+func NewLibraryProvider(store SkillStore, skillNames []string) *LibraryProvider {
+	return &LibraryProvider{
+		store:      store,
+		skillNames: append([]string(nil), skillNames...),
+		loaded:     make(map[string]*schema.Skill),
+	}
+}
+
+// library 返回已缓存的库 Skill，未缓存时从 Store 加载
+func (p *LibraryProvider) library(ctx context.Context, name string) (*schema.Skill, error) {
+	p.mu.Lock()
+	skill, ok := p.loaded[name]
+	p.mu.Unlock()
+	if ok {
+		return skill, nil
+	}
+
+	// 在锁外加载，避免慢 Store 阻塞其他库的查找
+	skill, err := p.store.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load library skill %s: %w", name, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached, ok := p.loaded[name]; ok {
+		return cached, nil
+	}
+	p.loaded[name] = skill
+	return skill, nil
+}
+
+// find 按库顺序查找名为 name 的资源，get 返回包装了 notFound 的错误时继续查找下一个库
+// 都未找到时返回包装了 notFound 的错误，并附带不存在的库；其他错误立即返回
+func find[T any](ctx context.Context, p *LibraryProvider, notFound error, name string, get func(skill *schema.Skill) (T, error)) (T, error) {
+	var zero T
+	var missing []error
+	for _, library := range p.skillNames {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		skill, err := p.library(ctx, library)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				return zero, err
+			}
+			missing = append(missing, err)
+			continue
+		}
+		v, err := get(skill)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, notFound) {
+			return zero, fmt.Errorf("library skill %s: %w", library, err)
+		}
+	}
+	return zero, errors.Join(append([]error{fmt.Errorf("%w: %s", notFound, name)}, missing...)...)
+}
+
+// GetScript 按库顺序查找脚本
+func (p *LibraryProvider) GetScript(ctx context.Context, name string) (resources.Script, error) {
+	return find(ctx, p, resources.ErrScriptNotFound, name, func(skill *schema.Skill) (resources.Script, error) {
+		return skill.GetScript(ctx, name)
+	})
+}
+
+// GetReference 按库顺序查找参考文档
+func (p *LibraryProvider) GetReference(ctx context.Context, name string) (string, error) {
+	return find(ctx, p, resources.ErrReferenceNotFound, name, func(skill *schema.Skill) (string, error) {
+		return skill.ReadReferenceContext(ctx, name)
+	})
+}
+
+// GetAsset 按库顺序查找资源文件
+func (p *LibraryProvider) GetAsset(ctx context.Context, name string) (*resources.Asset, error) {
+	return find(ctx, p, resources.ErrAssetNotFound, name, func(skill *schema.Skill) (*resources.Asset, error) {
+		return skill.GetAsset(ctx, name)
+	})
+}
+
+// ListScripts 合并所有库的脚本名称（见 Skill.AvailableScripts）
+func (p *LibraryProvider) ListScripts(ctx context.Context) ([]string, error) {
+	return p.union(ctx, (*schema.Skill).AvailableScripts)
+}
+
+// ListReferences 合并所有库的参考文档名称
+func (p *LibraryProvider) ListReferences(ctx context.Context) ([]string, error) {
+	return p.union(ctx, (*schema.Skill).AvailableReferences)
+}
+
+// ListAssets 合并所有库的资源文件名称
+func (p *LibraryProvider) ListAssets(ctx context.Context) ([]string, error) {
+	return p.union(ctx, (*schema.Skill).AvailableAssets)
+}
+
+// union 按库顺序合并名称并去重，跳过 Store 中不存在的库
+// 库 Skill 的 Provider 列举失败时仍返回已列出的名称，同时返回合并后的错误；加载库的其他失败立即返回
+func (p *LibraryProvider) union(ctx context.Context, list func(skill *schema.Skill, ctx context.Context) ([]string, error)) ([]string, error) {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	var errs []error
+	for _, name := range p.skillNames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		skill, err := p.library(ctx, name)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		listed, err := list(skill, ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("library skill %s: %w", name, err))
+		}
+		for _, n := range listed {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	return names, errors.Join(errs...)
+}
+
+// ClearCache 清空已加载的库 Skill，下次查找时重新从 Store 加载
+func (p *LibraryProvider) ClearCache() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loaded = make(map[string]*schema.Skill)
+}
+
+// Ensure LibraryProvider implements ResourceProvider and Clearable
+var _ resources.ResourceProvider = (*LibraryProvider)(nil)
+var _ resources.Clearable = (*LibraryProvider)(nil)
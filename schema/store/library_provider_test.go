@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+)
+
+// countingStore 记录 Get 调用次数
+type countingStore struct {
+	SkillStore
+	gets map[string]int
+}
+
+func (s *countingStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	s.gets[name]++
+	return s.SkillStore.Get(ctx, name)
+}
+
+func TestLibraryProvider(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryStore()
+	mem.Put(ctx, &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "lib_a"},
+		Scripts: []resources.Script{resources.NewEasyScript("echo", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return input, nil
+		})},
+		References: []*resources.Reference{{Name: "intro", Body: "intro from a"}},
+	})
+	mem.Put(ctx, &schema.Skill{
+		Metadata:   &schema.SkillMetadata{Name: "lib_b"},
+		References: []*resources.Reference{{Name: "intro", Body: "intro from b"}, {Name: "guide", Body: "guide from b"}},
+		Assets:     []*resources.Asset{{Name: "logo.png", Bytes: []byte{1}}},
+	})
+	st := &countingStore{SkillStore: mem, gets: make(map[string]int)}
+	provider := NewLibraryProvider(st, []string{"lib_missing", "lib_a", "lib_b"})
+
+	// 从第二个库解析参考文档
+	guide, err := provider.GetReference(ctx, "guide")
+	if err != nil || guide != "guide from b" {
+		t.Errorf("Expected guide from b, got %q (%v)", guide, err)
+	}
+
+	// 按给定顺序搜索，返回第一个匹配
+	intro, err := provider.GetReference(ctx, "intro")
+	if err != nil || intro != "intro from a" {
+		t.Errorf("Expected intro from a, got %q (%v)", intro, err)
+	}
+	if _, err := provider.GetScript(ctx, "echo"); err != nil {
+		t.Errorf("Expected echo script, got %v", err)
+	}
+	if asset, err := provider.GetAsset(ctx, "logo.png"); err != nil || asset.Name != "logo.png" {
+		t.Errorf("Expected logo.png asset, got %v (%v)", asset, err)
+	}
+
+	// 库 Skill 只加载一次；加载失败的库不缓存
+	if st.gets["lib_a"] != 1 || st.gets["lib_b"] != 1 {
+		t.Errorf("Expected each library loaded once, got %v", st.gets)
+	}
+	if st.gets["lib_missing"] < 2 {
+		t.Errorf("Expected missing library to be retried, got %d loads", st.gets["lib_missing"])
+	}
+
+	// 未找到时返回对应的 NotFound 错误，并附带库加载失败的原因
+	_, err = provider.GetReference(ctx, "nope")
+	if !errors.Is(err, resources.ErrReferenceNotFound) {
+		t.Errorf("Expected ErrReferenceNotFound, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "lib_missing") {
+		t.Errorf("Expected error to mention the failed library, got %v", err)
+	}
+	if _, err := provider.GetScript(ctx, "nope"); !errors.Is(err, resources.ErrScriptNotFound) {
+		t.Errorf("Expected ErrScriptNotFound, got %v", err)
+	}
+
+	refs, err := provider.ListReferences(ctx)
+	if err != nil || !reflect.DeepEqual(refs, []string{"intro", "guide"}) {
+		t.Errorf("Expected [intro guide], got %v (%v)", refs, err)
+	}
+	scripts, _ := provider.ListScripts(ctx)
+	if !reflect.DeepEqual(scripts, []string{"echo"}) {
+		t.Errorf("Expected [echo], got %v", scripts)
+	}
+	assets, _ := provider.ListAssets(ctx)
+	if !reflect.DeepEqual(assets, []string{"logo.png"}) {
+		t.Errorf("Expected [logo.png], got %v", assets)
+	}
+
+	provider.ClearCache()
+	provider.GetReference(ctx, "guide")
+	if st.gets["lib_b"] != 2 {
+		t.Errorf("Expected lib_b reloaded after ClearCache, got %d loads", st.gets["lib_b"])
+	}
+}
+
+// funcStore 用给定函数实现 Get
+type funcStore struct {
+	SkillStore
+	get func(name string) (*schema.Skill, error)
+}
+
+func (s *funcStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	return s.get(name)
+}
+
+func TestLibraryProvider_PropagatesErrors(t *testing.T) {
+	ctx := context.Background()
+	ioErr := errors.New("disk failure")
+	providerErr := errors.New("provider unavailable")
+
+	loadErr := errors.New("reference unavailable")
+	broken := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "lib_broken"},
+		References: []*resources.Reference{resources.NewLazyReference("intro", func(ctx context.Context) (string, error) {
+			return "", loadErr
+		})},
+	}
+	broken.SetProvider(resources.NewLazyLoadingProvider(func(ctx context.Context) (resources.ResourceProvider, error) {
+		return nil, providerErr
+	}))
+	skills := map[string]*schema.Skill{"lib_broken": broken}
+	st := &funcStore{SkillStore: NewMemoryStore(), get: func(name string) (*schema.Skill, error) {
+		if name == "lib_io" {
+			return nil, ioErr
+		}
+		if skill, ok := skills[name]; ok {
+			return skill, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}}
+
+	// 非"未找到"的库加载错误立即返回
+	provider := NewLibraryProvider(st, []string{"lib_missing", "lib_io"})
+	if _, err := provider.GetReference(ctx, "intro"); !errors.Is(err, ioErr) || errors.Is(err, resources.ErrReferenceNotFound) {
+		t.Errorf("Expected load error, got %v", err)
+	}
+	if _, err := provider.ListAssets(ctx); !errors.Is(err, ioErr) {
+		t.Errorf("Expected load error from ListAssets, got %v", err)
+	}
+
+	// 资源加载错误立即返回，列举错误随结果一并返回
+	provider = NewLibraryProvider(st, []string{"lib_missing", "lib_broken"})
+	if _, err := provider.GetReference(ctx, "intro"); !errors.Is(err, loadErr) || errors.Is(err, resources.ErrReferenceNotFound) {
+		t.Errorf("Expected reference load error, got %v", err)
+	}
+	if _, err := provider.ListScripts(ctx); !errors.Is(err, providerErr) {
+		t.Errorf("Expected provider error from ListScripts, got %v", err)
+	}
+
+	// ctx 取消时返回 ctx 错误
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := provider.GetReference(cancelled, "intro"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
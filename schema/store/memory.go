@@ -3,7 +3,10 @@ package store
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/alois132/skill/schema"
 	"github.com/alois132/skill/schema/resources"
@@ -12,9 +15,10 @@ import (
 // MemoryStore 内存中的 Skill 存储实现
 // 适用于测试和开发环境，数据不会持久化
 type MemoryStore struct {
-	mu     sync.RWMutex
-	skills map[string]*schema.Skill
-	config *StoreConfig
+	mu      sync.RWMutex
+	skills  map[string]*schema.Skill
+	deleted map[string]*schema.Skill // 软删除的墓碑
+	config  *StoreConfig
 }
 
 // NewMemoryStore 创建一个新的内存 Skill 存储
@@ -25,8 +29,9 @@ func NewMemoryStore(opts ...StoreOption) *MemoryStore {
 	}
 
 	return &MemoryStore{
-		skills: make(map[string]*schema.Skill),
-		config: config,
+		skills:  make(map[string]*schema.Skill),
+		deleted: make(map[string]*schema.Skill),
+		config:  config,
 	}
 }
 
@@ -38,27 +43,37 @@ func (s *MemoryStore) Get(ctx context.Context, name string) (*schema.Skill, erro
 	key := s.key(name)
 	skill, ok := s.skills[key]
 	if !ok {
-		return nil, errors.New("skill not found: " + name)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
 	}
 
 	// 返回副本以避免外部修改
 	return s.copySkill(skill), nil
 }
 
-// List 列出所有可用的 Skill 元数据
+// List 列出所有可用的 Skill 元数据，按存储键（含命名空间）排序
 func (s *MemoryStore) List(ctx context.Context) ([]*schema.SkillMetadata, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	metadatas := make([]*schema.SkillMetadata, 0, len(s.skills))
-	for _, skill := range s.skills {
-		if skill.Metadata != nil {
+	for _, key := range s.sortedKeys() {
+		if skill := s.skills[key]; skill.Metadata != nil {
 			metadatas = append(metadatas, skill.Metadata)
 		}
 	}
 	return metadatas, nil
 }
 
+// sortedKeys 返回按存储键排序的所有 Skill 键，调用方需持有锁
+func (s *MemoryStore) sortedKeys() []string {
+	keys := make([]string, 0, len(s.skills))
+	for key := range s.skills {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Put 保存 Skill 到内存
 func (s *MemoryStore) Put(ctx context.Context, skill *schema.Skill) error {
 	if skill == nil {
@@ -73,20 +88,117 @@ func (s *MemoryStore) Put(ctx context.Context, skill *schema.Skill) error {
 
 	key := s.key(skill.Metadata.Name)
 	s.skills[key] = s.copySkill(skill)
+	s.config.publish(ctx, skill.Metadata.Name, SkillOpPut)
+	return nil
+}
+
+// Patch 在锁内对 Skill 的副本应用 fn，成功后替换存储的版本
+func (s *MemoryStore) Patch(ctx context.Context, name string, fn func(skill *schema.Skill) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(name)
+	stored, ok := s.skills[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	// 元数据为指针，单独拷贝以免 fn 失败时修改已存储的版本
+	skill := s.copySkill(stored)
+	skill.Metadata = stored.Metadata.Clone()
+
+	if err := fn(skill); err != nil {
+		return err
+	}
+	if skill.Metadata == nil || skill.Metadata.Name != name {
+		return errors.New("patch cannot rename skill: " + name)
+	}
+
+	s.skills[key] = s.copySkill(skill)
+	s.config.publish(ctx, name, SkillOpPut)
 	return nil
 }
 
 // Delete 从内存中删除指定名称的 Skill
+// 开启软删除时将其移入墓碑，而不是丢弃
 func (s *MemoryStore) Delete(ctx context.Context, name string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	key := s.key(name)
-	if _, ok := s.skills[key]; !ok {
-		return errors.New("skill not found: " + name)
+	skill, ok := s.skills[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	if s.config.SoftDelete {
+		tombstone := s.copySkill(skill)
+		tombstone.Metadata = skill.Metadata.Clone()
+		now := time.Now()
+		tombstone.Metadata.Deleted = true
+		tombstone.Metadata.DeletedAt = &now
+		s.deleted[key] = tombstone
 	}
 
 	delete(s.skills, key)
+	s.config.publish(ctx, name, SkillOpDelete)
+	return nil
+}
+
+// Restore 恢复被软删除的 Skill
+func (s *MemoryStore) Restore(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(name)
+	tombstone, ok := s.deleted[key]
+	if !ok {
+		return errors.New("deleted skill not found: " + name)
+	}
+	if _, ok := s.skills[key]; ok {
+		return errors.New("skill already exists: " + name)
+	}
+
+	restored := s.copySkill(tombstone)
+	restored.Metadata = tombstone.Metadata.Clone()
+	restored.Metadata.Deleted = false
+	restored.Metadata.DeletedAt = nil
+	s.skills[key] = restored
+	delete(s.deleted, key)
+	s.config.publish(ctx, name, SkillOpPut)
+	return nil
+}
+
+// ListDeleted 列出所有被软删除的 Skill 元数据，按名称排序
+func (s *MemoryStore) ListDeleted(ctx context.Context) ([]*schema.SkillMetadata, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	metadatas := make([]*schema.SkillMetadata, 0, len(s.deleted))
+	for _, skill := range s.deleted {
+		metadatas = append(metadatas, skill.Metadata.Clone())
+	}
+	sort.Slice(metadatas, func(i, j int) bool {
+		return metadatas[i].Name < metadatas[j].Name
+	})
+	return metadatas, nil
+}
+
+// Purge 彻底删除指定名称的 Skill 及其墓碑
+func (s *MemoryStore) Purge(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(name)
+	_, live := s.skills[key]
+	_, tombstoned := s.deleted[key]
+	if !live && !tombstoned {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	delete(s.skills, key)
+	delete(s.deleted, key)
+	s.config.publish(ctx, name, SkillOpDelete)
 	return nil
 }
 
@@ -102,6 +214,9 @@ func (s *MemoryStore) Exists(ctx context.Context, name string) (bool, error) {
 
 // key 生成存储键
 func (s *MemoryStore) key(name string) string {
+	if s.config.KeyFunc != nil {
+		return s.config.KeyFunc(s.config.Namespace, name)
+	}
 	if s.config.Namespace != "" {
 		return s.config.Namespace + "/" + name
 	}
@@ -118,8 +233,9 @@ func (s *MemoryStore) copySkill(skill *schema.Skill) *schema.Skill {
 
 	// 创建新的 Skill 实例
 	copied := &schema.Skill{
-		Metadata: skill.Metadata,
-		Body:     skill.Body,
+		Metadata:      skill.Metadata,
+		Body:          skill.Body,
+		RecoverPanics: skill.RecoverPanics,
 	}
 
 	// 拷贝 Scripts 切片（浅拷贝，元素是接口）
@@ -150,6 +266,7 @@ func (s *MemoryStore) copySkill(skill *schema.Skill) *schema.Skill {
 }
 
 // GetAll 获取所有 Skills（仅用于测试）
+// 返回以存储键为键的 map，遍历顺序不固定；需要稳定顺序时对键排序或使用 List
 func (s *MemoryStore) GetAll() map[string]*schema.Skill {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -167,7 +284,10 @@ func (s *MemoryStore) Clear() {
 	defer s.mu.Unlock()
 
 	s.skills = make(map[string]*schema.Skill)
+	s.deleted = make(map[string]*schema.Skill)
 }
 
-// Ensure MemoryStore implements SkillStore
+// Ensure MemoryStore implements SkillStore, PatchStore and SoftDeleteStore
 var _ SkillStore = (*MemoryStore)(nil)
+var _ PatchStore = (*MemoryStore)(nil)
+var _ SoftDeleteStore = (*MemoryStore)(nil)
@@ -2,6 +2,10 @@ package store
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/alois132/skill/schema"
@@ -81,6 +85,32 @@ func TestMemoryStore_List(t *testing.T) {
 	}
 }
 
+func TestMemoryStore_ListSorted(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for _, name := range []string{"delta", "alpha", "charlie", "echo", "bravo"} {
+		if err := store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: name}}); err != nil {
+			t.Fatalf("Failed to put skill: %v", err)
+		}
+	}
+
+	want := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+	for i := 0; i < 5; i++ {
+		metadatas, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+		got := make([]string, len(metadatas))
+		for j, metadata := range metadatas {
+			got[j] = metadata.Name
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
 func TestMemoryStore_Put(t *testing.T) {
 	ctx := context.Background()
 	store := NewMemoryStore()
@@ -308,3 +338,214 @@ func TestMemoryStore_WithScriptsAndReferences(t *testing.T) {
 		t.Errorf("Expected 1 reference, got %d", len(loaded.References))
 	}
 }
+
+func TestMemoryStore_WithKeyFunc(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(
+		WithNamespace("myapp"),
+		WithKeyFunc(func(namespace, name string) string {
+			return namespace + ":" + strings.ToUpper(name)
+		}),
+	)
+
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "custom"},
+		Body:     "Body",
+	}
+	if err := store.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	// 存储键使用自定义映射
+	all := store.GetAll()
+	if _, ok := all["myapp:CUSTOM"]; !ok {
+		t.Errorf("Expected key 'myapp:CUSTOM', got keys %v", all)
+	}
+
+	// Get/Exists/Delete 使用相同的映射
+	if _, err := store.Get(ctx, "custom"); err != nil {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+	if exists, _ := store.Exists(ctx, "custom"); !exists {
+		t.Error("Expected skill to exist")
+	}
+	if err := store.Delete(ctx, "custom"); err != nil {
+		t.Fatalf("Failed to delete skill: %v", err)
+	}
+	if exists, _ := store.Exists(ctx, "custom"); exists {
+		t.Error("Expected skill to be deleted")
+	}
+}
+
+// patchableStore 同时支持 SkillStore 和 PatchStore 的存储
+type patchableStore interface {
+	SkillStore
+	PatchStore
+}
+
+// testConcurrentPatch 并发执行两个追加参考文档的 Patch，验证两者都被保留
+func testConcurrentPatch(t *testing.T, store patchableStore) {
+	ctx := context.Background()
+	if err := store.Put(ctx, &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "patched", Description: "before"},
+	}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"ref_a", "ref_b"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := store.Patch(ctx, "patched", func(skill *schema.Skill) error {
+				skill.References = append(skill.References, &resources.Reference{Name: name, Body: name})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Patch failed: %v", err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	skill, err := store.Get(ctx, "patched")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(skill.References) != 2 {
+		t.Fatalf("Expected 2 references, got %d", len(skill.References))
+	}
+
+	// fn 返回错误时不写入
+	patchErr := errors.New("abort")
+	err = store.Patch(ctx, "patched", func(skill *schema.Skill) error {
+		skill.Metadata.Description = "after"
+		return patchErr
+	})
+	if err != patchErr {
+		t.Errorf("Expected patch error, got %v", err)
+	}
+	skill, _ = store.Get(ctx, "patched")
+	if skill.Metadata.Description != "before" {
+		t.Errorf("Expected description to be unchanged, got %s", skill.Metadata.Description)
+	}
+
+	// 不存在的 Skill
+	if err := store.Patch(ctx, "missing", func(skill *schema.Skill) error { return nil }); err == nil {
+		t.Error("Expected error for non-existent skill")
+	}
+}
+
+func TestMemoryStore_Patch(t *testing.T) {
+	testConcurrentPatch(t, NewMemoryStore())
+}
+
+// softDeletableStore 同时支持 SkillStore 和 SoftDeleteStore 的存储
+type softDeletableStore interface {
+	SkillStore
+	SoftDeleteStore
+}
+
+// testSoftDelete 验证软删除后恢复、以及软删除后彻底删除的流程
+func testSoftDelete(t *testing.T, store softDeletableStore) {
+	ctx := context.Background()
+	for _, name := range []string{"kept", "restored", "purged"} {
+		if err := store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: name}, Body: name + " body"}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	// delete -> restore
+	if err := store.Delete(ctx, "restored"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(ctx, "restored"); err == nil {
+		t.Error("Expected soft-deleted skill to be hidden from Get")
+	}
+	if exists, _ := store.Exists(ctx, "restored"); exists {
+		t.Error("Expected soft-deleted skill to be hidden from Exists")
+	}
+	metadatas, _ := store.List(ctx)
+	if len(metadatas) != 2 {
+		t.Errorf("Expected 2 listed skills, got %d", len(metadatas))
+	}
+	deleted, err := store.ListDeleted(ctx)
+	if err != nil {
+		t.Fatalf("ListDeleted failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].Name != "restored" || !deleted[0].Deleted || deleted[0].DeletedAt == nil {
+		t.Errorf("Expected tombstone for restored, got %+v", deleted)
+	}
+
+	if err := store.Restore(ctx, "restored"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	skill, err := store.Get(ctx, "restored")
+	if err != nil {
+		t.Fatalf("Get after restore failed: %v", err)
+	}
+	if skill.Body != "restored body" || skill.Metadata.Deleted || skill.Metadata.DeletedAt != nil {
+		t.Errorf("Expected restored skill without tombstone, got %+v", skill.Metadata)
+	}
+	if deleted, _ := store.ListDeleted(ctx); len(deleted) != 0 {
+		t.Errorf("Expected no tombstones after restore, got %d", len(deleted))
+	}
+	if err := store.Restore(ctx, "restored"); err == nil {
+		t.Error("Expected error restoring a skill that is not deleted")
+	}
+
+	// delete -> purge
+	if err := store.Delete(ctx, "purged"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Purge(ctx, "purged"); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if deleted, _ := store.ListDeleted(ctx); len(deleted) != 0 {
+		t.Errorf("Expected no tombstones after purge, got %d", len(deleted))
+	}
+	if err := store.Restore(ctx, "purged"); err == nil {
+		t.Error("Expected error restoring a purged skill")
+	}
+	if err := store.Purge(ctx, "purged"); err == nil {
+		t.Error("Expected error purging a missing skill")
+	}
+
+	// Purge 也可以直接硬删除未删除的 Skill
+	if err := store.Purge(ctx, "kept"); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if exists, _ := store.Exists(ctx, "kept"); exists {
+		t.Error("Expected purged skill to be gone")
+	}
+}
+
+func TestMemoryStore_SoftDelete(t *testing.T) {
+	testSoftDelete(t, NewMemoryStore(WithSoftDelete()))
+
+	// 未开启软删除时 Delete 不保留墓碑
+	ctx := context.Background()
+	store := NewMemoryStore()
+	store.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "gone"}})
+	store.Delete(ctx, "gone")
+	if deleted, _ := store.ListDeleted(ctx); len(deleted) != 0 {
+		t.Errorf("Expected no tombstones without soft delete, got %d", len(deleted))
+	}
+}
+
+func TestStores_ErrNotFound(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	for name, store := range map[string]SkillStore{"memory": NewMemoryStore(), "file": fileStore} {
+		if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound from Get, got %v", name, err)
+		}
+		if err := store.Delete(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound from Delete, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alois132/skill/schema"
+)
+
+// SkillQuery Skill 元数据的查询条件，所有非空条件都需满足；零值匹配所有 Skill
+type SkillQuery struct {
+	NamePrefix          string            // 名称前缀
+	NameContains        string            // 名称包含的子串
+	DescriptionContains string            // 描述包含的子串
+	Labels              map[string]string // 标签选择器：每个键值对都需与 Skill 的标签相等
+	CaseInsensitive     bool              // 名称和描述匹配时忽略大小写（不影响标签）
+}
+
+// SearchableStore 支持服务端查询的可选接口
+// 未实现时由调用方对 List 的结果按 SkillQuery.Match 过滤
+type SearchableStore interface {
+	// Search 返回满足查询条件的 Skill 元数据
+	Search(ctx context.Context, query SkillQuery) ([]*schema.SkillMetadata, error)
+}
+
+// Match 判断元数据是否满足查询条件，nil 元数据不匹配
+func (q SkillQuery) Match(metadata *schema.SkillMetadata) bool {
+	if metadata == nil {
+		return false
+	}
+
+	name, desc := metadata.Name, metadata.Description
+	prefix, nameSub, descSub := q.NamePrefix, q.NameContains, q.DescriptionContains
+	if q.CaseInsensitive {
+		name, desc = strings.ToLower(name), strings.ToLower(desc)
+		prefix, nameSub, descSub = strings.ToLower(prefix), strings.ToLower(nameSub), strings.ToLower(descSub)
+	}
+	if !strings.HasPrefix(name, prefix) || !strings.Contains(name, nameSub) || !strings.Contains(desc, descSub) {
+		return false
+	}
+
+	for key, value := range q.Labels {
+		if actual, ok := metadata.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter 返回 metadatas 中满足查询条件的元数据，保持原有顺序
+func (q SkillQuery) Filter(metadatas []*schema.SkillMetadata) []*schema.SkillMetadata {
+	result := make([]*schema.SkillMetadata, 0, len(metadatas))
+	for _, metadata := range metadatas {
+		if q.Match(metadata) {
+			result = append(result, metadata)
+		}
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alois132/skill/schema"
+)
+
+// ErrReadOnly 对只读 Store 执行写操作时返回
+var ErrReadOnly = errors.New("skill store is read-only")
+
+// ReadOnlyStore 只读的 Skill 存储包装器
+// 读操作委托给内部 Store，写操作返回 ErrReadOnly，可安全地交给不受信任的组件
+type ReadOnlyStore struct {
+	inner SkillStore
+}
+
+// NewReadOnlyStore 创建一个新的只读 Skill 存储
+func NewReadOnlyStore(inner SkillStore) *ReadOnlyStore {
+	return &ReadOnlyStore{inner: inner}
+}
+
+// Get 从内部 Store 获取指定名称的 Skill
+func (s *ReadOnlyStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	return s.inner.Get(ctx, name)
+}
+
+// List 列出内部 Store 中的 Skill 元数据
+func (s *ReadOnlyStore) List(ctx context.Context) ([]*schema.SkillMetadata, error) {
+	return s.inner.List(ctx)
+}
+
+// Put 始终返回 ErrReadOnly
+func (s *ReadOnlyStore) Put(ctx context.Context, skill *schema.Skill) error {
+	return ErrReadOnly
+}
+
+// Delete 始终返回 ErrReadOnly
+func (s *ReadOnlyStore) Delete(ctx context.Context, name string) error {
+	return ErrReadOnly
+}
+
+// Exists 检查内部 Store 中是否存在指定名称的 Skill
+func (s *ReadOnlyStore) Exists(ctx context.Context, name string) (bool, error) {
+	return s.inner.Exists(ctx, name)
+}
+
+// Ensure ReadOnlyStore implements SkillStore
+var _ SkillStore = (*ReadOnlyStore)(nil)
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alois132/skill/schema"
+)
+
+func TestReadOnlyStore(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryStore()
+	skill := &schema.Skill{
+		Metadata: &schema.SkillMetadata{Name: "shared", Description: "Shared skill"},
+		Body:     "Body",
+	}
+	if err := inner.Put(ctx, skill); err != nil {
+		t.Fatalf("Failed to put skill: %v", err)
+	}
+
+	store := NewReadOnlyStore(inner)
+
+	// 读操作正常
+	loaded, err := store.Get(ctx, "shared")
+	if err != nil || loaded.Metadata.Name != "shared" {
+		t.Fatalf("Failed to get skill: %v", err)
+	}
+	metadatas, err := store.List(ctx)
+	if err != nil || len(metadatas) != 1 {
+		t.Fatalf("Expected 1 skill in list, got %v (%v)", metadatas, err)
+	}
+	if exists, err := store.Exists(ctx, "shared"); err != nil || !exists {
+		t.Errorf("Expected skill to exist, got %v (%v)", exists, err)
+	}
+
+	// 写操作返回 ErrReadOnly
+	if err := store.Put(ctx, skill); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from Put, got %v", err)
+	}
+	if err := store.Delete(ctx, "shared"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from Delete, got %v", err)
+	}
+
+	// 内部 Store 未被修改
+	if exists, _ := inner.Exists(ctx, "shared"); !exists {
+		t.Error("Expected inner store to be unchanged")
+	}
+}
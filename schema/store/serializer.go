@@ -0,0 +1,143 @@
+package store
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/alois132/skill/schema"
+	"github.com/alois132/skill/schema/resources"
+)
+
+// Serializer Skill 的序列化格式
+// 脚本通常是函数，无法序列化，由实现决定如何处理（内置实现不恢复脚本）
+type Serializer interface {
+	// Marshal 序列化 Skill
+	Marshal(skill *schema.Skill) ([]byte, error)
+	// Unmarshal 反序列化 Skill
+	Unmarshal(data []byte) (*schema.Skill, error)
+	// UnmarshalMetadata 只解析元数据，用于 List 等只需要元数据的场景，应尽量避免解析完整内容
+	UnmarshalMetadata(data []byte) (*schema.SkillMetadata, error)
+}
+
+// FileExtSerializer 可选接口：声明文件存储使用的扩展名（含 "."），未实现时使用 ".json"
+type FileExtSerializer interface {
+	FileExt() string
+}
+
+// WithSerializer 设置 Skill 的序列化格式，默认为 JSONSerializer，目前仅 FileStore 使用
+func WithSerializer(s Serializer) StoreOption {
+	return func(c *StoreConfig) {
+		c.Serializer = s
+	}
+}
+
+// JSONSerializer 默认的 JSON 序列化格式（缩进输出）
+// 脚本按 JSON 编码写出，但无法反序列化回函数；读取时 scripts 字段需为空
+type JSONSerializer struct {
+	Canonical bool // 使用规范化 JSON（所有对象键排序）
+}
+
+// Marshal 序列化 Skill
+func (s JSONSerializer) Marshal(skill *schema.Skill) ([]byte, error) {
+	if s.Canonical {
+		return marshalCanonical(skill)
+	}
+	return json.MarshalIndent(skill, "", "  ")
+}
+
+// Unmarshal 反序列化 Skill
+func (s JSONSerializer) Unmarshal(data []byte) (*schema.Skill, error) {
+	var skill schema.Skill
+	if err := json.Unmarshal(data, &skill); err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+// UnmarshalMetadata 只解析 metadata 字段，其他字段不会被解码
+func (s JSONSerializer) UnmarshalMetadata(data []byte) (*schema.SkillMetadata, error) {
+	var doc struct {
+		Metadata *schema.SkillMetadata `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Metadata, nil
+}
+
+// FileExt 返回 ".json"
+func (s JSONSerializer) FileExt() string {
+	return ".json"
+}
+
+// GobSerializer 基于 encoding/gob 的紧凑二进制格式
+// 元数据与其余内容分两段编码，UnmarshalMetadata 只解码第一段；脚本不写入
+type GobSerializer struct{}
+
+// gobContent gob 格式中元数据之后的内容
+type gobContent struct {
+	Body       string
+	References []*resources.Reference
+	Assets     []*resources.Asset
+}
+
+// Marshal 序列化 Skill，脚本被忽略
+func (GobSerializer) Marshal(skill *schema.Skill) ([]byte, error) {
+	if skill.Metadata == nil {
+		return nil, errors.New("skill metadata cannot be nil")
+	}
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(skill.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	content := gobContent{Body: skill.Body, References: skill.References, Assets: skill.Assets}
+	if err := enc.Encode(&content); err != nil {
+		return nil, fmt.Errorf("failed to encode content: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 反序列化 Skill
+func (GobSerializer) Unmarshal(data []byte) (*schema.Skill, error) {
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	var metadata schema.SkillMetadata
+	if err := dec.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	var content gobContent
+	if err := dec.Decode(&content); err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+	return &schema.Skill{
+		Metadata:   &metadata,
+		Body:       content.Body,
+		References: content.References,
+		Assets:     content.Assets,
+	}, nil
+}
+
+// UnmarshalMetadata 只解码元数据段
+func (GobSerializer) UnmarshalMetadata(data []byte) (*schema.SkillMetadata, error) {
+	var metadata schema.SkillMetadata
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// FileExt 返回 ".gob"
+func (GobSerializer) FileExt() string {
+	return ".gob"
+}
+
+// Ensure JSONSerializer and GobSerializer implement Serializer and FileExtSerializer
+var (
+	_ Serializer        = JSONSerializer{}
+	_ Serializer        = GobSerializer{}
+	_ FileExtSerializer = JSONSerializer{}
+	_ FileExtSerializer = GobSerializer{}
+)
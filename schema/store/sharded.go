@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/alois132/skill/schema"
+)
+
+// shardReplicas 每个分片在哈希环上的虚拟节点数
+const shardReplicas = 100
+
+// ShardedStore 按 Skill 名称将数据分片到多个后端的存储
+// 使用一致性哈希选择分片，同一名称总是路由到同一分片；List 汇总所有分片的结果
+type ShardedStore struct {
+	shards []SkillStore
+	hashFn func(name string) uint64
+	ring   []shardPoint // 按 hash 排序的虚拟节点
+}
+
+// shardPoint 哈希环上的虚拟节点
+type shardPoint struct {
+	hash  uint64
+	shard int
+}
+
+// NewShardedStore 创建一个新的分片存储
+// hashFn 为空时使用 FNV-1a 64 位哈希
+func NewShardedStore(shards []SkillStore, hashFn func(name string) uint64) (*ShardedStore, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("sharded store requires at least one shard")
+	}
+	if hashFn == nil {
+		hashFn = fnv64a
+	}
+
+	s := &ShardedStore{
+		shards: shards,
+		hashFn: hashFn,
+		ring:   make([]shardPoint, 0, len(shards)*shardReplicas),
+	}
+	for i := range shards {
+		for r := 0; r < shardReplicas; r++ {
+			s.ring = append(s.ring, shardPoint{
+				hash:  hashFn("shard-" + strconv.Itoa(i) + "-" + strconv.Itoa(r)),
+				shard: i,
+			})
+		}
+	}
+	sort.Slice(s.ring, func(i, j int) bool {
+		if s.ring[i].hash != s.ring[j].hash {
+			return s.ring[i].hash < s.ring[j].hash
+		}
+		return s.ring[i].shard < s.ring[j].shard
+	})
+
+	return s, nil
+}
+
+// Get 从名称对应的分片获取 Skill
+func (s *ShardedStore) Get(ctx context.Context, name string) (*schema.Skill, error) {
+	return s.shardFor(name).Get(ctx, name)
+}
+
+// List 汇总所有分片的 Skill 元数据，按名称去重并排序
+// 某个分片失败时仍返回其他分片的结果，同时返回合并后的错误
+func (s *ShardedStore) List(ctx context.Context) ([]*schema.SkillMetadata, error) {
+	seen := make(map[string]*schema.SkillMetadata)
+	var errs []error
+	for i, shard := range s.shards {
+		metadatas, err := shard.List(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("shard %d: %w", i, err))
+			continue
+		}
+		for _, metadata := range metadatas {
+			if _, ok := seen[metadata.Name]; !ok {
+				seen[metadata.Name] = metadata
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*schema.SkillMetadata, 0, len(names))
+	for _, name := range names {
+		result = append(result, seen[name])
+	}
+	return result, errors.Join(errs...)
+}
+
+// Put 将 Skill 保存到名称对应的分片
+func (s *ShardedStore) Put(ctx context.Context, skill *schema.Skill) error {
+	if skill == nil {
+		return errors.New("skill cannot be nil")
+	}
+	if skill.Metadata == nil || skill.Metadata.Name == "" {
+		return errors.New("skill metadata name cannot be empty")
+	}
+	return s.shardFor(skill.Metadata.Name).Put(ctx, skill)
+}
+
+// Delete 从名称对应的分片删除 Skill
+func (s *ShardedStore) Delete(ctx context.Context, name string) error {
+	return s.shardFor(name).Delete(ctx, name)
+}
+
+// Exists 检查名称对应的分片中是否存在 Skill
+func (s *ShardedStore) Exists(ctx context.Context, name string) (bool, error) {
+	return s.shardFor(name).Exists(ctx, name)
+}
+
+// shardFor 返回名称对应的分片
+func (s *ShardedStore) shardFor(name string) SkillStore {
+	return s.shards[s.shardIndex(name)]
+}
+
+// shardIndex 在哈希环上查找第一个不小于名称哈希的虚拟节点
+func (s *ShardedStore) shardIndex(name string) int {
+	h := s.hashFn(name)
+	i := sort.Search(len(s.ring), func(i int) bool {
+		return s.ring[i].hash >= h
+	})
+	if i == len(s.ring) {
+		i = 0
+	}
+	return s.ring[i].shard
+}
+
+// fnv64a 默认的 FNV-1a 64 位哈希
+func fnv64a(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// Ensure ShardedStore implements SkillStore
+var _ SkillStore = (*ShardedStore)(nil)
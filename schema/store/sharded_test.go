@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alois132/skill/schema"
+)
+
+func TestShardedStore(t *testing.T) {
+	ctx := context.Background()
+	shards := []*MemoryStore{NewMemoryStore(), NewMemoryStore(), NewMemoryStore()}
+	store, err := NewShardedStore([]SkillStore{shards[0], shards[1], shards[2]}, nil)
+	if err != nil {
+		t.Fatalf("NewShardedStore failed: %v", err)
+	}
+
+	const total = 30
+	for i := 0; i < total; i++ {
+		skill := &schema.Skill{Metadata: &schema.SkillMetadata{Name: fmt.Sprintf("skill_%d", i)}}
+		if err := store.Put(ctx, skill); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	// 每个 Skill 只存在于确定的分片中
+	used := make(map[int]bool)
+	for i := 0; i < total; i++ {
+		name := fmt.Sprintf("skill_%d", i)
+		index := store.shardIndex(name)
+		if index != store.shardIndex(name) {
+			t.Errorf("Expected deterministic routing for %s", name)
+		}
+		used[index] = true
+		for j, shard := range shards {
+			exists, _ := shard.Exists(ctx, name)
+			if exists != (j == index) {
+				t.Errorf("Expected %s only in shard %d, found in shard %d = %v", name, index, j, exists)
+			}
+		}
+		if _, err := store.Get(ctx, name); err != nil {
+			t.Errorf("Get failed: %v", err)
+		}
+	}
+	if len(used) < 2 {
+		t.Errorf("Expected skills to be spread across shards, used %v", used)
+	}
+
+	metadatas, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(metadatas) != total {
+		t.Errorf("Expected %d skills, got %d", total, len(metadatas))
+	}
+}
+
+// failingListStore List 总是失败的存储
+type failingListStore struct {
+	*MemoryStore
+}
+
+func (s *failingListStore) List(ctx context.Context) ([]*schema.SkillMetadata, error) {
+	return nil, errors.New("shard unavailable")
+}
+
+func TestShardedStore_ListPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	healthy := NewMemoryStore()
+	healthy.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "a"}})
+	// 同名 Skill 出现在多个分片时去重
+	duplicate := NewMemoryStore()
+	duplicate.Put(ctx, &schema.Skill{Metadata: &schema.SkillMetadata{Name: "a"}})
+
+	store, _ := NewShardedStore([]SkillStore{healthy, duplicate, &failingListStore{NewMemoryStore()}}, nil)
+	metadatas, err := store.List(ctx)
+	if err == nil {
+		t.Error("Expected shard error to be reported")
+	}
+	if len(metadatas) != 1 || metadatas[0].Name != "a" {
+		t.Errorf("Expected deduped results from healthy shards, got %v", metadatas)
+	}
+}
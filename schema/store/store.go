@@ -2,14 +2,19 @@ package store
 
 import (
 	"context"
+	"errors"
+
 	"github.com/alois132/skill/schema"
 )
 
+// ErrNotFound Skill 不存在，内置 Store 的 Get、Delete 等方法返回包装了它的 error
+var ErrNotFound = errors.New("skill not found")
+
 // SkillStore 定义 Skill 的持久化存储接口
 // 实现此接口可以将 Skill 存储到各种后端（内存、文件、etcd、数据库等）
 type SkillStore interface {
 	// Get 从存储中获取指定名称的 Skill
-	// 如果 Skill 不存在，返回 error
+	// 如果 Skill 不存在，返回 error（应包装 ErrNotFound，以便调用方区分不存在和其他失败）
 	Get(ctx context.Context, name string) (*schema.Skill, error)
 
 	// List 列出所有可用的 Skill 元数据
@@ -21,13 +26,32 @@ type SkillStore interface {
 	Put(ctx context.Context, skill *schema.Skill) error
 
 	// Delete 从存储中删除指定名称的 Skill
-	// 如果 Skill 不存在，返回 error
+	// 如果 Skill 不存在，返回 error（应包装 ErrNotFound）
 	Delete(ctx context.Context, name string) error
 
 	// Exists 检查指定名称的 Skill 是否存在
 	Exists(ctx context.Context, name string) (bool, error)
 }
 
+// PatchStore 支持原子局部更新的可选接口
+// 调用方可通过类型断言判断 SkillStore 是否支持
+type PatchStore interface {
+	// Patch 在存储的锁内加载最新的 Skill，调用 fn 修改后原子地写回
+	// fn 返回错误时不写入；fn 不能修改 Skill 名称
+	Patch(ctx context.Context, name string, fn func(skill *schema.Skill) error) error
+}
+
+// SoftDeleteStore 支持软删除的可选接口
+// 开启软删除后 Delete 只标记墓碑，Get/Exists/List 不再返回该 Skill，但数据保留以便恢复
+type SoftDeleteStore interface {
+	// Restore 恢复被软删除的 Skill；同名 Skill 已存在时返回 error
+	Restore(ctx context.Context, name string) error
+	// ListDeleted 列出所有被软删除的 Skill 元数据
+	ListDeleted(ctx context.Context) ([]*schema.SkillMetadata, error)
+	// Purge 彻底删除指定名称的 Skill（包括其墓碑），不存在时返回 error
+	Purge(ctx context.Context, name string) error
+}
+
 // StoreOption SkillStore 的配置选项
 type StoreOption func(*StoreConfig)
 
@@ -35,6 +59,23 @@ type StoreOption func(*StoreConfig)
 type StoreConfig struct {
 	Namespace string // 命名空间，用于隔离不同环境的 Skill
 	Prefix    string // 键前缀
+
+	CanonicalJSON  bool // 使用规范化 JSON（所有对象键排序），目前仅 FileStore 使用
+	IntegrityCheck bool // 写入时记录校验和，读取时校验，目前仅 FileStore 使用
+	Index          bool // 维护名称到元数据的索引文件以加速 List，目前仅 FileStore 使用
+	SoftDelete     bool // Delete 时保留墓碑而不是删除数据，MemoryStore 和 FileStore 支持
+
+	// EventBus 写入成功后发布 SkillEvent 的事件总线，为空时不发布
+	EventBus EventBus
+
+	// Serializer Skill 的序列化格式，为空时使用 JSONSerializer（按 CanonicalJSON 决定是否规范化）
+	Serializer Serializer
+
+	// KeyFunc 自定义名称到存储键的映射，为空时使用各 Store 的默认规则
+	KeyFunc func(namespace, name string) string
+	// NameFunc 自定义存储键到名称的反向映射，ok 为 false 表示该键不属于当前 Store
+	// 仅在需要从键解析名称时使用（如 FileStore.List），为空时不做过滤
+	NameFunc func(key string) (name string, ok bool)
 }
 
 // WithNamespace 设置命名空间
@@ -50,3 +91,51 @@ func WithPrefix(prefix string) StoreOption {
 		c.Prefix = prefix
 	}
 }
+
+// WithCanonicalJSON 使用规范化 JSON 序列化
+// 所有对象（包括嵌套的 map）的键按字典序输出，未修改的 Skill 重复保存时字节完全一致
+func WithCanonicalJSON() StoreOption {
+	return func(c *StoreConfig) {
+		c.CanonicalJSON = true
+	}
+}
+
+// WithKeyFunc 设置自定义的名称到存储键的映射
+// Get/Put/Delete/Exists 都使用同一个映射；FileStore 中它决定文件名（不含 .json 后缀）
+func WithKeyFunc(fn func(namespace, name string) string) StoreOption {
+	return func(c *StoreConfig) {
+		c.KeyFunc = fn
+	}
+}
+
+// WithNameFunc 设置自定义的存储键到名称的反向映射
+func WithNameFunc(fn func(key string) (name string, ok bool)) StoreOption {
+	return func(c *StoreConfig) {
+		c.NameFunc = fn
+	}
+}
+
+// WithIntegrityCheck 开启完整性校验
+// FileStore 在 Put 时写入 <file>.sha256 校验文件，Get 时校验内容，不匹配时返回 ErrCorrupt
+func WithIntegrityCheck() StoreOption {
+	return func(c *StoreConfig) {
+		c.IntegrityCheck = true
+	}
+}
+
+// WithIndex 开启索引
+// FileStore 在 Put/Delete 时维护 _index.json，List 只读取索引；索引缺失或过期（文件增删、大小或修改时间变化）时
+// 回退到全量扫描，并在下一次 Put/Delete 时重建
+func WithIndex() StoreOption {
+	return func(c *StoreConfig) {
+		c.Index = true
+	}
+}
+
+// WithSoftDelete 开启软删除
+// Delete 标记墓碑（设置 Metadata.Deleted / DeletedAt）而不删除数据，可通过 Restore 恢复、Purge 彻底删除
+func WithSoftDelete() StoreOption {
+	return func(c *StoreConfig) {
+		c.SoftDelete = true
+	}
+}
@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alois132/skill/schema/resources"
+	"github.com/alois132/skill/util"
+)
+
+var (
+	// ErrInvalidMetadata Skill 缺少元数据或名称
+	ErrInvalidMetadata = errors.New("invalid skill metadata")
+	// ErrDanglingTag Body 中的标记无法解析到脚本、参考文档或资源文件
+	ErrDanglingTag = errors.New("dangling tag")
+	// ErrDuplicateName 同一类资源中存在重复名称
+	ErrDuplicateName = errors.New("duplicate name")
+	// ErrInvalidScript 内联脚本的 Validate 报告了配置错误
+	ErrInvalidScript = errors.New("invalid script")
+)
+
+// Validate 检查 Skill 是否可用，返回发现的所有问题（无问题时返回空切片）
+// 检查项：元数据和名称存在；Body 解析成功；Body 中每个 <script>、<reference>、<asset> 标记
+// 都能解析到内联资源或 Provider 中的资源；实现 resources.ValidatableScript 的内联脚本没有配置错误；
+// 开启 Metadata.UniqueNames 时还检查重复名称。
+// 问题可通过 errors.Is 与 ErrInvalidMetadata、ErrDanglingTag、ErrDuplicateName、ErrInvalidScript 比较
+func (skill *Skill) Validate(ctx context.Context) []error {
+	issues := make([]error, 0)
+	if skill.Metadata == nil || skill.Metadata.Name == "" {
+		issues = append(issues, fmt.Errorf("%w: name is empty", ErrInvalidMetadata))
+	}
+	if skill.Metadata != nil && skill.Metadata.UniqueNames {
+		duplicates := skill.DuplicateNames()
+		for _, kind := range []string{"scripts", "references", "assets"} {
+			for _, name := range duplicates[kind] {
+				issues = append(issues, fmt.Errorf("%w: %s %s", ErrDuplicateName, strings.TrimSuffix(kind, "s"), name))
+			}
+		}
+	}
+	for _, script := range skill.Scripts {
+		if validatable, ok := script.(resources.ValidatableScript); ok {
+			if err := validatable.Validate(); err != nil {
+				issues = append(issues, fmt.Errorf("%w: script %s: %w", ErrInvalidScript, script.GetName(), err))
+			}
+		}
+	}
+	if _, err := parseBody(skill.bodyFormat(), skill.Body); err != nil {
+		return append(issues, err)
+	}
+
+	for _, name := range util.UniqueStrings(skill.GetScriptNames()) {
+		if _, err := skill.resolveScript(ctx, name); err != nil {
+			issues = append(issues, fmt.Errorf("%w: script %s", ErrDanglingTag, name))
+		}
+	}
+	for _, name := range util.UniqueStrings(skill.GetReferenceNames()) {
+		if _, err := skill.ReadReferenceContext(ctx, name); err != nil {
+			issues = append(issues, fmt.Errorf("%w: reference %s", ErrDanglingTag, name))
+		}
+	}
+	for _, name := range util.UniqueStrings(skill.GetAssetNames()) {
+		if !skill.hasAsset(ctx, name) {
+			issues = append(issues, fmt.Errorf("%w: asset %s", ErrDanglingTag, name))
+		}
+	}
+	return issues
+}
+
+// DuplicateNames 返回 Scripts、References、Assets 中重复出现的名称，键为 "scripts"、"references"、"assets"
+// 各类资源分别检查（脚本和参考文档同名不算重复），每个重复名称按首次出现的顺序只报告一次；
+// 默认区分大小写，开启 Metadata.CaseInsensitiveNames 时忽略大小写。没有重复时返回空 map
+func (skill *Skill) DuplicateNames() map[string][]string {
+	fold := skill.Metadata != nil && skill.Metadata.CaseInsensitiveNames
+
+	scripts := make([]string, 0, len(skill.Scripts))
+	for _, script := range skill.Scripts {
+		scripts = append(scripts, script.GetName())
+	}
+	references := make([]string, 0, len(skill.References))
+	for _, ref := range skill.References {
+		references = append(references, ref.Name)
+	}
+	assets := make([]string, 0, len(skill.Assets))
+	for _, asset := range skill.Assets {
+		assets = append(assets, asset.Name)
+	}
+
+	duplicates := make(map[string][]string)
+	for kind, names := range map[string][]string{"scripts": scripts, "references": references, "assets": assets} {
+		if dups := duplicateNames(names, fold); len(dups) > 0 {
+			duplicates[kind] = dups
+		}
+	}
+	return duplicates
+}
+
+// duplicateNames 返回出现多次的名称（按首次出现的顺序），fold 为 true 时忽略大小写
+func duplicateNames(names []string, fold bool) []string {
+	counts := make(map[string]int, len(names))
+	var dups []string
+	for _, name := range names {
+		key := name
+		if fold {
+			key = strings.ToLower(name)
+		}
+		counts[key]++
+		if counts[key] == 2 {
+			dups = append(dups, name)
+		}
+	}
+	return dups
+}
+
+// hasAsset 检查资源文件是否能从内联资源或 Provider 中解析
+func (skill *Skill) hasAsset(ctx context.Context, name string) bool {
+	for _, asset := range skill.Assets {
+		if asset.Name == name {
+			return true
+		}
+	}
+	if provider := skill.GetProvider(); provider != nil {
+		if _, err := provider.GetAsset(ctx, name); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CoerceNumbers 将 map 中以字符串编码的数字和布尔值原地转换为数字 / bool
+// 整数转换为 int64（超出范围的正整数为 uint64），其余数字转换为 float64，见 parseNumber
+// 用于 map[string]interface{} 输入的脚本，嵌套的 map 和数组会递归处理。
+// 其他字符串保持不变，但语法上是数字却超出范围的字符串（如 "1e999"）返回错误；
+// numericKeys 中的顶层键必须是数字或可转换为数字的字符串，否则返回错误，缺失的键不检查
+func CoerceNumbers(m map[string]interface{}, numericKeys ...string) error {
+	for k, v := range m {
+		coerced, err := coerceLoose(v)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", k, err)
+		}
+		m[k] = coerced
+	}
+	for _, key := range numericKeys {
+		v, ok := m[key]
+		if !ok {
+			continue
+		}
+		switch v.(type) {
+		case float64, int, int64, uint64, json.Number:
+		case string:
+			return fmt.Errorf("field %s: cannot coerce %q to number", key, v)
+		default:
+			return fmt.Errorf("field %s: expected number, got %T", key, v)
+		}
+	}
+	return nil
+}
+
+func coerceLoose(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		if n, ok := parseNumber(val); ok {
+			return n, nil
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(val), 64); errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("number %q out of range", val)
+		}
+		switch val {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return val, nil
+	case map[string]interface{}:
+		if err := CoerceNumbers(val); err != nil {
+			return nil, err
+		}
+		return val, nil
+	case []interface{}:
+		for i := range val {
+			coerced, err := coerceLoose(val[i])
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			val[i] = coerced
+		}
+		return val, nil
+	default:
+		return v, nil
+	}
+}
+
+// CoerceTo 按目标类型 t 原地转换 m 中以字符串编码的数字和布尔值
+// t 为结构体时按 json tag 匹配字段（与 encoding/json 一样优先精确匹配，其次不区分大小写），
+// 无法转换的值返回错误；t 为 map 时按元素类型转换
+func CoerceTo(m map[string]interface{}, t reflect.Type) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := jsonFieldName(field)
+			if name == "" {
+				continue
+			}
+			key, ok := matchKey(m, name)
+			if !ok {
+				continue
+			}
+			coerced, err := coerceValue(m[key], field.Type)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+			m[key] = coerced
+		}
+	case reflect.Map:
+		for k, v := range m {
+			coerced, err := coerceValue(v, t.Elem())
+			if err != nil {
+				return fmt.Errorf("field %s: %w", k, err)
+			}
+			m[k] = coerced
+		}
+	}
+	return nil
+}
+
+// coerceValue 将单个值转换为适合反序列化到 t 的形式
+func coerceValue(v interface{}, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		n, ok := parseNumber(s)
+		if !ok {
+			return nil, fmt.Errorf("cannot coerce %q to %s", s, t.Kind())
+		}
+		return n, nil
+	case reflect.Bool:
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("cannot coerce %q to bool", s)
+		}
+		return b, nil
+	case reflect.Interface:
+		return coerceLoose(v)
+	case reflect.Struct, reflect.Map:
+		if nested, ok := v.(map[string]interface{}); ok {
+			if err := CoerceTo(nested, t); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+		for i := range items {
+			coerced, err := coerceValue(items[i], t.Elem())
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			items[i] = coerced
+		}
+		return items, nil
+	default:
+		return v, nil
+	}
+}
+
+// parseNumber 解析有限的十进制数字
+// 依次尝试 int64、uint64 和 float64，避免超过 2^53 的整数经 float64 丢失精度；
+// NaN 和 Inf 无法序列化为 JSON，视为不可转换
+func parseNumber(s string) (interface{}, bool) {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return f, true
+}
+
+// matchKey 按 encoding/json 的规则查找字段对应的 key：优先精确匹配，其次不区分大小写
+func matchKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// jsonFieldName 返回字段在 JSON 中的名称，"-" 返回空字符串
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,158 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoerceNumbers(t *testing.T) {
+	m := map[string]interface{}{
+		"a":      "10",
+		"b":      5.0,
+		"flag":   "true",
+		"name":   "alice",
+		"nested": map[string]interface{}{"n": "2.5"},
+	}
+	if err := CoerceNumbers(m); err != nil {
+		t.Fatalf("CoerceNumbers failed: %v", err)
+	}
+
+	if m["a"] != int64(10) {
+		t.Errorf("Expected a=10, got %v", m["a"])
+	}
+	if m["b"] != 5.0 {
+		t.Errorf("Expected b=5, got %v", m["b"])
+	}
+	if m["flag"] != true {
+		t.Errorf("Expected flag=true, got %v", m["flag"])
+	}
+	if m["name"] != "alice" {
+		t.Errorf("Expected name to stay 'alice', got %v", m["name"])
+	}
+	if m["nested"].(map[string]interface{})["n"] != 2.5 {
+		t.Errorf("Expected nested n=2.5, got %v", m["nested"])
+	}
+}
+
+func TestCoerceNumbers_Errors(t *testing.T) {
+	// 语法上是数字但超出范围
+	err := CoerceNumbers(map[string]interface{}{"n": map[string]interface{}{"big": "1e999"}})
+	if err == nil || err.Error() != `field n: field big: number "1e999" out of range` {
+		t.Errorf("Expected out of range error, got %v", err)
+	}
+
+	// 标记为数字的键必须是数字
+	err = CoerceNumbers(map[string]interface{}{"a": "ten", "b": "5"}, "a", "b", "c")
+	if err == nil || err.Error() != `field a: cannot coerce "ten" to number` {
+		t.Errorf("Expected error for non-numeric key, got %v", err)
+	}
+	err = CoerceNumbers(map[string]interface{}{"a": true}, "a")
+	if err == nil || err.Error() != "field a: expected number, got bool" {
+		t.Errorf("Expected type error, got %v", err)
+	}
+
+	// 未标记的普通字符串保持不变
+	m := map[string]interface{}{"a": "10", "name": "ten"}
+	if err := CoerceNumbers(m, "a"); err != nil || m["a"] != int64(10) || m["name"] != "ten" {
+		t.Errorf("Expected a=10 and name unchanged, got %v (%v)", m, err)
+	}
+}
+
+func TestCoerceTo(t *testing.T) {
+	type Input struct {
+		A     int     `json:"a"`
+		B     float64 `json:"b"`
+		Flag  bool    `json:"flag"`
+		Label string  `json:"label"`
+	}
+
+	m := map[string]interface{}{"a": "10", "b": "0.5", "flag": "false", "label": "42"}
+	if err := CoerceTo(m, reflect.TypeOf(Input{})); err != nil {
+		t.Fatalf("CoerceTo failed: %v", err)
+	}
+	if m["a"] != int64(10) || m["b"] != 0.5 || m["flag"] != false {
+		t.Errorf("Unexpected coercion result: %v", m)
+	}
+	// 字符串字段保持不变
+	if m["label"] != "42" {
+		t.Errorf("Expected label to stay '42', got %v", m["label"])
+	}
+
+	// 无法转换的值返回错误
+	err := CoerceTo(map[string]interface{}{"a": "ten"}, reflect.TypeOf(Input{}))
+	if err == nil {
+		t.Fatal("Expected error for non-coercible value")
+	}
+	if err.Error() != `field a: cannot coerce "ten" to int` {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestCoerceTo_LargeIntegers(t *testing.T) {
+	type Input struct {
+		ID    int64  `json:"id"`
+		Count uint64 `json:"count"`
+	}
+
+	m := map[string]interface{}{"id": "9007199254740993", "count": "18446744073709551615"}
+	if err := CoerceTo(m, reflect.TypeOf(Input{})); err != nil {
+		t.Fatalf("CoerceTo failed: %v", err)
+	}
+	if m["id"] != int64(9007199254740993) {
+		t.Errorf("Expected id to keep full precision, got %v", m["id"])
+	}
+	if m["count"] != uint64(18446744073709551615) {
+		t.Errorf("Expected count to keep full precision, got %v", m["count"])
+	}
+}
+
+func TestCoerceTo_CaseInsensitiveFields(t *testing.T) {
+	type Input struct {
+		Count int  `json:"count"`
+		Flag  bool // 无 tag，按字段名匹配
+	}
+
+	m := map[string]interface{}{"Count": "3", "flag": "true"}
+	if err := CoerceTo(m, reflect.TypeOf(Input{})); err != nil {
+		t.Fatalf("CoerceTo failed: %v", err)
+	}
+	if m["Count"] != int64(3) || m["flag"] != true {
+		t.Errorf("Expected case-insensitive field matching like encoding/json, got %v", m)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Node struct {
+		Base
+		Name     string            `json:"name"`
+		Tags     []string          `json:"tags,omitempty"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Children []*Node           `json:"children,omitempty"`
+		Secret   string            `json:"-"`
+	}
+
+	schema := JSONSchema(reflect.TypeOf(Node{}))
+	properties := schema["properties"].(map[string]interface{})
+
+	// 嵌入字段展开，json:"-" 忽略
+	for _, name := range []string{"id", "name", "tags", "labels", "children"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Expected property %s", name)
+		}
+	}
+	if _, ok := properties["Secret"]; ok {
+		t.Error("Expected Secret to be skipped")
+	}
+	// 未标记 omitempty 的字段为必填
+	if !reflect.DeepEqual(schema["required"], []string{"id", "name"}) {
+		t.Errorf("Expected required [id name], got %v", schema["required"])
+	}
+	// 递归类型退化为对象
+	children := properties["children"].(map[string]interface{})
+	if !reflect.DeepEqual(children["items"], map[string]interface{}{"type": "object"}) {
+		t.Errorf("Expected recursive items to be a plain object, got %v", children["items"])
+	}
+}
@@ -0,0 +1,15 @@
+package util
+
+// UniqueStrings 去除重复的字符串，保留首次出现的顺序；返回新的切片，不修改 s
+func UniqueStrings(s []string) []string {
+	seen := make(map[string]struct{}, len(s))
+	result := make([]string, 0, len(s))
+	for _, v := range s {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
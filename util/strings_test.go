@@ -0,0 +1,20 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueStrings(t *testing.T) {
+	in := []string{"b", "a", "b", "c", "a"}
+	got := UniqueStrings(in)
+	if expected := []string{"b", "a", "c"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if in[2] != "b" {
+		t.Errorf("Expected input to be unchanged, got %v", in)
+	}
+	if got := UniqueStrings(nil); len(got) != 0 {
+		t.Errorf("Expected empty result, got %v", got)
+	}
+}
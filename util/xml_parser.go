@@ -5,22 +5,53 @@ import (
 	"strings"
 )
 
+// TagKind 标记类型
+type TagKind string
+
+const (
+	TagUnknown   TagKind = ""
+	TagScript    TagKind = "script"
+	TagReference TagKind = "reference"
+	TagAsset     TagKind = "asset"
+)
+
+// ParseTagKind 将标记名转换为 TagKind，未知的标记名返回 TagUnknown
+func ParseTagKind(name string) TagKind {
+	switch kind := TagKind(name); kind {
+	case TagScript, TagReference, TagAsset:
+		return kind
+	}
+	return TagUnknown
+}
+
 // XMLTag 表示解析出的 XML 标记
 type XMLTag struct {
 	TagName string // 标记名：script, reference, asset
 	Content string // 标记内容（如 "init_skill", "usage_guide"）
+
+	// Attrs 标记属性（ParseXMLTagsV2 填充所有属性，ParseXMLTags 只填充 args），无属性时为 nil
+	Attrs map[string]string
+}
+
+// ArgsAttr 标记上的内联参数属性，值为 JSON，AutoExecute 执行该脚本时使用
+const ArgsAttr = "args"
+
+// Kind 返回标记类型，由 TagName 得出（解析出的标记总是已知类型），未知的标记名返回 TagUnknown
+// 不单独存储，以保持 XMLTag 的值可直接比较
+func (tag XMLTag) Kind() TagKind {
+	return ParseTagKind(tag.TagName)
 }
 
 // ParseXMLTags 从文本中解析所有 XML 标记
-// 支持格式：<script>name</script> 或 <reference>name</reference>
+// 支持格式：<script>name</script> 或 <reference>name</reference>，以及带内联参数的 <script args='{"k":"v"}'>name</script>
 func ParseXMLTags(body string) []XMLTag {
 	if body == "" {
 		return nil
 	}
 
 	// 正则匹配 XML 标记：支持 script, reference, asset
-	// 格式：<tag>content</tag>
-	pattern := `<(script|reference|asset)>([^<]+)</(script|reference|asset)>`
+	// 格式：<tag>content</tag> 或 <tag args="...">content</tag>
+	pattern := `<(script|reference|asset)(\s+args\s*=\s*(?:"([^"]*)"|'([^']*)'))?>([^<]+)</(script|reference|asset)>`
 	re := regexp.MustCompile(pattern)
 
 	matches := re.FindAllStringSubmatch(body, -1)
@@ -30,10 +61,17 @@ func ParseXMLTags(body string) []XMLTag {
 
 	tags := make([]XMLTag, 0, len(matches))
 	for _, match := range matches {
-		if len(match) >= 3 {
+		if len(match) >= 6 {
 			tag := XMLTag{
 				TagName: match[1],
-				Content: strings.TrimSpace(match[2]),
+				Content: strings.TrimSpace(match[5]),
+			}
+			if match[2] != "" {
+				args := match[3]
+				if args == "" {
+					args = match[4]
+				}
+				tag.Attrs = map[string]string{ArgsAttr: args}
 			}
 			tags = append(tags, tag)
 		}
@@ -42,6 +80,76 @@ func ParseXMLTags(body string) []XMLTag {
 	return tags
 }
 
+var (
+	// v2TagPattern 匹配可带属性的标记：<tag key="value" ...>content</tag>
+	v2TagPattern = regexp.MustCompile(`<(script|reference|asset)((?:\s+[A-Za-z_][\w-]*\s*=\s*(?:"[^"]*"|'[^']*'))*)\s*>([^<]+)</(script|reference|asset)>`)
+	// v2AttrPattern 匹配单个属性
+	v2AttrPattern = regexp.MustCompile(`([A-Za-z_][\w-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// ParseXMLTagsV2 从文本中解析所有 XML 标记，支持属性
+// 支持格式：<script>name</script> 或 <script timeout="5s" mode='async'>name</script>；
+// 开闭标记名称不一致的匹配会被忽略
+func ParseXMLTagsV2(body string) []XMLTag {
+	if body == "" {
+		return nil
+	}
+
+	matches := v2TagPattern.FindAllStringSubmatch(body, -1)
+	if matches == nil {
+		return nil
+	}
+
+	tags := make([]XMLTag, 0, len(matches))
+	for _, match := range matches {
+		if match[1] != match[4] {
+			continue
+		}
+		tag := XMLTag{
+			TagName: match[1],
+			Content: strings.TrimSpace(match[3]),
+		}
+		for _, attr := range v2AttrPattern.FindAllStringSubmatch(match[2], -1) {
+			if tag.Attrs == nil {
+				tag.Attrs = make(map[string]string)
+			}
+			value := attr[2]
+			if value == "" {
+				value = attr[3]
+			}
+			tag.Attrs[attr[1]] = value
+		}
+		tags = append(tags, tag)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// RenameXMLTags 将 body 中内容为 oldName 的 kind 标记改写为 newName，开标记上的属性（如 args）保持不变
+// 匹配规则与 ParseXMLTagsV2 相同，因此同时覆盖无属性、args 属性和 V2 属性标记；没有匹配时原样返回 body
+func RenameXMLTags(body string, kind TagKind, oldName, newName string) string {
+	var sb strings.Builder
+	last := 0
+	for _, match := range v2TagPattern.FindAllStringSubmatchIndex(body, -1) {
+		// 分组：1 开标记名，3 内容，4 闭标记名
+		name := body[match[2]:match[3]]
+		if name != string(kind) || body[match[8]:match[9]] != name || strings.TrimSpace(body[match[6]:match[7]]) != oldName {
+			continue
+		}
+		sb.WriteString(body[last:match[6]])
+		sb.WriteString(newName)
+		last = match[7]
+	}
+	if last == 0 {
+		return body
+	}
+	sb.WriteString(body[last:])
+	return sb.String()
+}
+
 // ExtractScriptNames 从 Body 中提取所有脚本名称
 func ExtractScriptNames(body string) []string {
 	tags := ParseXMLTags(body)
@@ -51,7 +159,7 @@ func ExtractScriptNames(body string) []string {
 
 	scriptNames := []string{}
 	for _, tag := range tags {
-		if tag.TagName == "script" {
+		if tag.Kind() == TagScript {
 			scriptNames = append(scriptNames, tag.Content)
 		}
 	}
@@ -71,7 +179,7 @@ func ExtractReferenceNames(body string) []string {
 
 	refNames := []string{}
 	for _, tag := range tags {
-		if tag.TagName == "reference" {
+		if tag.Kind() == TagReference {
 			refNames = append(refNames, tag.Content)
 		}
 	}
@@ -91,7 +199,7 @@ func ExtractAssetNames(body string) []string {
 
 	assetNames := []string{}
 	for _, tag := range tags {
-		if tag.TagName == "asset" {
+		if tag.Kind() == TagAsset {
 			assetNames = append(assetNames, tag.Content)
 		}
 	}
@@ -74,6 +74,14 @@ init_skill
 				{TagName: "script", Content: "complex_script"},
 			},
 		},
+		{
+			name: "inline args",
+			body: `<script args='{"format":"iso"}'>get_time</script> <script args="[1,2]">sum</script> <script other="x">ignored</script>`,
+			expected: []XMLTag{
+				{TagName: "script", Content: "get_time", Attrs: map[string]string{"args": `{"format":"iso"}`}},
+				{TagName: "script", Content: "sum", Attrs: map[string]string{"args": "[1,2]"}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -290,3 +298,57 @@ func TestRealWorldExample(t *testing.T) {
 		t.Errorf("Expected [template.png], got %v", assetNames)
 	}
 }
+
+func TestParseXMLTagsV2(t *testing.T) {
+	body := `运行 <script timeout="5s" mode='async'>init</script>，参考 <reference>guide</reference>，忽略 <script>bad</asset>`
+	expected := []XMLTag{
+		{TagName: "script", Content: "init", Attrs: map[string]string{"timeout": "5s", "mode": "async"}},
+		{TagName: "reference", Content: "guide"},
+	}
+
+	tags := ParseXMLTagsV2(body)
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, tags)
+	}
+	if ParseXMLTagsV2("") != nil {
+		t.Error("Expected nil for empty body")
+	}
+}
+
+func TestXMLTag_Kind(t *testing.T) {
+	tags := ParseXMLTags("<script>init</script> <reference>guide</reference> <asset>logo.png</asset>")
+	expected := []TagKind{TagScript, TagReference, TagAsset}
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected %d tags, got %d", len(expected), len(tags))
+	}
+	for i, tag := range tags {
+		if tag.Kind() != expected[i] {
+			t.Errorf("Expected kind %q for %s, got %q", expected[i], tag.Content, tag.Kind())
+		}
+	}
+
+	// V2 解析同样得到已知类型
+	if tags := ParseXMLTagsV2(`<script mode="async">run</script>`); len(tags) != 1 || tags[0].Kind() != TagScript {
+		t.Errorf("Expected TagScript from V2 parser, got %+v", tags)
+	}
+
+	// 未知标记名
+	if kind := (XMLTag{TagName: "widget"}).Kind(); kind != TagUnknown {
+		t.Errorf("Expected TagUnknown, got %q", kind)
+	}
+}
+
+func TestRenameXMLTags(t *testing.T) {
+	body := `Run <script>add</script>, <script timeout="5s" mode='async'> add </script>, ` +
+		`<reference>add</reference> and <script>addition</script>`
+	expected := `Run <script>sum</script>, <script timeout="5s" mode='async'>sum</script>, ` +
+		`<reference>add</reference> and <script>addition</script>`
+	if got := RenameXMLTags(body, TagScript, "add", "sum"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	// 没有匹配时原样返回
+	if got := RenameXMLTags(body, TagAsset, "add", "sum"); got != body {
+		t.Errorf("Expected body unchanged, got %s", got)
+	}
+}